@@ -0,0 +1,101 @@
+package main
+
+import (
+	"io"
+	"sort"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+const (
+	afterHoursWidth = 13
+	weekendWidth    = 9
+	outsideWidth    = 9
+)
+
+// defaultWorkHours are the working hours of --after-hours-report, from
+// 08:00 to 19:59.
+var defaultWorkHours = &hourRange{8, 19}
+
+// afterHoursColumns replace the table columns with --after-hours-report,
+// where every row is an author.
+var afterHoursColumns = []tableColumn{
+	commitsColumn,
+	{"After Hours", afterHoursWidth, func(stats *DailyStats) string {
+		return formatCount(stats.AfterHours)
+	}},
+	{"Weekend", weekendWidth, func(stats *DailyStats) string {
+		return formatCount(stats.WeekendCommits)
+	}},
+	{"Outside", outsideWidth, func(stats *DailyStats) string {
+		share := 0.0
+		if stats.Commits > 0 {
+			share = 100 * float64(stats.AfterHours+stats.WeekendCommits) / float64(stats.Commits)
+		}
+		return formatPercent(share, 1)
+	}},
+}
+
+// isWeekend reports whether t falls on a Saturday or Sunday.
+func isWeekend(t time.Time) bool {
+	return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+}
+
+// getAfterHoursStats counts the commits of every author, and how many of
+// them were made on a weekend or on a weekday outside workHours. The hours
+// are those of the author's own time zone, as recorded in the commit.
+func getAfterHoursStats(repo *git.Repository, startDate, endDate time.Time, opts *Options, workHours *hourRange) (map[string]*DailyStats, error) {
+	authorStats := make(map[string]*DailyStats)
+
+	err := walkCommits(repo, startDate, endDate, opts, func(c *object.Commit, stats object.FileStats) error {
+		author := authorKey(c.Author, opts)
+		if _, ok := authorStats[author]; !ok {
+			authorStats[author] = &DailyStats{
+				FilesChanged: make(map[string]struct{}),
+				Authors:      make(map[string]struct{}),
+			}
+		}
+
+		authorStats[author].Commits++
+		switch {
+		case isWeekend(c.Author.When):
+			authorStats[author].WeekendCommits++
+		case !workHours.contains(c.Author.When.Hour()):
+			authorStats[author].AfterHours++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return authorStats, nil
+}
+
+// printAfterHoursTable prints one row per author, those with the most
+// commits outside working hours first and by name on a tie.
+func printAfterHoursTable(w io.Writer, authorStats map[string]*DailyStats) {
+	authors := make([]string, 0, len(authorStats))
+	rows := make([]*DailyStats, 0, len(authorStats))
+	for author, stats := range authorStats {
+		authors = append(authors, author)
+		rows = append(rows, stats)
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		a, b := authorStats[authors[i]], authorStats[authors[j]]
+		if outsideA, outsideB := a.AfterHours+a.WeekendCommits, b.AfterHours+b.WeekendCommits; outsideA != outsideB {
+			return outsideA > outsideB
+		}
+		return authors[i] < authors[j]
+	})
+	fitColumns(rows)
+	fitLabels(authors)
+
+	printTableHeader(w, "Author")
+
+	for _, author := range authors {
+		printTableRow(w, author, authorStats[author], "")
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsWeekend(t *testing.T) {
+	tests := []struct {
+		date string
+		want bool
+	}{
+		{"2024-03-04", false}, // Monday
+		{"2024-03-08", false}, // Friday
+		{"2024-03-09", true},  // Saturday
+		{"2024-03-10", true},  // Sunday
+	}
+	for _, tt := range tests {
+		day, err := time.Parse("2006-01-02", tt.date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := isWeekend(day); got != tt.want {
+			t.Errorf("isWeekend(%s) = %t, want %t", tt.date, got, tt.want)
+		}
+	}
+}
+
+func TestAfterHoursReport(t *testing.T) {
+	const alice, bob = "Alice <alice@example.com>", "Bob <bob@example.com>"
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-02-28T22:00:00Z", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-04T10:00:00Z", author: alice, files: map[string]string{"a": lines(2)}})
+	r.commit(testCommit{when: "2024-03-04T21:00:00Z", author: alice, files: map[string]string{"a": lines(3)}})
+	r.commit(testCommit{when: "2024-03-05T07:00:00Z", author: bob, files: map[string]string{"a": lines(4)}})
+	r.commit(testCommit{when: "2024-03-05T19:30:00Z", author: bob, files: map[string]string{"a": lines(5)}})
+	// Noon in Bob's time zone, though 03:00 in UTC.
+	r.commit(testCommit{when: "2024-03-06T12:00:00+09:00", author: bob, files: map[string]string{"a": lines(6)}})
+	r.commit(testCommit{when: "2024-03-09T10:00:00Z", author: alice, files: map[string]string{"a": lines(7)}})
+
+	tests := []struct {
+		flags string
+		rows  [][]string // the rows in order, label first
+	}{
+		{"", [][]string{
+			{alice, "3", "1", "1", "66.7%"},
+			{bob, "3", "1", "0", "33.3%"},
+		}},
+		{"--work-hours 7-21", [][]string{
+			{alice, "3", "0", "1", "33.3%"},
+			{bob, "3", "0", "0", "0.0%"},
+		}},
+		{"--work-hours 9-17", [][]string{
+			{alice, "3", "1", "1", "66.7%"},
+			{bob, "3", "2", "0", "66.7%"},
+		}},
+		{"--work-hours 6-8", [][]string{
+			{alice, "3", "2", "1", "100.0%"},
+			{bob, "3", "2", "0", "66.7%"},
+		}},
+	}
+	for _, tt := range tests {
+		out := mustRun(t, r.dir, append(strings.Fields("--after-hours-report "+tt.flags), ".", "2024-03-01", "2024-03-10")...)
+		at := -1
+		for _, want := range tt.rows {
+			if row := tableRow(out, want[0]); strings.Join(row, "|") != strings.Join(want, "|") {
+				t.Errorf("%q: %v, want %v", tt.flags, row, want)
+			}
+			i := strings.Index(out, want[0])
+			if i < at {
+				t.Errorf("%q: %s is listed out of order:\n%s", tt.flags, want[0], out)
+			}
+			at = i
+		}
+	}
+
+	if stdout, stderr, code := runGitStat(t, r.dir, "--after-hours-report", "--by-author", ".", "2024-03-01", "2024-03-10"); code == 0 || !strings.Contains(stderr, "only one of") || !strings.Contains(stderr, "--after-hours-report") {
+		t.Errorf("--after-hours-report --by-author exited with %d: %s%s", code, stdout, stderr)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/merkletrie"
+)
+
+const ageWeightedWidth = 14
+
+// ageWeightedColumn shows the changes weighted by the age of their files,
+// counted with --age-weighted and rounded to whole lines.
+var ageWeightedColumn = tableColumn{"Age Weighted", ageWeightedWidth, func(stats *DailyStats) string {
+	return formatCount(int(math.Round(stats.AgeWeighted)))
+}}
+
+// creationDates looks up when the files in the history of a commit were
+// created, for --age-weighted. The history is walked once per run, and the
+// date of every path is worked out the first time it is looked up and kept
+// for the commits touching it later.
+type creationDates struct {
+	added     map[string]time.Time
+	movedFrom map[string][]string
+	cache     map[string]creationDate
+}
+
+// creationDate is a memoized lookup of creationDates, with ok false for
+// paths whose creation is unknown.
+type creationDate struct {
+	when time.Time
+	ok   bool
+}
+
+// fileCreationDates walks the history of from for when every file was first
+// added, by the author date of the commit adding it. Each commit is compared
+// with its first parent, so a file brought in by a merge is dated by the
+// commit on its branch. Renames are detected with renameThreshold, and a
+// file moved to a new path, in any commit, is dated by when its old path was
+// added.
+func fileCreationDates(repo *git.Repository, from plumbing.Hash, renameThreshold int) (*creationDates, error) {
+	commits, err := repo.Log(&git.LogOptions{From: from})
+	if err != nil {
+		return nil, err
+	}
+
+	dates := &creationDates{
+		added:     make(map[string]time.Time),
+		movedFrom: make(map[string][]string),
+		cache:     make(map[string]creationDate),
+	}
+	err = commits.ForEach(func(c *object.Commit) error {
+		changes, err := commitChanges(c, renameThreshold)
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			action, err := change.Action()
+			if err != nil {
+				return err
+			}
+			switch {
+			case action == merkletrie.Insert:
+				if when, ok := dates.added[change.To.Name]; !ok || c.Author.When.Before(when) {
+					dates.added[change.To.Name] = c.Author.When
+				}
+			case action == merkletrie.Modify && change.From.Name != change.To.Name:
+				dates.movedFrom[change.To.Name] = append(dates.movedFrom[change.To.Name], change.From.Name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	debugf("found the creation dates of %d files", len(dates.added))
+	return dates, nil
+}
+
+// created returns when the file at name was created: as early as the oldest
+// file that was added to the path or moved to it from elsewhere.
+func (d *creationDates) created(name string) (time.Time, bool) {
+	if date, ok := d.cache[name]; ok {
+		return date.when, date.ok
+	}
+	when, ok := d.birth(name, make(map[string]bool))
+	d.cache[name] = creationDate{when, ok}
+	return when, ok
+}
+
+// birth follows the moves to name back to where they started. seen guards
+// against paths moved back and forth; as the results of paths met on the
+// way leave out the paths already seen, only those of created are kept.
+func (d *creationDates) birth(name string, seen map[string]bool) (time.Time, bool) {
+	if date, ok := d.cache[name]; ok {
+		return date.when, date.ok
+	}
+
+	seen[name] = true
+	when, ok := d.added[name]
+	for _, old := range d.movedFrom[name] {
+		if seen[old] {
+			continue
+		}
+		if t, found := d.birth(old, seen); found && (!ok || t.Before(when)) {
+			when, ok = t, true
+		}
+	}
+	return when, ok
+}
+
+// ageWeight is the factor the changes to a file are multiplied by with
+// --age-weighted: one plus the age of the file in years when the commit was
+// made, so changes to a new file count once and those to a file created two
+// years earlier three times. A renamed file keeps the age of its old path,
+// whether it was moved by this commit or an earlier one. Files whose creation
+// is unknown, as beyond the boundary of a shallow clone, count once.
+func (d *creationDates) ageWeight(name string, when time.Time) float64 {
+	from, _ := splitRename(name)
+	birth, ok := d.created(from)
+	if !ok || !when.After(birth) {
+		return 1
+	}
+	return 1 + when.Sub(birth).Hours()/(24*365)
+}
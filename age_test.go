@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestAgeWeight(t *testing.T) {
+	birth := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+	created := &creationDates{added: map[string]time.Time{"old.go": birth}, cache: make(map[string]creationDate)}
+
+	tests := []struct {
+		name string
+		when time.Time
+		want float64
+	}{
+		{"old.go", birth, 1},
+		{"old.go", birth.AddDate(0, 0, 365), 2},
+		{"old.go", birth.AddDate(0, 0, 730), 3},
+		{"old.go", birth.AddDate(0, 0, -1), 1},
+		// A rename made by the commit keeps the age of its old path.
+		{"old.go => new.go", birth.AddDate(0, 0, 365), 2},
+		{"unknown.go", birth.AddDate(0, 0, 365), 1},
+	}
+	for _, tt := range tests {
+		if got := created.ageWeight(tt.name, tt.when); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("ageWeight(%q, %s) = %g, want %g", tt.name, tt.when.Format(time.DateOnly), got, tt.want)
+		}
+	}
+}
+
+func TestFileCreationDates(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2022-03-01T10:00:00Z", files: map[string]string{"a.txt": lines(10)}})
+	r.commit(testCommit{when: "2023-03-01T10:00:00Z", files: map[string]string{"a.txt": lines(12), "b.txt": lines(20)}})
+	// Moved unchanged, moved again and then moved with heavy edits.
+	r.commit(testCommit{when: "2023-06-01T10:00:00Z", files: map[string]string{"b.txt": "", "c.txt": lines(20)}})
+	r.commit(testCommit{when: "2023-09-01T10:00:00Z", files: map[string]string{"c.txt": "", "d.txt": lines(20) + "more\n"}})
+	r.checkout("feature")
+	feature := r.commit(testCommit{when: "2024-01-01T10:00:00Z", files: map[string]string{"f.txt": lines(3)}})
+	r.checkout("master")
+	// The merge adds f.txt against its first parent, but it was added
+	// earlier on its branch.
+	head := r.commit(testCommit{when: "2024-02-01T10:00:00Z", files: map[string]string{"f.txt": lines(3)}, parents: []plumbing.Hash{feature}})
+
+	tests := []struct {
+		renameThreshold int
+		want            map[string]string
+	}{
+		{60, map[string]string{
+			"a.txt": "2022-03-01",
+			"b.txt": "2023-03-01",
+			"c.txt": "2023-03-01",
+			"d.txt": "2023-03-01",
+			"f.txt": "2024-01-01",
+		}},
+		// Only unchanged moves are renames at 100%, so the edited move
+		// dates d.txt by itself.
+		{100, map[string]string{
+			"a.txt": "2022-03-01",
+			"b.txt": "2023-03-01",
+			"c.txt": "2023-03-01",
+			"d.txt": "2023-09-01",
+			"f.txt": "2024-01-01",
+		}},
+	}
+	for _, tt := range tests {
+		dates, err := fileCreationDates(r.repo, head, tt.renameThreshold)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make(map[string]string)
+		for name := range tt.want {
+			if when, ok := dates.created(name); ok {
+				got[name] = when.UTC().Format(time.DateOnly)
+			}
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("with a rename threshold of %d: %v, want %v", tt.renameThreshold, got, tt.want)
+		}
+		if when, ok := dates.created("missing.txt"); ok {
+			t.Errorf("with a rename threshold of %d: missing.txt created %s", tt.renameThreshold, when)
+		}
+
+		// Every path is worked out once, and later lookups are
+		// answered from the cache.
+		if len(dates.cache) != len(tt.want)+1 {
+			t.Errorf("with a rename threshold of %d: %d paths cached, want %d", tt.renameThreshold, len(dates.cache), len(tt.want)+1)
+		}
+		dates.added, dates.movedFrom = nil, nil
+		if when, ok := dates.created("d.txt"); !ok || when.UTC().Format(time.DateOnly) != tt.want["d.txt"] {
+			t.Errorf("with a rename threshold of %d: d.txt created %s again, want %s", tt.renameThreshold, when, tt.want["d.txt"])
+		}
+	}
+}
+
+func TestAgeWeighted(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2022-03-01T10:00:00Z", files: map[string]string{"old.txt": lines(2)}})
+	r.commit(testCommit{when: "2023-03-01T10:00:00Z", files: map[string]string{"mid.txt": lines(2)}})
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"old.txt": lines(4)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"mid.txt": lines(5), "new.txt": lines(4)}})
+
+	tests := []struct {
+		label string
+		want  []string
+	}{
+		{"Date Range", []string{"Date Range", "Files Changed", "Additions", "Deletions", "Total Changes", "Age Weighted"}},
+		// Two years and a leap day old: 2 lines weigh about 3 each.
+		{"2024-03-01", []string{"2024-03-01", "1", "2", "0", "2", "6"}},
+		// A year and a leap day old, 3 lines weigh 2 each, and the 4
+		// lines of a new file once.
+		{"2024-03-02", []string{"2024-03-02", "2", "7", "0", "7", "10"}},
+		{"Total", []string{"Total", "3", "9", "0", "9", "16"}},
+	}
+	out := mustRun(t, r.dir, "--age-weighted", ".", "2024-03-01", "2024-03-02")
+	for _, tt := range tests {
+		if row := tableRow(out, tt.label); !slices.Equal(row, tt.want) {
+			t.Errorf("%s: %q, want %q:\n%s", tt.label, row, tt.want, out)
+		}
+	}
+
+	// The column is left out by default.
+	if out := mustRun(t, r.dir, ".", "2024-03-01", "2024-03-02"); strings.Contains(out, "Age Weighted") {
+		t.Errorf("without --age-weighted:\n%s", out)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+const (
+	// minWeekdaySamples is how many other days of the same weekday a day is
+	// needed to be compared with.
+	minWeekdaySamples = 3
+
+	// anomalyZScore is how many standard deviations a day must lie away
+	// from its weekday average to be reported.
+	anomalyZScore = 2
+)
+
+// anomaly is a day whose total changes stand out from the other days of the
+// same weekday.
+type anomaly struct {
+	Date    time.Time
+	Changes int
+	Mean    float64
+	ZScore  float64 // NaN when the other days all have the same changes
+}
+
+// findAnomalies compares the total changes of every day of the range with
+// the other days of the same weekday, days without commits counting as
+// zero. The day itself is left out of its average, so a single outlier does
+// not hide itself by raising it. Days of --exclude-range are skipped.
+func findAnomalies(report *Report) []anomaly {
+	var days [7][]time.Time
+	changes := make(map[string]float64)
+	for d := report.StartDate; !d.After(report.EndDate); d = d.AddDate(0, 0, 1) {
+		if excluded(d, report.Options.ExcludeRanges) {
+			continue
+		}
+		if stats, ok := report.DailyStats[d.Format("2006-01-02")]; ok {
+			changes[d.Format("2006-01-02")] = float64(stats.Changes)
+		}
+		days[d.Weekday()] = append(days[d.Weekday()], d)
+	}
+
+	var anomalies []anomaly
+	for d := report.StartDate; !d.After(report.EndDate); d = d.AddDate(0, 0, 1) {
+		same := days[d.Weekday()]
+		if len(same)-1 < minWeekdaySamples || excluded(d, report.Options.ExcludeRanges) {
+			continue
+		}
+
+		var sum, sumSquares float64
+		for _, other := range same {
+			if !other.Equal(d) {
+				value := changes[other.Format("2006-01-02")]
+				sum += value
+				sumSquares += value * value
+			}
+		}
+		n := float64(len(same) - 1)
+		mean := sum / n
+		stddev := math.Sqrt(max(sumSquares/n-mean*mean, 0))
+
+		value := changes[d.Format("2006-01-02")]
+		switch {
+		case stddev > 0 && math.Abs(value-mean)/stddev >= anomalyZScore:
+			anomalies = append(anomalies, anomaly{d, int(value), mean, (value - mean) / stddev})
+		case stddev == 0 && value != mean:
+			anomalies = append(anomalies, anomaly{d, int(value), mean, math.NaN()})
+		}
+	}
+	return anomalies
+}
+
+// printAnomalies prints the days deviating from their weekday average, with
+// the deviation in percent and as a z-score.
+func printAnomalies(w io.Writer, report *Report) {
+	anomalies := findAnomalies(report)
+	if len(anomalies) == 0 {
+		fmt.Fprintf(w, "Anomalies: none\n")
+		return
+	}
+
+	fmt.Fprintf(w, "Anomalies:\n")
+	for _, a := range anomalies {
+		weekday := weekdayName(a.Date.Weekday())
+		deviation := "no changes on the others"
+		if a.Mean > 0 {
+			deviation = fmt.Sprintf("%s%% vs the %s average of %s", formatSigned((float64(a.Changes)-a.Mean)/a.Mean*100, 0), weekday, formatFloat(a.Mean, 1))
+		}
+		if !math.IsNaN(a.ZScore) {
+			deviation += ", z " + formatSigned(a.ZScore, 1)
+		}
+		fmt.Fprintf(w, "  %s %s: %d changes, %s\n", a.Date.Format("2006-01-02"), weekday, a.Changes, deviation)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnomalies(t *testing.T) {
+	date := func(s string) time.Time {
+		d, err := parseDate(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	// The range from 2024-03-04 to 03-31 has four of every weekday.
+	tests := []struct {
+		name    string
+		end     string
+		changes map[string]int
+		exclude []period
+		want    string
+	}{
+		{
+			name:    "a day above identical ones",
+			end:     "2024-03-31",
+			changes: map[string]int{"2024-03-04": 10, "2024-03-11": 10, "2024-03-18": 10, "2024-03-25": 100},
+			want:    "Anomalies:\n  2024-03-25 Monday: 100 changes, +900% vs the Monday average of 10.0\n",
+		},
+		{
+			name:    "the only day with commits",
+			end:     "2024-03-31",
+			changes: map[string]int{"2024-03-12": 5},
+			want:    "Anomalies:\n  2024-03-12 Tuesday: 5 changes, no changes on the others\n",
+		},
+		{
+			name:    "a z-score above 2",
+			end:     "2024-03-31",
+			changes: map[string]int{"2024-03-06": 10, "2024-03-13": 12, "2024-03-20": 14, "2024-03-27": 20},
+			want:    "Anomalies:\n  2024-03-27 Wednesday: 20 changes, +67% vs the Wednesday average of 12.0, z +4.9\n",
+		},
+		{
+			name:    "a z-score below 2",
+			end:     "2024-03-31",
+			changes: map[string]int{"2024-03-06": 10, "2024-03-13": 14, "2024-03-20": 10, "2024-03-27": 14},
+			want:    "Anomalies: none\n",
+		},
+		{
+			name:    "too few weeks",
+			end:     "2024-03-24",
+			changes: map[string]int{"2024-03-04": 10, "2024-03-11": 10, "2024-03-18": 100},
+			want:    "Anomalies: none\n",
+		},
+		{
+			name:    "excluded days",
+			end:     "2024-03-31",
+			changes: map[string]int{"2024-03-04": 10, "2024-03-11": 10, "2024-03-18": 10, "2024-03-25": 100},
+			exclude: []period{{date("2024-03-25"), date("2024-03-25")}},
+			want:    "Anomalies: none\n",
+		},
+	}
+	for _, tt := range tests {
+		report := &Report{
+			StartDate:  date("2024-03-04"),
+			EndDate:    date(tt.end),
+			DailyStats: make(map[string]*DailyStats),
+			Options:    &Options{ExcludeRanges: tt.exclude},
+		}
+		for day, changes := range tt.changes {
+			report.DailyStats[day] = &DailyStats{Changes: changes}
+		}
+		var b strings.Builder
+		printAnomalies(&b, report)
+		if b.String() != tt.want {
+			t.Errorf("%s: printed:\n%s\nwant:\n%s", tt.name, b.String(), tt.want)
+		}
+	}
+
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-04T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	if out := mustRun(t, r.dir, "--anomalies", ".", "2024-03-04", "2024-03-10"); !strings.HasSuffix(out, "Anomalies: none\n") {
+		t.Errorf("--anomalies printed:\n%s", out)
+	}
+	if out := mustRun(t, r.dir, ".", "2024-03-04", "2024-03-10"); strings.Contains(out, "Anomalies") {
+		t.Errorf("anomalies printed without --anomalies:\n%s", out)
+	}
+}
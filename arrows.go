@@ -0,0 +1,39 @@
+package main
+
+import "strings"
+
+// deltaArrows is set by --arrows to mark the changes shown by --show-delta,
+// --diff and --file-count with an arrow before their sign.
+var deltaArrows bool
+
+// deltaArrow returns the arrow for a change of delta and the color it is
+// drawn in, empty for no change.
+func deltaArrow(delta int) (arrow, color string) {
+	switch {
+	case delta > 0:
+		return "↑", colorGreen
+	case delta < 0:
+		return "↓", colorRed
+	}
+	return "→", ""
+}
+
+// formatArrowDelta formats delta like formatDelta, led by its arrow with
+// --arrows.
+func formatArrowDelta(delta int) string {
+	if !deltaArrows {
+		return formatDelta(delta)
+	}
+	arrow, _ := deltaArrow(delta)
+	return arrow + " " + formatDelta(delta)
+}
+
+// colorArrow colors the arrow of delta within cell, which is padded to its
+// width before, as the color codes would count toward it.
+func colorArrow(cell string, delta int) string {
+	arrow, color := deltaArrow(delta)
+	if !deltaArrows || color == "" {
+		return cell
+	}
+	return strings.Replace(cell, arrow, color+arrow+colorReset, 1)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatArrowDelta(t *testing.T) {
+	defer func(arrows bool) { deltaArrows = arrows }(deltaArrows)
+
+	tests := []struct {
+		arrows bool
+		delta  int
+		want   string
+	}{
+		{false, 5, "+5"},
+		{false, -3, "-3"},
+		{false, 0, "0"},
+		{true, 5, "↑ +5"},
+		{true, -3, "↓ -3"},
+		{true, 0, "→ 0"},
+	}
+	for _, tt := range tests {
+		deltaArrows = tt.arrows
+		if got := formatArrowDelta(tt.delta); got != tt.want {
+			t.Errorf("formatArrowDelta(%d) with arrows %t = %q, want %q", tt.delta, tt.arrows, got, tt.want)
+		}
+	}
+}
+
+func TestColorArrow(t *testing.T) {
+	defer func(arrows bool) { deltaArrows = arrows }(deltaArrows)
+
+	tests := []struct {
+		arrows bool
+		delta  int
+		want   string
+	}{
+		{false, 5, "  +5  "},
+		{true, 5, " " + colorGreen + "↑" + colorReset + " +5 "},
+		{true, -3, " " + colorRed + "↓" + colorReset + " -3 "},
+		// Flat arrows are left uncolored.
+		{true, 0, " → 0  "},
+	}
+	for _, tt := range tests {
+		deltaArrows = tt.arrows
+		cell := centerText(formatArrowDelta(tt.delta), 6)
+		got := colorArrow(cell, tt.delta)
+		if got != tt.want {
+			t.Errorf("colorArrow(%q, %d) with arrows %t = %q, want %q", cell, tt.delta, tt.arrows, got, tt.want)
+		}
+		// The colors do not change the width the cell was padded to.
+		if textWidth(stripANSI(got)) != 6 {
+			t.Errorf("colorArrow(%q, %d) = %q, %d wide, want 6", cell, tt.delta, got, textWidth(stripANSI(got)))
+		}
+	}
+}
+
+func TestFormatChangesDeltaArrows(t *testing.T) {
+	defer func(arrows bool) { deltaArrows = arrows }(deltaArrows)
+	deltaArrows = true
+
+	tests := []struct {
+		delta int
+		want  string
+	}{
+		{5, " " + colorGreen + "↑ +5" + colorReset},
+		{-3, " " + colorRed + "↓ -3" + colorReset},
+		{0, " → 0"},
+	}
+	for _, tt := range tests {
+		if got := formatChangesDelta(tt.delta); got != tt.want {
+			t.Errorf("formatChangesDelta(%d) with arrows = %q, want %q", tt.delta, got, tt.want)
+		}
+	}
+}
+
+func TestArrows(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-02-28T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(2)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(4)}})
+
+	// Arrows are only printed to terminals, which the tests' stdout is not.
+	out := stripANSI(mustRun(t, r.dir, "--arrows", "--show-delta", ".", "2024-03-01", "2024-03-02"))
+	row := tableRow(out, "2024-03-02")
+	if row == nil || !strings.HasSuffix(row[len(row)-1], "+1") {
+		t.Errorf("2024-03-02: %v, want the delta +1:\n%s", row, out)
+	}
+	if strings.ContainsAny(out, "↑↓→") {
+		t.Errorf("--arrows printed arrows to a pipe:\n%s", out)
+	}
+}
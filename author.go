@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// authorKey identifies the author of a commit in the by-author output. By
+// default name and email are both part of the key; with --author-email-only
+// only the lower-cased email is, so name changes do not split an author.
+// --anonymize hides the names anyway and identifies authors by email too,
+// so an author gets the same pseudonym in every output mode.
+func authorKey(sig object.Signature, opts *Options) string {
+	if opts.AuthorEmailOnly || opts.Anonymize {
+		return strings.ToLower(sig.Email)
+	}
+	return fmt.Sprintf("%s <%s>", sig.Name, sig.Email)
+}
+
+// readAuthorList returns the authors listed in filename for --authors-file,
+// one per line as a name, an email or `Name <email>`, of which only the
+// email is kept. Blank lines and lines starting with # are skipped.
+func readAuthorList(filename string) ([]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var authors []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if start := strings.LastIndex(line, "<"); start >= 0 && strings.HasSuffix(line, ">") {
+			line = line[start+1 : len(line)-1]
+		}
+		authors = append(authors, line)
+	}
+	return authors, scanner.Err()
+}
+
+// authorSet returns the lower-cased authors of list, or nil when it is empty
+// and every author is included.
+func authorSet(list []string) map[string]bool {
+	if len(list) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(list))
+	for _, author := range list {
+		set[strings.ToLower(author)] = true
+	}
+	return set
+}
+
+// includesAuthor reports whether the author with name and email is in set,
+// by either of them regardless of case. A nil set includes everyone.
+func includesAuthor(set map[string]bool, name, email string) bool {
+	return set == nil || set[strings.ToLower(name)] || set[strings.ToLower(email)]
+}
+
+func getAuthorStats(repo *git.Repository, startDate, endDate time.Time, opts *Options) (map[string]*DailyStats, error) {
+	return getGroupStats(repo, startDate, endDate, opts, func(c *object.Commit, stat object.FileStat) string {
+		return authorKey(c.Author, opts)
+	})
+}
+
+// pseudonyms maps author keys to pseudonyms for --anonymize, such as
+// "Author QJXW". A pseudonym is derived from a hash of the key, so an author
+// gets the same one on every run. Only the authors of the report who would
+// otherwise share one get longer pseudonyms, so the others do not change
+// when authors come and go. Keys may repeat.
+func pseudonyms(keys []string) map[string]string {
+	pending := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		pending[key] = true
+	}
+
+	names := make(map[string]string, len(pending))
+	for length := 4; len(pending) > 0; length++ {
+		owners := make(map[string][]string, len(pending))
+		for key := range pending {
+			name := pseudonym(key, length)
+			owners[name] = append(owners[name], key)
+		}
+		for name, keys := range owners {
+			if len(keys) == 1 {
+				names[keys[0]] = name
+				delete(pending, keys[0])
+			}
+		}
+	}
+	return names
+}
+
+// pseudonym spells the first length bytes of the hash of key as letters.
+func pseudonym(key string, length int) string {
+	sum := sha256.Sum256([]byte(key))
+	letters := make([]byte, length)
+	for i := range letters {
+		letters[i] = 'A' + sum[i]%26
+	}
+	return "Author " + string(letters)
+}
+
+// anonymizeStats replaces the authors of groupStats with their pseudonyms.
+func anonymizeStats(groupStats map[string]*DailyStats) map[string]*DailyStats {
+	keys := make([]string, 0, len(groupStats))
+	for key := range groupStats {
+		keys = append(keys, key)
+	}
+
+	names := pseudonyms(keys)
+	anonymized := make(map[string]*DailyStats, len(groupStats))
+	for key, stats := range groupStats {
+		anonymized[names[key]] = stats
+	}
+	return anonymized
+}
+
+// shareColumn shows the share of the total changes made by each row, with
+// one decimal. A row is at 0% when there are no changes at all.
+func shareColumn(groupStats map[string]*DailyStats) tableColumn {
+	total := 0
+	for _, stats := range groupStats {
+		total += stats.Changes
+	}
+
+	return tableColumn{"Share", shareWidth, func(stats *DailyStats) string {
+		share := 0.0
+		if total > 0 {
+			share = 100 * float64(stats.Changes) / float64(total)
+		}
+		return formatPercent(share, 1)
+	}}
+}
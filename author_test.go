@@ -0,0 +1,309 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestAuthorKey(t *testing.T) {
+	tests := []struct {
+		name, email string
+		emailOnly   bool
+		want        string
+	}{
+		{"Alice Smith", "alice@example.com", false, "Alice Smith <alice@example.com>"},
+		{"Alice Smith", "alice@example.com", true, "alice@example.com"},
+		{"alice", "Alice@Example.com", true, "alice@example.com"},
+		{"Alice", "", true, ""},
+	}
+	for _, tt := range tests {
+		sig := object.Signature{Name: tt.name, Email: tt.email}
+		if got := authorKey(sig, &Options{AuthorEmailOnly: tt.emailOnly}); got != tt.want {
+			t.Errorf("authorKey(%s <%s>) with emailOnly %t = %q, want %q", tt.name, tt.email, tt.emailOnly, got, tt.want)
+		}
+	}
+}
+
+func TestAuthorEmailOnly(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", author: "Alice Smith <alice@example.com>", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", author: "Alice S. <Alice@Example.com>", files: map[string]string{"a": lines(3)}})
+	r.commit(testCommit{when: "2024-03-02T11:00:00Z", author: "Bob <bob@example.com>", files: map[string]string{"b": lines(1)}})
+
+	tests := []struct {
+		flag string
+		rows map[string]string // total changes by author
+	}{
+		{"", map[string]string{
+			"Alice Smith <alice@example.com>": "1",
+			"Alice S. <Alice@Example.com>":    "2",
+			"Bob <bob@example.com>":           "1",
+		}},
+		{"--author-email-only", map[string]string{
+			"alice@example.com": "3",
+			"bob@example.com":   "1",
+		}},
+	}
+	for _, tt := range tests {
+		args := []string{"--by-author", ".", "2024-03-01", "2024-03-02"}
+		if tt.flag != "" {
+			args = append([]string{tt.flag}, args...)
+		}
+		out := mustRun(t, r.dir, args...)
+
+		rows := 0
+		for _, line := range strings.Split(out, "\n") {
+			if strings.Contains(line, "@") {
+				rows++
+			}
+		}
+		if rows != len(tt.rows) {
+			t.Errorf("%q: %d authors, want %d:\n%s", tt.flag, rows, len(tt.rows), out)
+		}
+		for author, changes := range tt.rows {
+			if row := tableRow(out, author); row == nil || row[4] != changes {
+				t.Errorf("%q: row of %s is %q, want %s changes", tt.flag, author, row, changes)
+			}
+		}
+	}
+}
+
+func TestShareColumn(t *testing.T) {
+	tests := []struct {
+		changes []int
+		want    []string
+	}{
+		{[]int{50, 30, 20}, []string{"50.0%", "30.0%", "20.0%"}},
+		{[]int{1, 1, 1}, []string{"33.3%", "33.3%", "33.3%"}},
+		{[]int{2, 1}, []string{"66.7%", "33.3%"}},
+		{[]int{7, 0}, []string{"100.0%", "0.0%"}},
+		{[]int{0, 0}, []string{"0.0%", "0.0%"}},
+	}
+	for _, tt := range tests {
+		groupStats := make(map[string]*DailyStats)
+		for i, changes := range tt.changes {
+			groupStats[fmt.Sprint(i)] = &DailyStats{Changes: changes}
+		}
+		share := shareColumn(groupStats)
+		for i := range tt.changes {
+			if got := share.value(groupStats[fmt.Sprint(i)]); got != tt.want[i] {
+				t.Errorf("%d of %v changes: %s, want %s", tt.changes[i], tt.changes, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestAuthorPercentage(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", author: "Alice <alice@example.com>", files: map[string]string{"a": lines(6)}})
+	r.commit(testCommit{when: "2024-03-01T11:00:00Z", author: "Bob <bob@example.com>", files: map[string]string{"b": lines(3)}})
+	r.commit(testCommit{when: "2024-03-01T12:00:00Z", author: "Carol <carol@example.com>", files: map[string]string{"c": lines(1)}})
+
+	out := mustRun(t, r.dir, "--by-author", "--author-percentage", ".", "2024-03-01", "2024-03-01")
+	for author, want := range map[string]string{
+		"Alice <alice@example.com>": "60.0%",
+		"Bob <bob@example.com>":     "30.0%",
+		"Carol <carol@example.com>": "10.0%",
+	} {
+		row := tableRow(out, author)
+		if row == nil || row[len(row)-1] != want {
+			t.Errorf("%s: %q, want a share of %s", author, row, want)
+		}
+	}
+}
+
+func TestPseudonyms(t *testing.T) {
+	// With this many authors some share the first four letters of their
+	// hash, so their pseudonyms must grow longer to tell them apart.
+	var keys []string
+	for i := 0; i < 5000; i++ {
+		keys = append(keys, fmt.Sprintf("Author %d <author%d@example.com>", i, i))
+	}
+	keys = append(keys, keys[0])
+
+	names := pseudonyms(keys)
+	owners := make(map[string]string)
+	for _, key := range keys {
+		name := names[key]
+		if !strings.HasPrefix(name, "Author ") || strings.Contains(name, "@") {
+			t.Fatalf("%s is called %q", key, name)
+		}
+		if owner, ok := owners[name]; ok && owner != key {
+			t.Fatalf("%s and %s are both called %s", owner, key, name)
+		}
+		owners[name] = key
+	}
+	if len(owners) != len(keys)-1 {
+		t.Errorf("%d pseudonyms for %d authors", len(owners), len(keys)-1)
+	}
+
+	// Only the authors who collide get longer pseudonyms, so the others
+	// keep theirs whoever else is in the report.
+	short := make(map[string]int)
+	for _, key := range keys[:len(keys)-1] {
+		short[pseudonym(key, 4)]++
+	}
+	lengthened := 0
+	for _, key := range keys[:len(keys)-1] {
+		switch {
+		case short[pseudonym(key, 4)] == 1 && names[key] != pseudonym(key, 4):
+			t.Errorf("%s is called %s, want %s as no other author shares it", key, names[key], pseudonym(key, 4))
+		case short[pseudonym(key, 4)] > 1:
+			lengthened++
+		}
+	}
+	if lengthened == 0 {
+		t.Error("no pseudonyms collide at four letters")
+	}
+
+	// An author keeps their pseudonym from run to run, while the other
+	// authors of the report do not collide with it.
+	tests := []struct{ keys []string }{
+		{[]string{"alice@example.com"}},
+		{[]string{"alice@example.com", "bob@example.com"}},
+		{[]string{"bob@example.com", "alice@example.com", "carol@example.com"}},
+		{append([]string{"alice@example.com"}, keys...)},
+	}
+	alice := pseudonym("alice@example.com", 4)
+	for _, tt := range tests {
+		if got := pseudonyms(tt.keys)["alice@example.com"]; got != alice {
+			t.Errorf("alice@example.com among %d authors is %s, want %s", len(tt.keys), got, alice)
+		}
+	}
+}
+
+func TestAnonymize(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", author: "Alice <alice@example.com>", files: map[string]string{".mailmap": "Alice <alice@example.com> <alice@laptop.local>\n"}})
+	r.commit(testCommit{when: "2024-03-01T11:00:00Z", author: "Bob <bob@example.com>", files: map[string]string{"b": lines(2)}})
+	// The same person under another name and, before the .mailmap, email.
+	r.commit(testCommit{when: "2024-03-01T12:00:00Z", author: "Alice Smith <Alice@example.com>", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-01T13:00:00Z", author: "alice <alice@laptop.local>", files: map[string]string{"a": lines(3)}})
+
+	alice, bob := pseudonym("alice@example.com", 4), pseudonym("bob@example.com", 4)
+	tests := []struct {
+		mode []string
+		want map[string]int // the times each pseudonym is shown
+	}{
+		{[]string{"--by-author"}, map[string]int{alice: 1, bob: 1}},
+		{[]string{"--after-hours-report"}, map[string]int{alice: 1, bob: 1}},
+		{[]string{"--commits-table"}, map[string]int{alice: 3, bob: 1}},
+		{[]string{"--format", "commits-json"}, map[string]int{alice: 3, bob: 1}},
+		{[]string{"--format", "authors-json"}, map[string]int{alice: 1, bob: 1}},
+	}
+	for _, tt := range tests {
+		args := append(append([]string{"--anonymize"}, tt.mode...), ".", "2024-03-01", "2024-03-01")
+		out := mustRun(t, r.dir, args...)
+		for _, identity := range []string{"Alice", "alice@", "laptop", "Smith", "Bob", "bob@"} {
+			if strings.Contains(out, identity) {
+				t.Errorf("%v shows %q:\n%s", tt.mode, identity, out)
+			}
+		}
+		// Each person has the same pseudonym in every mode.
+		for name, want := range tt.want {
+			if got := strings.Count(out, name); got != want {
+				t.Errorf("%v shows %s %d times, want %d:\n%s", tt.mode, name, got, want, out)
+			}
+		}
+		if again := mustRun(t, r.dir, args...); again != out {
+			t.Errorf("%v differs between runs:\n%s\nthen:\n%s", tt.mode, out, again)
+		}
+	}
+}
+
+func TestReadAuthorList(t *testing.T) {
+	tests := []struct {
+		content string
+		want    []string
+	}{
+		{"alice@example.com\nBob\n", []string{"alice@example.com", "Bob"}},
+		{"Alice Smith <alice@example.com>\n  Bob <bob@example.com>  \n", []string{"alice@example.com", "bob@example.com"}},
+		{"# the core team\n\nalice@example.com\n   \n#bob@example.com\n", []string{"alice@example.com"}},
+		{"Carol <carol\n", []string{"Carol <carol"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		filename := filepath.Join(t.TempDir(), "authors")
+		if err := os.WriteFile(filename, []byte(tt.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := readAuthorList(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("readAuthorList(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+
+	if _, err := readAuthorList(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("a missing file was read")
+	}
+}
+
+func TestIncludesAuthor(t *testing.T) {
+	set := authorSet([]string{"Alice@Example.com", "Bob"})
+	tests := []struct {
+		set         map[string]bool
+		name, email string
+		want        bool
+	}{
+		{set, "Alice", "alice@example.com", true},
+		{set, "Alice", "ALICE@EXAMPLE.COM", true},
+		{set, "bob", "bob@work.example.com", true},
+		{set, "Carol", "carol@example.com", false},
+		{set, "Alice Smith", "alice@home.example.com", false},
+		{authorSet(nil), "Carol", "carol@example.com", true},
+	}
+	for _, tt := range tests {
+		if got := includesAuthor(tt.set, tt.name, tt.email); got != tt.want {
+			t.Errorf("includesAuthor(%v, %q, %q) = %t, want %t", tt.set, tt.name, tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestAuthorsFile(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-02-28T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", author: "Alice <alice@example.com>", files: map[string]string{"a": lines(2)}})
+	r.commit(testCommit{when: "2024-03-01T11:00:00Z", author: "Bob <bob@example.com>", files: map[string]string{"a": lines(4)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", author: "Carol <carol@example.com>", files: map[string]string{"a": lines(8)}})
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		filename := filepath.Join(dir, name)
+		if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return filename
+	}
+	alice := write("alice", "Alice <alice@example.com>\n")
+	others := write("others", "# not Alice\nbob\nCAROL@example.com\n")
+
+	tests := []struct {
+		flags     []string
+		additions string
+		authors   string
+	}{
+		{nil, "7", "3"},
+		{[]string{"--authors-file", alice}, "1", "1"},
+		{[]string{"--authors-file", others}, "6", "2"},
+		{[]string{"--authors-file", alice, "--authors-file", others}, "7", "3"},
+	}
+	for _, tt := range tests {
+		out := mustRun(t, r.dir, append(tt.flags, "--authors", ".", "2024-03-01", "2024-03-02")...)
+		if row := tableRow(out, "Total"); row == nil || row[2] != tt.additions || row[5] != tt.authors {
+			t.Errorf("%v: total row %v, want %s additions by %s authors", tt.flags, row, tt.additions, tt.authors)
+		}
+	}
+
+	if stdout, _, code := runGitStat(t, r.dir, "--authors-file", filepath.Join(dir, "missing"), ".", "2024-03-01", "2024-03-02"); code == 0 || !strings.Contains(stdout, "no such file or directory") {
+		t.Errorf("a missing --authors-file exited with %d: %s", code, stdout)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// formatAuthorsJSON writes the changes of every author in each --period
+// instead of the daily report. Like formatCommitsJSON it needs the commits
+// themselves, so it is not one of renderers.
+const formatAuthorsJSON = "authors-json"
+
+type jsonPeriodAuthor struct {
+	Email     string `json:"email"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	Commits   int    `json:"commits"`
+}
+
+type jsonPeriod struct {
+	PeriodStart string             `json:"period_start"`
+	PeriodEnd   string             `json:"period_end"`
+	Authors     []jsonPeriodAuthor `json:"authors"`
+}
+
+// writeAuthorsJSON writes the periods of the range in order, each with its
+// authors identified by their lower-cased email and sorted by it. Periods
+// without commits are written with no authors, so every period of a
+// dashboard has an entry.
+func writeAuthorsJSON(w io.Writer, repo *git.Repository, startDate, endDate time.Time, opts *Options) error {
+	periodName := opts.Period
+	if opts.RelativeWeeks {
+		periodName = periodSprint
+	}
+	periods := splitPeriods(startDate, endDate, periodName)
+
+	authors := make([]map[string]*jsonPeriodAuthor, len(periods))
+	for i := range authors {
+		authors[i] = make(map[string]*jsonPeriodAuthor)
+	}
+
+	err := walkCommits(repo, startDate, endDate, opts, func(c *object.Commit, stats object.FileStats) error {
+		day, err := parseDate(c.Author.When.Format("2006-01-02"))
+		if err != nil {
+			return err
+		}
+		i := sort.Search(len(periods), func(i int) bool { return !periods[i].End.Before(day) })
+		if i == len(periods) || day.Before(periods[i].Start) {
+			return nil
+		}
+
+		email := strings.ToLower(c.Author.Email)
+		author, ok := authors[i][email]
+		if !ok {
+			author = &jsonPeriodAuthor{Email: email}
+			authors[i][email] = author
+		}
+		author.Commits++
+		for _, stat := range stats {
+			author.Additions += stat.Addition
+			author.Deletions += stat.Deletion
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var names map[string]string
+	if opts.Anonymize {
+		var emails []string
+		for _, byEmail := range authors {
+			for email := range byEmail {
+				emails = append(emails, email)
+			}
+		}
+		names = pseudonyms(emails)
+	}
+
+	out := make([]jsonPeriod, len(periods))
+	for i, p := range periods {
+		out[i] = jsonPeriod{
+			PeriodStart: p.Start.Format("2006-01-02"),
+			PeriodEnd:   p.End.Format("2006-01-02"),
+			Authors:     []jsonPeriodAuthor{},
+		}
+		for _, author := range authors[i] {
+			if names != nil {
+				author.Email = names[author.Email]
+			}
+			out[i].Authors = append(out[i].Authors, *author)
+		}
+		sort.Slice(out[i].Authors, func(a, b int) bool { return out[i].Authors[a].Email < out[i].Authors[b].Email })
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAuthorsJSON(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-02-28T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-04T10:00:00Z", author: "Alice <Alice@Example.com>", files: map[string]string{"a": lines(3)}})
+	r.commit(testCommit{when: "2024-03-05T10:00:00Z", author: "Bob <bob@example.com>", files: map[string]string{"b": lines(2)}})
+	r.commit(testCommit{when: "2024-03-06T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-12T10:00:00Z", author: "Bob <bob@example.com>", files: map[string]string{"b": lines(5)}})
+
+	alice := func(additions, deletions, commits int) jsonPeriodAuthor {
+		return jsonPeriodAuthor{"alice@example.com", additions, deletions, commits}
+	}
+	bob := func(additions, deletions, commits int) jsonPeriodAuthor {
+		return jsonPeriodAuthor{"bob@example.com", additions, deletions, commits}
+	}
+	tests := []struct {
+		args []string
+		want []jsonPeriod
+	}{
+		{
+			[]string{"--period", "week", ".", "2024-03-04", "2024-03-24"},
+			[]jsonPeriod{
+				{"2024-03-04", "2024-03-10", []jsonPeriodAuthor{alice(2, 2, 2), bob(2, 0, 1)}},
+				{"2024-03-11", "2024-03-17", []jsonPeriodAuthor{bob(3, 0, 1)}},
+				{"2024-03-18", "2024-03-24", []jsonPeriodAuthor{}},
+			},
+		},
+		{
+			[]string{".", "2024-03-04", "2024-03-05"},
+			[]jsonPeriod{
+				{"2024-03-04", "2024-03-04", []jsonPeriodAuthor{alice(2, 0, 1)}},
+				{"2024-03-05", "2024-03-05", []jsonPeriodAuthor{bob(2, 0, 1)}},
+			},
+		},
+		{
+			[]string{"--period", "month", ".", "2024-03-01", "2024-03-31"},
+			[]jsonPeriod{
+				{"2024-03-01", "2024-03-31", []jsonPeriodAuthor{alice(2, 2, 2), bob(5, 0, 2)}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		var got []jsonPeriod
+		out := mustRun(t, r.dir, append([]string{"--format", "authors-json"}, tt.args...)...)
+		if err := json.Unmarshal([]byte(out), &got); err != nil {
+			t.Fatalf("%v: %v in:\n%s", tt.args, err, out)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: %+v, want %+v", tt.args, got, tt.want)
+		}
+	}
+
+	var anonymized []jsonPeriod
+	out := mustRun(t, r.dir, "--format", "authors-json", "--anonymize", "--period", "month", ".", "2024-03-01", "2024-03-31")
+	if err := json.Unmarshal([]byte(out), &anonymized); err != nil {
+		t.Fatal(err)
+	}
+	names := pseudonyms([]string{"alice@example.com", "bob@example.com"})
+	if len(anonymized) != 1 || len(anonymized[0].Authors) != 2 {
+		t.Fatalf("--anonymize: %+v", anonymized)
+	}
+	for _, author := range anonymized[0].Authors {
+		if author.Email != names["alice@example.com"] && author.Email != names["bob@example.com"] {
+			t.Errorf("--anonymize: the author %q is not a pseudonym", author.Email)
+		}
+	}
+
+	if stdout, stderr, code := runGitStat(t, r.dir, "--format", "table,authors-json", "--output", "out.json", ".", "2024-03-01", "2024-03-31"); code == 0 || !strings.Contains(stdout+stderr, "--format authors-json cannot be combined with other formats") {
+		t.Errorf("--format table,authors-json exited with %d: %s%s", code, stdout, stderr)
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// findRepos returns the git repositories below dir, sorted by path.
+// Repositories nested inside another repository and hidden directories are
+// not searched.
+func findRepos(dir string) ([]string, error) {
+	var repos []string
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			warnf("skipping %s: %v", path, err)
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+
+		if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
+			repos = append(repos, path)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+
+	return repos, err
+}
+
+// getBatchStats sums up the stats of every repository, keyed by its path
+// relative to root. Repositories are analyzed a few at a time; those that
+// cannot be read are skipped with a warning.
+func getBatchStats(root string, repos []string, startDate, endDate time.Time, opts *Options) map[string]*DailyStats {
+	repoStats := make(map[string]*DailyStats)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+
+	for _, path := range repos {
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			name, err := filepath.Rel(root, path)
+			if err != nil {
+				name = path
+			}
+
+			stats, err := getRepoTotal(path, startDate, endDate, opts)
+			if err != nil {
+				warnf("skipping %s: %v", name, err)
+				return
+			}
+
+			mu.Lock()
+			repoStats[name] = stats
+			mu.Unlock()
+		}(path)
+	}
+
+	wg.Wait()
+	return repoStats
+}
+
+// getRepoTotal returns the total stats of the repository at path.
+func getRepoTotal(path string, startDate, endDate time.Time, opts *Options) (*DailyStats, error) {
+	repo, err := openRepository(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dailyStats, err := getGitStats(repo, startDate, endDate, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return totalStats(&Report{DailyStats: dailyStats, Options: opts}), nil
+}
+
+// printBatchTable prints one row per repository followed by the total of all
+// of them. The total adds up the repositories like totalStats adds up days;
+// files of different repositories are never the same file.
+func printBatchTable(w io.Writer, repoStats map[string]*DailyStats, opts *Options) {
+	totalOpts := *opts
+	totalOpts.DedupeAcrossDays = false
+	total := totalStats(&Report{DailyStats: repoStats, Options: &totalOpts})
+
+	rows := make([]*DailyStats, 0, len(repoStats)+1)
+	for _, stats := range repoStats {
+		rows = append(rows, stats)
+	}
+	fitColumns(append(rows, total))
+
+	repos := sortedByChanges(repoStats)
+	fitLabels(append(repos, "Total"))
+
+	printTableHeader(w, "Repository")
+
+	for _, name := range repos {
+		printTableRow(w, name, repoStats[name], "")
+	}
+	printTableRow(w, "Total", total, "")
+}
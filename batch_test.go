@@ -0,0 +1,108 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// testRepoAt creates a repository at dir, like newTestRepo does in a new
+// temporary directory.
+func testRepoAt(t *testing.T, dir string) *testRepo {
+	t.Helper()
+
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testRepo{t: t, dir: dir, repo: repo, wt: wt}
+}
+
+// batchDir returns a directory holding the repositories api and web/app
+// along with directories that are not repositories or are not searched.
+func batchDir(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+	api := testRepoAt(t, filepath.Join(root, "api"))
+	api.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(3), "b": lines(1)}})
+	api.commit(testCommit{when: "2024-03-02T10:00:00Z", author: "Bob <bob@example.com>", files: map[string]string{"a": lines(5)}})
+
+	app := testRepoAt(t, filepath.Join(root, "web", "app"))
+	app.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(10)}})
+	// Repositories within repositories and hidden directories are skipped.
+	testRepoAt(t, filepath.Join(root, "web", "app", "vendor", "lib")).commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	testRepoAt(t, filepath.Join(root, ".cache", "repo")).commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(1)}})
+
+	if err := os.MkdirAll(filepath.Join(root, "notes", "2024"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "notes", "todo.txt"), []byte("todo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestFindRepos(t *testing.T) {
+	root := batchDir(t)
+
+	repos, err := findRepos(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(root, "api"), filepath.Join(root, "web", "app")}
+	if !slices.Equal(repos, want) {
+		t.Errorf("findRepos = %q, want %q", repos, want)
+	}
+
+	if repos, err := findRepos(filepath.Join(root, "notes")); err != nil || len(repos) != 0 {
+		t.Errorf("findRepos of a directory without repositories = %q, %v", repos, err)
+	}
+}
+
+func TestBatchRepos(t *testing.T) {
+	root := batchDir(t)
+
+	tests := []struct {
+		args []string
+		rows map[string][]string
+	}{
+		{nil, map[string][]string{
+			"api":     {"2", "6", "0", "6", "2"},
+			"web/app": {"1", "10", "0", "10", "1"},
+			"Total":   {"3", "16", "0", "16", "3"},
+		}},
+		// Alice committed to both repositories.
+		{[]string{"--authors"}, map[string][]string{
+			"api":   {"2", "6", "0", "6", "2", "2"},
+			"Total": {"3", "16", "0", "16", "2", "3"},
+		}},
+	}
+	for _, tt := range tests {
+		args := append(append([]string{"--batch-repos"}, tt.args...), root, "2024-03-01", "2024-03-02")
+		out := mustRun(t, t.TempDir(), args...)
+		for label, want := range tt.rows {
+			if row := tableRow(out, label); row == nil || !slices.Equal(row[1:], want) {
+				t.Errorf("%v: %s %v, want %v:\n%s", tt.args, label, row, want, out)
+			}
+		}
+		for _, skipped := range []string{"notes", "vendor", ".cache"} {
+			if strings.Contains(out, skipped) {
+				t.Errorf("%v: %s is reported:\n%s", tt.args, skipped, out)
+			}
+		}
+	}
+
+	empty := t.TempDir()
+	if stdout, stderr, code := runGitStat(t, empty, "--batch-repos", empty, "2024-03-01", "2024-03-02"); code == 0 || !strings.Contains(stderr, "No repositories found") {
+		t.Errorf("--batch-repos of an empty directory exited with %d: %s%s", code, stdout, stderr)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// originHead is the symbolic ref a clone uses to record the default branch
+// of its remote.
+const originHead = plumbing.ReferenceName("refs/remotes/origin/HEAD")
+
+// resolveBranch returns the commit the log walk starts from, along with a
+// name describing it. Without an explicit branch the remote's default branch
+// (refs/remotes/origin/HEAD) is used, falling back to HEAD when the
+// repository has no such ref. A detached HEAD is named by its short hash.
+func resolveBranch(repo *git.Repository, branch string) (plumbing.Hash, string, error) {
+	if branch != "" {
+		hash, err := repo.ResolveRevision(plumbing.Revision(branch))
+		if err != nil {
+			return plumbing.ZeroHash, "", fmt.Errorf("cannot resolve branch %q: %w", branch, err)
+		}
+		return *hash, branch, nil
+	}
+
+	ref, err := repo.Reference(originHead, true)
+	if err == nil {
+		return ref.Hash(), ref.Name().Short(), nil
+	}
+	if err != plumbing.ErrReferenceNotFound {
+		return plumbing.ZeroHash, "", err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return plumbing.ZeroHash, "", err
+	}
+	if head.Name() == plumbing.HEAD {
+		// Detached HEAD, as in most CI checkouts: there is no branch
+		// name to show.
+		return head.Hash(), head.Hash().String()[:7], nil
+	}
+	return head.Hash(), head.Name().Short(), nil
+}
+
+// stashRef is where `git stash` keeps the latest stash.
+const stashRef = plumbing.ReferenceName("refs/stash")
+
+// resolveRef looks up the reference given with --ref, such as refs/stash or
+// refs/notes/commits. The refs/ prefix may be left out.
+func resolveRef(repo *git.Repository, name string) (*plumbing.Reference, error) {
+	ref, err := repo.Reference(plumbing.ReferenceName(name), true)
+	if err == plumbing.ErrReferenceNotFound && !strings.HasPrefix(name, "refs/") {
+		ref, err = repo.Reference(plumbing.ReferenceName("refs/"+name), true)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve ref %q: %w", name, err)
+	}
+	return ref, nil
+}
+
+// stashFilter skips the commits git stash records the index and the
+// untracked files in, the second and third parent of the stash commit. The
+// stash commit itself already holds all of their changes.
+func stashFilter(repo *git.Repository, ref *plumbing.Reference) (func(c *object.Commit) bool, error) {
+	stash, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	helpers := make(map[plumbing.Hash]bool)
+	for _, parent := range stash.ParentHashes[min(len(stash.ParentHashes), 1):] {
+		helpers[parent] = true
+	}
+
+	return func(c *object.Commit) bool { return !helpers[c.Hash] }, nil
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestResolveBranch(t *testing.T) {
+	r := newTestRepo(t)
+	first := r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	r.checkout("main")
+	tip := r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(2)}})
+	r.checkout("topic")
+	topic := r.commit(testCommit{when: "2024-03-03T10:00:00Z", files: map[string]string{"a": lines(3)}})
+
+	originMain := plumbing.ReferenceName("refs/remotes/origin/main")
+	setRef := func(ref *plumbing.Reference) {
+		if err := r.repo.Storer.SetReference(ref); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setRef(plumbing.NewHashReference(originMain, tip))
+
+	tests := []struct {
+		name     string
+		setup    func()
+		branch   string
+		wantHash plumbing.Hash
+		wantName string
+	}{
+		{
+			name:     "the remote's default branch",
+			setup:    func() { setRef(plumbing.NewSymbolicReference(originHead, originMain)) },
+			wantHash: tip,
+			wantName: "origin/main",
+		},
+		{
+			name:     "--branch over the remote's default branch",
+			branch:   "master",
+			wantHash: first,
+			wantName: "master",
+		},
+		{
+			name: "HEAD without a remote",
+			setup: func() {
+				if err := r.repo.Storer.RemoveReference(originHead); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantHash: topic,
+			wantName: "topic",
+		},
+		{
+			name:     "detached HEAD",
+			setup:    func() { setRef(plumbing.NewHashReference(plumbing.HEAD, first)) },
+			wantHash: first,
+			wantName: first.String()[:7],
+		},
+	}
+	for _, tt := range tests {
+		if tt.setup != nil {
+			tt.setup()
+		}
+		hash, name, err := resolveBranch(r.repo, tt.branch)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if hash != tt.wantHash || name != tt.wantName {
+			t.Errorf("%s: %s (%s), want %s (%s)", tt.name, name, hash, tt.wantName, tt.wantHash)
+		}
+	}
+
+	if _, _, err := resolveBranch(r.repo, "missing"); err == nil {
+		t.Error("resolving a missing branch succeeded")
+	}
+}
+
+func TestDetachedHead(t *testing.T) {
+	r := newTestRepo(t)
+	first := r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(2)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(5)}})
+	if err := r.wt.Checkout(&git.CheckoutOptions{Hash: first}); err != nil {
+		t.Fatal(err)
+	}
+	short := first.String()[:7]
+
+	tests := []struct {
+		args   []string
+		stdout string
+		stderr string
+	}{
+		{[]string{"--format", "csv"}, "date,files_changed,additions,deletions,total_changes,commits\n2024-03-01,1,2,0,2,1\n", ""},
+		{[]string{"--validate"}, "Branch:     " + short + " (" + short + ")", ""},
+		{[]string{"--verbose"}, "", "Debug: walking commits from " + short},
+	}
+	for _, tt := range tests {
+		args := append(append([]string{}, tt.args...), ".", "2024-03-01", "2024-03-02")
+		stdout, stderr, code := runGitStat(t, r.dir, args...)
+		if code != 0 {
+			t.Errorf("%v exited with %d: %s", tt.args, code, stderr)
+			continue
+		}
+		if !strings.Contains(stdout, tt.stdout) || !strings.Contains(stderr, tt.stderr) {
+			t.Errorf("%v: stdout\n%s\nstderr\n%s\nwant %q and %q", tt.args, stdout, stderr, tt.stdout, tt.stderr)
+		}
+	}
+}
+
+// packRefs moves every branch, remote branch and tag of r into packed-refs
+// and removes their loose refs, as `git pack-refs --all` does. Symbolic refs
+// such as HEAD stay loose.
+func packRefs(t *testing.T, r *testRepo) {
+	t.Helper()
+
+	refs, err := r.repo.References()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var packed []string
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference || ref.Name() == plumbing.HEAD {
+			return nil
+		}
+		packed = append(packed, ref.Hash().String()+" "+ref.Name().String())
+		if tag, err := r.repo.TagObject(ref.Hash()); err == nil {
+			packed = append(packed, "^"+tag.Target.String())
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gitDir := filepath.Join(r.dir, ".git")
+	content := "# pack-refs with: peeled fully-peeled sorted \n" + strings.Join(packed, "\n") + "\n"
+	if err := os.WriteFile(filepath.Join(gitDir, "packed-refs"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"heads", "tags", "remotes"} {
+		if err := os.RemoveAll(filepath.Join(gitDir, "refs", dir)); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Join(gitDir, "refs", dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPackedRefs(t *testing.T) {
+	r := newTestRepo(t)
+	first := r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	r.checkout("feature")
+	feature := r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(3)}})
+	r.checkout("master")
+	tagger := parseTestAuthor("")
+	tagger.When = time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC)
+	if _, err := r.repo.CreateTag("v1.0", first, &git.CreateTagOptions{Tagger: &tagger, Message: "release"}); err != nil {
+		t.Fatal(err)
+	}
+	originMain := plumbing.ReferenceName("refs/remotes/origin/main")
+	if err := r.repo.Storer.SetReference(plumbing.NewHashReference(originMain, feature)); err != nil {
+		t.Fatal(err)
+	}
+	packRefs(t, r)
+
+	repo, err := git.PlainOpen(r.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		branch   string
+		wantHash plumbing.Hash
+		wantName string
+	}{
+		{"", first, "master"},
+		{"feature", feature, "feature"},
+		{"refs/heads/master", first, "refs/heads/master"},
+		{"v1.0", first, "v1.0"},
+	}
+	for _, tt := range tests {
+		hash, name, err := resolveBranch(repo, tt.branch)
+		if err != nil {
+			t.Errorf("resolveBranch(%q): %v", tt.branch, err)
+			continue
+		}
+		if hash != tt.wantHash || name != tt.wantName {
+			t.Errorf("resolveBranch(%q) = %s (%s), want %s (%s)", tt.branch, name, hash, tt.wantName, tt.wantHash)
+		}
+	}
+
+	// git clone leaves origin/HEAD loose, pointing to a packed branch.
+	if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(originHead, originMain)); err != nil {
+		t.Fatal(err)
+	}
+	if hash, name, err := resolveBranch(repo, ""); err != nil || hash != feature || name != "origin/main" {
+		t.Errorf("resolveBranch through origin/HEAD = %s (%s), %v, want origin/main (%s)", name, hash, err, feature)
+	}
+
+	out := stripANSI(mustRun(t, r.dir, "--branch", "feature", "--annotate", ".", "2024-03-01", "2024-03-02"))
+	if row := tableRow(out, "Total"); row[4] != "3" {
+		t.Errorf("--branch feature: %s changes, want 3:\n%s", row[4], out)
+	}
+	if row := tableRow(out, "2024-03-01"); row == nil || !strings.HasSuffix(row[len(row)-1], "[v1.0]") {
+		t.Errorf("the packed tag v1.0 is not annotated:\n%s", out)
+	}
+}
+
+func TestRef(t *testing.T) {
+	r := newTestRepo(t)
+	base := r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	// A stash commit has the index as its second parent, which holds part of
+	// the changes of the stash commit itself.
+	r.checkout("index")
+	index := r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(5)}})
+	r.checkout("master")
+	r.checkout("wip")
+	stash := r.commit(testCommit{when: "2024-03-03T10:00:00Z", files: map[string]string{"a": lines(6)}, parents: []plumbing.Hash{index}})
+	if err := r.repo.Storer.SetReference(plumbing.NewHashReference(stashRef, stash)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args []string
+		days map[string]string // the additions shown by day, "" for no row
+	}{
+		{[]string{"--ref", "refs/stash", ".", "2024-03-01"}, map[string]string{"2024-03-02": "", "2024-03-03": "5"}},
+		{[]string{"--ref", "stash", ".", "2024-03-01"}, map[string]string{"2024-03-02": "", "2024-03-03": "5"}},
+		{[]string{"--ref", "heads/index", ".", "2024-03-01"}, map[string]string{"2024-03-02": "4", "2024-03-03": ""}},
+		{[]string{"--ref", "refs/heads/wip", ".", "2024-03-01"}, map[string]string{"2024-03-02": "4", "2024-03-03": "5"}},
+		{[]string{"--ref", "stash", "--since-commit", base.String(), "."}, map[string]string{"2024-03-02": "", "2024-03-03": "5"}},
+	}
+	for _, tt := range tests {
+		out := mustRun(t, r.dir, append(tt.args, "2024-03-04")...)
+		for day, want := range tt.days {
+			row := tableRow(out, day)
+			if want == "" && row != nil {
+				t.Errorf("%v: %s has a row: %v", tt.args, day, row)
+			}
+			if want != "" && (row == nil || row[2] != want) {
+				t.Errorf("%v: %s has the row %v, want %s additions", tt.args, day, row, want)
+			}
+		}
+	}
+
+	failures := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--ref", "refs/missing"}, `Invalid --ref: cannot resolve ref "refs/missing"`},
+		{[]string{"--ref", "stash", "--branch", "master"}, "--ref and --branch cannot be used together"},
+	}
+	for _, tt := range failures {
+		stdout, stderr, code := runGitStat(t, r.dir, append(tt.args, ".", "2024-03-01", "2024-03-04")...)
+		if code == 0 || !strings.Contains(stderr, tt.want) {
+			t.Errorf("%v exited with %d: %s%s\nwant the error %q", tt.args, code, stdout, stderr, tt.want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// checkpointInterval is how often the progress of --checkpoint is saved.
+const checkpointInterval = 10 * time.Second
+
+// checkpoint is the progress of a run saved by --checkpoint: the commits
+// already counted and the daily totals they add up to.
+type checkpoint struct {
+	StartDate  string                 `json:"start_date"`
+	EndDate    string                 `json:"end_date"`
+	Branch     string                 `json:"branch"`
+	Commits    []string               `json:"commits"`
+	DailyStats map[string]*DailyStats `json:"daily_stats"`
+
+	filename string
+	done     map[string]bool
+	saved    time.Time
+}
+
+// newCheckpoint starts the checkpoint of a run over the date range. With
+// resume the progress saved in filename by an earlier run is picked up,
+// which must have covered the same range and branch; a missing file starts
+// from scratch.
+func newCheckpoint(filename string, resume bool, startDate, endDate time.Time, opts *Options) (*checkpoint, error) {
+	cp := &checkpoint{
+		StartDate:  startDate.Format("2006-01-02"),
+		EndDate:    endDate.Format("2006-01-02"),
+		Branch:     opts.Branch,
+		DailyStats: make(map[string]*DailyStats),
+		filename:   filename,
+		done:       make(map[string]bool),
+		saved:      time.Now(),
+	}
+	if !resume {
+		return cp, nil
+	}
+
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		debugf("no checkpoint at %s, starting from scratch", filename)
+		return cp, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var saved checkpoint
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	if saved.StartDate != cp.StartDate || saved.EndDate != cp.EndDate || saved.Branch != cp.Branch {
+		return nil, fmt.Errorf("%s was written for another date range or branch; remove it to start over", filename)
+	}
+
+	cp.Commits = saved.Commits
+	for _, hash := range saved.Commits {
+		cp.done[hash] = true
+	}
+	for date, stats := range saved.DailyStats {
+		if stats.FilesChanged == nil {
+			stats.FilesChanged = make(map[string]struct{})
+		}
+		if stats.Authors == nil {
+			stats.Authors = make(map[string]struct{})
+		}
+		cp.DailyStats[date] = stats
+	}
+	infof("Resuming from %s with %d commits already counted", filename, len(cp.Commits))
+	return cp, nil
+}
+
+// filter skips the commits counted before.
+func (cp *checkpoint) filter(c *object.Commit) bool {
+	return !cp.done[c.Hash.String()]
+}
+
+// add records that c has been counted, saving the progress every
+// checkpointInterval.
+func (cp *checkpoint) add(c *object.Commit) error {
+	cp.Commits = append(cp.Commits, c.Hash.String())
+	cp.done[c.Hash.String()] = true
+	if time.Since(cp.saved) < checkpointInterval {
+		return nil
+	}
+	return cp.save()
+}
+
+// save writes the progress to the checkpoint file. It is written next to it
+// first and then renamed, so an interrupted save keeps the previous one.
+func (cp *checkpoint) save() error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(cp.filename), filepath.Base(cp.filename)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), cp.filename); err != nil {
+		return err
+	}
+
+	cp.saved = time.Now()
+	debugf("saved %d commits to %s", len(cp.Commits), cp.filename)
+	return nil
+}
+
+// finish removes the checkpoint file once the run is complete.
+func (cp *checkpoint) finish() error {
+	if err := os.Remove(cp.filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// notifyInterrupt returns a channel that receives a value when the run is
+// interrupted with Ctrl-C, and a function undoing it.
+func notifyInterrupt() (<-chan os.Signal, func()) {
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt)
+	return interrupted, func() { signal.Stop(interrupted) }
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestNewCheckpoint(t *testing.T) {
+	defer func(level logLevel) { currentLogLevel = level }(currentLogLevel)
+	currentLogLevel = levelError
+
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)
+	saved := `{"start_date":"2024-03-01","end_date":"2024-03-03","branch":"","commits":["abc","def"],"daily_stats":{"2024-03-02":{"Additions":5}}}`
+
+	tests := []struct {
+		name    string
+		saved   string // the contents of the checkpoint file, "" for none
+		resume  bool
+		commits int
+		wantErr string
+	}{
+		{"new run", saved, false, 0, ""},
+		{"resume without a file", "", true, 0, ""},
+		{"resume", saved, true, 2, ""},
+		{"another range", strings.Replace(saved, "2024-03-03", "2024-03-04", 1), true, 0, "was written for another date range or branch"},
+		{"another branch", strings.Replace(saved, `"branch":""`, `"branch":"topic"`, 1), true, 0, "was written for another date range or branch"},
+		{"corrupt", "{", true, 0, "unexpected end of JSON input"},
+	}
+	for _, tt := range tests {
+		filename := filepath.Join(t.TempDir(), "checkpoint.json")
+		if tt.saved != "" {
+			if err := os.WriteFile(filename, []byte(tt.saved), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		cp, err := newCheckpoint(filename, tt.resume, start, end, &Options{})
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: error %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if len(cp.Commits) != tt.commits || len(cp.done) != tt.commits {
+			t.Errorf("%s: %d commits done, want %d", tt.name, len(cp.Commits), tt.commits)
+		}
+		if tt.commits > 0 {
+			if stats := cp.DailyStats["2024-03-02"]; stats == nil || stats.Additions != 5 || stats.FilesChanged == nil || stats.Authors == nil {
+				t.Errorf("%s: resumed with %+v", tt.name, stats)
+			}
+			if cp.filter(&object.Commit{}) != true {
+				t.Errorf("%s: a new commit is skipped", tt.name)
+			}
+		}
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	defer func(level logLevel) { currentLogLevel = level }(currentLogLevel)
+	currentLogLevel = levelError
+
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-02-28T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(2)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(4)}})
+	r.commit(testCommit{when: "2024-03-03T10:00:00Z", files: map[string]string{"a": lines(8)}})
+	filename := filepath.Join(t.TempDir(), "checkpoint.json")
+	want := mustRun(t, r.dir, ".", "2024-03-01", "2024-03-03")
+
+	// Interrupt the run once the first commit is reached, as Ctrl-C would.
+	opts, args, err := parseArgs([]string{"--checkpoint", filename, r.dir, "2024-03-01", "2024-03-03"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	self, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	interrupted := false
+	opts.addCommitFilter(func(c *object.Commit) bool {
+		if !interrupted {
+			if err := self.Signal(os.Interrupt); err != nil {
+				t.Skipf("cannot interrupt the test: %v", err)
+			}
+			interrupted = true
+			time.Sleep(100 * time.Millisecond)
+		}
+		return true
+	})
+	start, _ := parseDate(args[1])
+	end, _ := parseDate(args[2])
+	if _, err := getGitStats(r.repo, start, end, opts); err == nil || !strings.Contains(err.Error(), "run again with --resume") {
+		t.Fatalf("the interrupted run returned %v", err)
+	}
+	cp, err := newCheckpoint(filename, true, start, end, &Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cp.Commits) != 1 {
+		t.Fatalf("%d commits saved, want 1", len(cp.Commits))
+	}
+
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{[]string{"--checkpoint", filename, "--resume", ".", "2024-03-01", "2024-03-04"}, "was written for another date range or branch"},
+		{[]string{"--resume", ".", "2024-03-01", "2024-03-03"}, "--resume needs --checkpoint"},
+		{[]string{"--checkpoint", filename, "--batch-repos", ".", "2024-03-01", "2024-03-03"}, "--checkpoint cannot be used with --batch-repos"},
+		{[]string{"--checkpoint", filename, "--resume", ".", "2024-03-01", "2024-03-03"}, ""},
+	}
+	for _, tt := range tests {
+		stdout, stderr, code := runGitStat(t, r.dir, tt.args...)
+		if tt.wantErr != "" {
+			if code == 0 || !strings.Contains(stderr, tt.wantErr) {
+				t.Errorf("%v exited with %d: %s%s\nwant the error %q", tt.args, code, stdout, stderr, tt.wantErr)
+			}
+			continue
+		}
+		// The resumed run counts the saved commit once and removes the
+		// checkpoint when done.
+		if code != 0 || stdout != want || !strings.Contains(stderr, "Resuming from") {
+			t.Errorf("%v exited with %d:\n%s%s\nwant:\n%s", tt.args, code, stdout, stderr, want)
+		}
+		if _, err := os.Stat(filename); !os.IsNotExist(err) {
+			t.Errorf("the checkpoint is kept after the run finished: %v", err)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Churn modes accepted by --churn-mode. They decide how much a file changed
+// in a commit before the changes are added up.
+const (
+	churnSum = "sum" // additions plus deletions
+	churnMax = "max" // the larger of additions and deletions
+	churnNet = "net" // additions minus deletions
+)
+
+func validateChurnMode(mode string) error {
+	switch mode {
+	case churnSum, churnMax, churnNet:
+		return nil
+	}
+	return fmt.Errorf("unknown churn mode %q, expected %s, %s or %s", mode, churnSum, churnMax, churnNet)
+}
+
+// fileChanges returns how much the file of stat changed under the given
+// churn mode.
+func fileChanges(stat object.FileStat, mode string) int {
+	switch mode {
+	case churnMax:
+		return max(stat.Addition, stat.Deletion)
+	case churnNet:
+		return stat.Addition - stat.Deletion
+	}
+	return stat.Addition + stat.Deletion
+}
+
+// weightedColumn shows the additions and deletions weighted by --add-weight
+// and --del-weight, rounded to whole lines.
+func weightedColumn(addWeight, delWeight float64) tableColumn {
+	return tableColumn{"Weighted", weightedWidth, func(stats *DailyStats) string {
+		weighted := float64(stats.Additions)*addWeight + float64(stats.Deletions)*delWeight
+		return formatCount(int(math.Round(weighted)))
+	}}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestFileChanges(t *testing.T) {
+	tests := []struct {
+		additions, deletions int
+		mode                 string
+		want                 int
+	}{
+		{10, 4, churnSum, 14},
+		{10, 4, churnMax, 10},
+		{10, 4, churnNet, 6},
+		{4, 10, churnMax, 10},
+		{4, 10, churnNet, -6},
+		{0, 0, churnSum, 0},
+	}
+	for _, tt := range tests {
+		stat := object.FileStat{Name: "a", Addition: tt.additions, Deletion: tt.deletions}
+		if got := fileChanges(stat, tt.mode); got != tt.want {
+			t.Errorf("+%d -%d with %s: %d, want %d", tt.additions, tt.deletions, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestChurnMode(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": strings.ReplaceAll(lines(4), "line", "old")}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(10)}}) // +10 -4
+
+	tests := []struct {
+		mode    string
+		changes string
+	}{
+		{churnSum, "14"},
+		{churnMax, "10"},
+		{churnNet, "6"},
+	}
+	for _, tt := range tests {
+		row := tableRow(mustRun(t, r.dir, "--churn-mode", tt.mode, ".", "2024-03-02", "2024-03-02"), "2024-03-02")
+		if row == nil || row[2] != "10" || row[3] != "4" || row[4] != tt.changes {
+			t.Errorf("--churn-mode %s: %q, want +10 -4 and %s changes", tt.mode, row, tt.changes)
+		}
+	}
+
+	if stdout, stderr, code := runGitStat(t, r.dir, "--churn-mode", "min", ".", "2024-03-02", "2024-03-02"); code == 0 || !strings.Contains(stdout+stderr, `unknown churn mode "min"`) {
+		t.Errorf("--churn-mode min exited with %d: %s%s", code, stdout, stderr)
+	}
+}
+
+func TestWeightedColumn(t *testing.T) {
+	stats := &DailyStats{Additions: 10, Deletions: 3}
+
+	tests := []struct {
+		addWeight, delWeight float64
+		want                 string
+	}{
+		{1, 1, "13"},
+		{1, 2, "16"},
+		{0.5, 1.5, "10"}, // 9.5 rounds up
+		{0, 1, "3"},
+		{0.25, 0, "3"}, // 2.5 rounds away from zero
+	}
+	for _, tt := range tests {
+		if got := weightedColumn(tt.addWeight, tt.delWeight).value(stats); got != tt.want {
+			t.Errorf("--add-weight %g --del-weight %g: %s, want %s", tt.addWeight, tt.delWeight, got, tt.want)
+		}
+	}
+}
+
+func TestWeights(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(10)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(4), "b": lines(2)}})
+
+	tests := []struct {
+		args []string
+		want map[string]string // the Weighted column by row
+	}{
+		{[]string{"--del-weight", "2"}, map[string]string{"2024-03-01": "10", "2024-03-02": "14", "Total": "24"}},
+		{[]string{"--add-weight", "0.5", "--del-weight", "1.5"}, map[string]string{"2024-03-01": "5", "2024-03-02": "10", "Total": "15"}},
+	}
+	for _, tt := range tests {
+		out := mustRun(t, r.dir, append(tt.args, ".", "2024-03-01", "2024-03-02")...)
+		for label, want := range tt.want {
+			row := tableRow(out, label)
+			if row == nil || row[len(row)-1] != want {
+				t.Errorf("%v: %s %v, want %s weighted changes:\n%s", tt.args, label, row, want, out)
+			}
+		}
+	}
+
+	if out := mustRun(t, r.dir, "--add-weight", "1", "--del-weight", "1", ".", "2024-03-01", "2024-03-02"); strings.Contains(out, "Weighted") {
+		t.Errorf("the Weighted column is shown with the default weights:\n%s", out)
+	}
+	if stdout, stderr, code := runGitStat(t, r.dir, "--del-weight", "-1", ".", "2024-03-01", "2024-03-02"); code == 0 || !strings.Contains(stderr, "must not be negative") {
+		t.Errorf("--del-weight -1 exited with %d: %s%s", code, stdout, stderr)
+	}
+}
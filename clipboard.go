@@ -0,0 +1,29 @@
+package main
+
+import (
+	"errors"
+	"io"
+
+	"github.com/atotto/clipboard"
+)
+
+// writeClipboard copies text to the system clipboard.
+var writeClipboard = clipboard.WriteAll
+
+// copyToClipboard copies the output collected for --clipboard, without its
+// color codes, to the clipboard. Where there is none, such as on a server
+// without a display, it is written to fallback instead.
+func copyToClipboard(output string, fallback io.Writer) error {
+	err := errors.New("no clipboard utility found")
+	if !clipboard.Unsupported {
+		err = writeClipboard(stripANSI(output))
+	}
+	if err == nil {
+		infof("Copied the report to the clipboard")
+		return nil
+	}
+
+	warnf("cannot copy to the clipboard (%v), writing to stdout instead", err)
+	_, err = io.WriteString(fallback, output)
+	return err
+}
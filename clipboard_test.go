@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/atotto/clipboard"
+)
+
+func TestCopyToClipboard(t *testing.T) {
+	defer func(write func(string) error, unsupported bool) {
+		writeClipboard, clipboard.Unsupported = write, unsupported
+	}(writeClipboard, clipboard.Unsupported)
+	defer func(level logLevel) { currentLogLevel = level }(currentLogLevel)
+	currentLogLevel = levelError // no warnings about the fallbacks
+
+	output := colorOrange + "2024-03-01" + colorReset + " | 3\n"
+	tests := []struct {
+		name        string
+		unsupported bool
+		err         error
+		copied      string
+		fallback    string
+	}{
+		{"a clipboard", false, nil, "2024-03-01 | 3\n", ""},
+		{"a failing clipboard", false, errors.New("exit status 1"), "", output},
+		{"no clipboard", true, nil, "", output},
+	}
+	for _, tt := range tests {
+		copied := ""
+		clipboard.Unsupported = tt.unsupported
+		writeClipboard = func(text string) error {
+			if tt.err == nil {
+				copied = text
+			}
+			return tt.err
+		}
+
+		var fallback bytes.Buffer
+		if err := copyToClipboard(output, &fallback); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if copied != tt.copied || fallback.String() != tt.fallback {
+			t.Errorf("%s: copied %q and wrote %q, want %q and %q", tt.name, copied, fallback.String(), tt.copied, tt.fallback)
+		}
+	}
+}
+
+func TestClipboardFallback(t *testing.T) {
+	if !clipboard.Unsupported {
+		t.Skip("a clipboard is available")
+	}
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(3)}})
+
+	want := mustRun(t, r.dir, ".", "2024-03-01", "2024-03-02")
+	stdout, stderr, code := runGitStat(t, r.dir, "--clipboard", ".", "2024-03-01", "2024-03-02")
+	if code != 0 || stdout != want || !strings.Contains(stderr, "cannot copy to the clipboard") {
+		t.Errorf("--clipboard without a clipboard exited with %d, printing:\n%s\nwant:\n%s\nstderr: %s", code, stdout, want, stderr)
+	}
+}
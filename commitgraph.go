@@ -0,0 +1,70 @@
+package main
+
+import (
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	commitgraphfmt "github.com/go-git/go-git/v5/plumbing/format/commitgraph/v2"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/object/commitgraph"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// logCommits calls fn with every commit reachable from from whose committer
+// date lies between since and until, like repo.Log with Since and Until.
+//
+// Finding the commits in the range means visiting the whole history. When
+// the repository has a commit-graph file (written by `git commit-graph
+// write` or `git gc`), the walk reads the parents and dates from it, so only
+// the commits in the range are loaded from the object store.
+func logCommits(repo *git.Repository, from plumbing.Hash, since, until time.Time, fn func(c *object.Commit) error) error {
+	index := openCommitGraph(repo)
+	if index == nil {
+		commits, err := repo.Log(&git.LogOptions{
+			From:  from,
+			Since: &since,
+			Until: &until,
+		})
+		if err != nil {
+			return err
+		}
+		return commits.ForEach(fn)
+	}
+	defer index.Close()
+
+	debugf("using the commit-graph")
+
+	nodes := commitgraph.NewGraphCommitNodeIndex(index, repo.Storer)
+	head, err := nodes.Get(from)
+	if err != nil {
+		return err
+	}
+
+	return commitgraph.NewCommitNodeIterCTime(head, nil, nil).ForEach(func(node commitgraph.CommitNode) error {
+		if when := node.CommitTime(); when.Before(since) || when.After(until) {
+			return nil
+		}
+
+		c, err := node.Commit()
+		if err != nil {
+			return err
+		}
+		return fn(c)
+	})
+}
+
+// openCommitGraph returns the commit-graph index of the repository, or nil
+// when it has none or it cannot be read.
+func openCommitGraph(repo *git.Repository) commitgraphfmt.Index {
+	storage, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return nil
+	}
+
+	index, err := commitgraphfmt.OpenChainOrFileIndex(storage.Filesystem())
+	if err != nil {
+		return nil
+	}
+	return index
+}
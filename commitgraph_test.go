@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// writeCommitGraph writes the commit-graph file of the repository in dir.
+func writeCommitGraph(tb testing.TB, dir string) {
+	tb.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		tb.Skip("git is not on PATH")
+	}
+	if out, err := exec.Command("git", "-C", dir, "commit-graph", "write", "--reachable").CombinedOutput(); err != nil {
+		tb.Fatalf("git commit-graph write: %v: %s", err, out)
+	}
+}
+
+// loggedCommits returns the subjects of the commits logCommits visits, in
+// order, after opening the repository afresh.
+func loggedCommits(t *testing.T, dir string, since, until time.Time) []string {
+	t.Helper()
+
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var subjects []string
+	err = logCommits(repo, head.Hash(), since, until, func(c *object.Commit) error {
+		subjects = append(subjects, strings.TrimSpace(c.Message))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return subjects
+}
+
+func TestLogCommits(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", message: "one", files: map[string]string{"a": lines(1)}})
+	r.checkout("feature")
+	feature := r.commit(testCommit{when: "2024-03-03T10:00:00Z", message: "feature", files: map[string]string{"b": lines(1)}})
+	r.checkout("master")
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", message: "two", files: map[string]string{"a": lines(2)}})
+	r.commit(testCommit{when: "2024-03-04T10:00:00Z", message: "merge", parents: []plumbing.Hash{feature}})
+	r.commit(testCommit{when: "2024-03-06T10:00:00Z", message: "three", files: map[string]string{"a": lines(3)}})
+
+	day := func(s string) time.Time {
+		d, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	tests := []struct {
+		since, until string
+		want         string
+	}{
+		{"2024-03-01T00:00:00Z", "2024-03-06T23:59:59Z", "feature merge one three two"},
+		{"2024-03-02T00:00:00Z", "2024-03-04T23:59:59Z", "feature merge two"},
+		{"2024-03-04T10:00:00Z", "2024-03-04T10:00:00Z", "merge"},
+		{"2024-03-07T00:00:00Z", "2024-03-08T00:00:00Z", ""},
+	}
+
+	for _, graph := range []bool{false, true} {
+		if graph {
+			writeCommitGraph(t, r.dir)
+			repo, err := git.PlainOpen(r.dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			index := openCommitGraph(repo)
+			if index == nil {
+				t.Fatal("the commit-graph was written but not found")
+			}
+			index.Close()
+		} else if index := openCommitGraph(r.repo); index != nil {
+			t.Fatal("found a commit-graph before writing one")
+		}
+
+		for _, tt := range tests {
+			subjects := loggedCommits(t, r.dir, day(tt.since), day(tt.until))
+			sort.Strings(subjects)
+			if got := strings.Join(subjects, " "); got != tt.want {
+				t.Errorf("commit-graph %t, %s to %s: %q, want %q", graph, tt.since, tt.until, got, tt.want)
+			}
+		}
+	}
+}
+
+// BenchmarkLogCommits walks a long history for the commits of its last week,
+// with and without a commit-graph.
+func BenchmarkLogCommits(b *testing.B) {
+	r := newTestRepo(b)
+	start := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < 1000; i++ {
+		r.commit(testCommit{
+			when:  start.Add(time.Duration(i) * 6 * time.Hour).Format(time.RFC3339),
+			files: map[string]string{fmt.Sprintf("file%d", i%50): lines(i%7 + 1)},
+		})
+	}
+	until := start.Add(1000 * 6 * time.Hour)
+	since := until.AddDate(0, 0, -7)
+
+	walk := func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			repo, err := git.PlainOpen(r.dir)
+			if err != nil {
+				b.Fatal(err)
+			}
+			head, err := repo.Head()
+			if err != nil {
+				b.Fatal(err)
+			}
+			n := 0
+			err = logCommits(repo, head.Hash(), since, until, func(c *object.Commit) error {
+				n++
+				return nil
+			})
+			if err != nil || n != 28 {
+				b.Fatalf("walked %d commits: %v", n, err)
+			}
+		}
+	}
+
+	b.Run("without commit-graph", walk)
+	writeCommitGraph(b, r.dir)
+	b.Run("with commit-graph", walk)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+const (
+	hashWidth    = 9
+	dateWidth    = 12
+	authorWidth  = 20
+	subjectWidth = 50
+)
+
+// commitRow is a single commit of the --commits-table output.
+type commitRow struct {
+	Hash      string
+	FullHash  string
+	When      time.Time
+	Author    string
+	Additions int
+	Deletions int
+	Subject   string
+}
+
+// getCommitRows returns the commits in the date range, newest first and by
+// hash among those with the same author time.
+func getCommitRows(repo *git.Repository, startDate, endDate time.Time, opts *Options) ([]commitRow, error) {
+	var rows []commitRow
+	var authors []string
+
+	err := walkCommits(repo, startDate, endDate, opts, func(c *object.Commit, stats object.FileStats) error {
+		row := commitRow{
+			Hash:     c.Hash.String()[:7],
+			FullHash: c.Hash.String(),
+			When:     c.Author.When,
+			Author:   c.Author.Name,
+			Subject:  strings.TrimSpace(strings.SplitN(c.Message, "\n", 2)[0]),
+		}
+		for _, stat := range stats {
+			row.Additions += stat.Addition
+			row.Deletions += stat.Deletion
+		}
+		rows = append(rows, row)
+		authors = append(authors, authorKey(c.Author, opts))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Anonymize {
+		names := pseudonyms(authors)
+		for i := range rows {
+			rows[i].Author = names[authors[i]]
+		}
+	}
+
+	// Commits made at the same time, as by scripted imports, are ordered
+	// by hash so the output is the same on every run.
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].When.Equal(rows[j].When) {
+			return rows[i].When.After(rows[j].When)
+		}
+		return rows[i].FullHash < rows[j].FullHash
+	})
+
+	return rows, nil
+}
+
+// printCommitsTable prints one row per commit. Long author names and
+// subjects are cut to fit their column, which are narrowed, subject first,
+// when the table would be wider than maxTableWidth. With a non-empty
+// urlTemplate the hashes link to the URL it gives for each commit.
+func printCommitsTable(w io.Writer, rows []commitRow, urlTemplate string) {
+	widths := []int{hashWidth, dateWidth, authorWidth, additionsWidth, deletionsWidth, subjectWidth}
+	shrinkWidths(widths, 10, 5, 2)
+	authorWidth, subjectWidth := widths[2], widths[5]
+
+	printCells(w,
+		centerText("Commit", hashWidth),
+		centerText("Date", dateWidth),
+		centerText("Author", authorWidth),
+		centerText("Additions", additionsWidth),
+		centerText("Deletions", deletionsWidth),
+		centerText("Subject", subjectWidth))
+	printRule(w, widths...)
+
+	for _, row := range rows {
+		printCells(w,
+			commitLink(centerText(row.Hash, hashWidth), row, urlTemplate),
+			centerText(row.When.Format("2006-01-02"), dateWidth),
+			" "+padText(row.Author, authorWidth-1),
+			centerText(formatCount(row.Additions), additionsWidth),
+			centerText(formatCount(row.Deletions), deletionsWidth),
+			" "+padText(row.Subject, subjectWidth-1))
+		printRule(w, widths...)
+	}
+}
+
+// commitLink turns the hash of row within cell into an OSC 8 terminal
+// hyperlink to urlTemplate, with {hash} replaced by the full commit hash.
+// Terminals without support for them show the plain hash.
+func commitLink(cell string, row commitRow, urlTemplate string) string {
+	if urlTemplate == "" {
+		return cell
+	}
+	url := strings.ReplaceAll(urlTemplate, "{hash}", row.FullHash)
+	link := "\033]8;;" + url + "\033\\" + row.Hash + "\033]8;;\033\\"
+	return strings.Replace(cell, row.Hash, link, 1)
+}
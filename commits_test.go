@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestCommitsTable(t *testing.T) {
+	long := "feat: " + strings.Repeat("very ", 20) + "long subject"
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-02-28T10:00:00Z", message: "before the range", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", message: "add lines\n\nwith a body", files: map[string]string{"a": lines(4)}})
+	r.commit(testCommit{when: "2024-03-03T10:00:00Z", message: long, author: "Bob <bob@example.com>", files: map[string]string{"a": lines(2), "b": lines(1)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", message: "empty", author: "Carol <carol@example.com>"})
+	r.commit(testCommit{when: "2024-03-02T11:00:00Z", message: "fix: typo", files: map[string]string{"b": "line one\n"}})
+
+	out := mustRun(t, r.dir, "--commits-table", "--include-empty-commits", ".", "2024-03-01", "2024-03-03")
+
+	var rows [][]string
+	for _, line := range strings.Split(out, "\n") {
+		cells := strings.Split(line, "|")
+		if len(cells) != 6 || strings.TrimSpace(cells[0]) == "Commit" {
+			continue
+		}
+		for i := range cells {
+			cells[i] = strings.TrimSpace(cells[i])
+		}
+		rows = append(rows, cells[1:])
+	}
+
+	want := [][]string{
+		{"2024-03-03", "Bob", "1", "2", long[:subjectWidth-1]},
+		{"2024-03-02", "Alice", "1", "1", "fix: typo"},
+		{"2024-03-02", "Carol", "0", "0", "empty"},
+		{"2024-03-01", "Alice", "3", "0", "add lines"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("%d rows, want one per commit, %d:\n%s", len(rows), len(want), out)
+	}
+	for i, row := range rows {
+		if strings.Join(row, "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d is %q, want %q", i+1, row, want[i])
+		}
+	}
+}
+
+func TestCommitLink(t *testing.T) {
+	row := commitRow{Hash: "1234567", FullHash: "1234567890abcdef1234567890abcdef12345678"}
+	cell := centerText(row.Hash, hashWidth)
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"", " 1234567 "},
+		{
+			"https://github.com/org/repo/commit/{hash}",
+			" \033]8;;https://github.com/org/repo/commit/1234567890abcdef1234567890abcdef12345678\033\\1234567\033]8;;\033\\ ",
+		},
+		{
+			"https://example.com/{hash}?short=0#{hash}",
+			" \033]8;;https://example.com/1234567890abcdef1234567890abcdef12345678?short=0#1234567890abcdef1234567890abcdef12345678\033\\1234567\033]8;;\033\\ ",
+		},
+	}
+	for _, tt := range tests {
+		if got := commitLink(cell, row, tt.template); got != tt.want {
+			t.Errorf("commitLink with %q = %q, want %q", tt.template, got, tt.want)
+		}
+		if got := stripOSC8(commitLink(cell, row, tt.template)); got != cell {
+			t.Errorf("commitLink with %q shows %q, want the padded hash %q", tt.template, got, cell)
+		}
+	}
+}
+
+// stripOSC8 removes OSC 8 hyperlinks, leaving their text.
+func stripOSC8(s string) string {
+	for {
+		start := strings.Index(s, "\033]8;;")
+		if start < 0 {
+			return s
+		}
+		end := strings.Index(s[start:], "\033\\")
+		if end < 0 {
+			return s
+		}
+		s = s[:start] + s[start+end+2:]
+	}
+}
+
+func TestCommitURLPiped(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(1)}})
+
+	out := mustRun(t, r.dir, "--commits-table", "--commit-url", "https://example.com/{hash}", ".", "2024-03-01", "2024-03-01")
+	if strings.Contains(out, "\033]8;;") {
+		t.Errorf("links written to a pipe:\n%q", out)
+	}
+}
+
+func TestSameTimestampOrder(t *testing.T) {
+	r := newTestRepo(t)
+	var imported []string
+	for i := 0; i < 6; i++ {
+		hash := r.commit(testCommit{when: "2024-03-01T10:00:00Z", message: fmt.Sprintf("import %d", i), files: map[string]string{fmt.Sprintf("f%d", i): lines(i + 1)}})
+		imported = append(imported, hash.String())
+	}
+	later := r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"f0": lines(2)}})
+	sort.Strings(imported)
+	want := append([]string{later.String()}, imported...)
+
+	var hashes []string
+	for _, commit := range decodeCommitsJSON(t, mustRun(t, r.dir, "--format", "commits-json", ".", "2024-03-01", "2024-03-02")) {
+		hashes = append(hashes, commit.Hash)
+	}
+	if !slices.Equal(hashes, want) {
+		t.Errorf("commits-json wrote %q, want %q", hashes, want)
+	}
+
+	hashes = nil
+	for _, line := range strings.Split(mustRun(t, r.dir, "--commits-table", ".", "2024-03-01", "2024-03-02"), "\n") {
+		if cells := strings.Split(line, "|"); len(cells) == 6 && strings.TrimSpace(cells[0]) != "Commit" {
+			hashes = append(hashes, strings.TrimSpace(cells[0]))
+		}
+	}
+	var short []string
+	for _, hash := range want {
+		short = append(short, hash[:7])
+	}
+	if !slices.Equal(hashes, short) {
+		t.Errorf("--commits-table lists %q, want %q", hashes, short)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// formatCommitsJSON writes one JSON object per commit instead of the daily
+// report. It needs the commits themselves, so it is not one of renderers.
+const formatCommitsJSON = "commits-json"
+
+type jsonCommitFile struct {
+	Path      string `json:"path"`
+	OldPath   string `json:"old_path,omitempty"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
+
+type jsonCommit struct {
+	Hash      string           `json:"hash"`
+	Author    string           `json:"author"`
+	Email     string           `json:"email,omitempty"`
+	Timestamp string           `json:"timestamp"`
+	Additions int              `json:"additions"`
+	Deletions int              `json:"deletions"`
+	Files     []jsonCommitFile `json:"files"`
+}
+
+// writeCommitsJSON writes every commit in the date range as a line of JSON
+// as soon as it is walked, newest first, so large histories can be piped
+// into other tools without being held in memory. Consecutive commits with
+// the same author time, as made by scripted imports, are held back until
+// the time changes and written in the order of their hashes, so the output
+// does not depend on the order they happened to be walked in. With
+// --anonymize the commits are held until the walk ends, as the pseudonyms
+// are only known to be unique once all authors are, and written with their
+// pseudonym and without an email.
+func writeCommitsJSON(w io.Writer, repo *git.Repository, startDate, endDate time.Time, opts *Options) error {
+	enc := json.NewEncoder(w)
+
+	var pending []jsonCommit
+	flush := func() error {
+		sort.Slice(pending, func(i, j int) bool { return pending[i].Hash < pending[j].Hash })
+		for _, commit := range pending {
+			if err := enc.Encode(commit); err != nil {
+				return err
+			}
+		}
+		pending = pending[:0]
+		return nil
+	}
+	emit := func(commit jsonCommit) error {
+		if len(pending) > 0 && pending[0].Timestamp != commit.Timestamp {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		pending = append(pending, commit)
+		return nil
+	}
+
+	var held []jsonCommit
+	var authors []string
+
+	err := walkCommits(repo, startDate, endDate, opts, func(c *object.Commit, stats object.FileStats) error {
+		commit := jsonCommit{
+			Hash:      c.Hash.String(),
+			Author:    c.Author.Name,
+			Email:     c.Author.Email,
+			Timestamp: c.Author.When.Format(time.RFC3339),
+			Files:     []jsonCommitFile{},
+		}
+
+		for _, stat := range stats {
+			file := jsonCommitFile{Additions: stat.Addition, Deletions: stat.Deletion}
+			from, to := splitRename(stat.Name)
+			file.Path = to
+			if from != to {
+				file.OldPath = from
+			}
+
+			commit.Files = append(commit.Files, file)
+			commit.Additions += stat.Addition
+			commit.Deletions += stat.Deletion
+		}
+
+		if opts.Anonymize {
+			held = append(held, commit)
+			authors = append(authors, authorKey(c.Author, opts))
+			return nil
+		}
+		return emit(commit)
+	})
+	if err != nil {
+		return err
+	}
+
+	names := pseudonyms(authors)
+	for i, commit := range held {
+		commit.Author = names[authors[i]]
+		commit.Email = ""
+		if err := emit(commit); err != nil {
+			return err
+		}
+	}
+	return flush()
+}
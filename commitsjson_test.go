@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// decodeCommitsJSON decodes the lines written by --format commits-json.
+func decodeCommitsJSON(t *testing.T, out string) []jsonCommit {
+	t.Helper()
+
+	var commits []jsonCommit
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		var commit jsonCommit
+		if err := json.Unmarshal([]byte(line), &commit); err != nil {
+			t.Fatalf("%q: %v", line, err)
+		}
+		commits = append(commits, commit)
+	}
+	return commits
+}
+
+func TestCommitsJSON(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-02-29T10:00:00Z", files: map[string]string{"old.txt": lines(10)}})
+	add := r.commit(testCommit{when: "2024-03-01T10:00:00+02:00", files: map[string]string{"a.go": lines(3), "b.go": lines(1)}})
+	move := r.commit(testCommit{when: "2024-03-02T10:00:00Z", author: "Bob <bob@example.com>", files: map[string]string{"old.txt": "", "new.txt": lines(11)}})
+	edit := r.commit(testCommit{when: "2024-03-02T11:00:00Z", files: map[string]string{"a.go": lines(1)}})
+
+	out := mustRun(t, r.dir, "--format", "commits-json", ".", "2024-03-01", "2024-03-02")
+	commits := decodeCommitsJSON(t, out)
+
+	want := []jsonCommit{
+		{Hash: edit.String(), Author: "Alice", Email: "alice@example.com", Timestamp: "2024-03-02T11:00:00Z", Deletions: 2,
+			Files: []jsonCommitFile{{Path: "a.go", Deletions: 2}}},
+		{Hash: move.String(), Author: "Bob", Email: "bob@example.com", Timestamp: "2024-03-02T10:00:00Z", Additions: 1,
+			Files: []jsonCommitFile{{Path: "new.txt", OldPath: "old.txt", Additions: 1}}},
+		{Hash: add.String(), Author: "Alice", Email: "alice@example.com", Timestamp: "2024-03-01T10:00:00+02:00", Additions: 4,
+			Files: []jsonCommitFile{{Path: "a.go", Additions: 3}, {Path: "b.go", Additions: 1}}},
+	}
+	if len(commits) != len(want) {
+		t.Fatalf("%d commits, want %d:\n%s", len(commits), len(want), out)
+	}
+	for i, commit := range commits {
+		got, _ := json.Marshal(commit)
+		wantJSON, _ := json.Marshal(want[i])
+		if string(got) != string(wantJSON) {
+			t.Errorf("commit %d is %s, want %s", i+1, got, wantJSON)
+		}
+	}
+
+	// As many commits as the daily table counts.
+	row := tableRow(mustRun(t, r.dir, "--per-commit", ".", "2024-03-01", "2024-03-02"), "Total")
+	if n, _ := strconv.Atoi(row[5]); n != len(commits) {
+		t.Errorf("the table counts %s commits, commits-json %d", row[5], len(commits))
+	}
+
+	anonymized := decodeCommitsJSON(t, mustRun(t, r.dir, "--format", "commits-json", "--anonymize", ".", "2024-03-01", "2024-03-02"))
+	authors := make(map[string]bool)
+	for _, commit := range anonymized {
+		if commit.Email != "" || commit.Author == "Alice" || commit.Author == "Bob" {
+			t.Errorf("--anonymize wrote %s <%s>", commit.Author, commit.Email)
+		}
+		authors[commit.Author] = true
+	}
+	if len(anonymized) != len(commits) || len(authors) != 2 || anonymized[0].Author != anonymized[2].Author {
+		t.Errorf("--anonymize wrote %d commits by %v, want %d by two pseudonyms", len(anonymized), authors, len(commits))
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+const otherCommitType = "other"
+
+// defaultCommitTypes are the Conventional Commits types recognized by
+// --by-type unless --types is given.
+var defaultCommitTypes = []string{
+	"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert",
+}
+
+// commitTypeOf returns the Conventional Commits type of a commit message,
+// such as `feat` for "feat(parser)!: add arrays", or "other" when the
+// message has no recognized type prefix.
+func commitTypeOf(message string, types []string) string {
+	subject, _, _ := strings.Cut(message, "\n")
+
+	prefix, _, ok := strings.Cut(subject, ":")
+	if !ok {
+		return otherCommitType
+	}
+
+	prefix = strings.TrimSuffix(strings.TrimSpace(prefix), "!")
+	if i := strings.Index(prefix, "("); i >= 0 && strings.HasSuffix(prefix, ")") {
+		prefix = prefix[:i]
+	}
+	prefix = strings.ToLower(prefix)
+
+	for _, t := range types {
+		if prefix == t {
+			return t
+		}
+	}
+	return otherCommitType
+}
+
+func getCommitTypeStats(repo *git.Repository, startDate, endDate time.Time, opts *Options) (map[string]*DailyStats, error) {
+	return getGroupStats(repo, startDate, endDate, opts, func(c *object.Commit, stat object.FileStat) string {
+		return commitTypeOf(c.Message, opts.CommitTypes)
+	})
+}
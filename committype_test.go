@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCommitTypeOf(t *testing.T) {
+	tests := []struct {
+		message string
+		types   []string
+		want    string
+	}{
+		{"feat: add arrays", defaultCommitTypes, "feat"},
+		{"fix(parser): handle EOF", defaultCommitTypes, "fix"},
+		{"feat(api)!: drop v1\n\nBREAKING CHANGE: gone", defaultCommitTypes, "feat"},
+		{"Fix: capitalized", defaultCommitTypes, "fix"},
+		{"chore : spaced", defaultCommitTypes, "chore"},
+		{"Update README", defaultCommitTypes, otherCommitType},
+		{"wip: halfway", defaultCommitTypes, otherCommitType},
+		{"subject\n\nfeat: in the body", defaultCommitTypes, otherCommitType},
+		{"wip: halfway", []string{"wip"}, "wip"},
+		{"feat: add arrays", []string{"wip"}, otherCommitType},
+	}
+	for _, tt := range tests {
+		if got := commitTypeOf(tt.message, tt.types); got != tt.want {
+			t.Errorf("commitTypeOf(%q, %v) = %q, want %q", tt.message, tt.types, got, tt.want)
+		}
+	}
+}
+
+func TestByType(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", message: "feat: add a", files: map[string]string{"a": lines(3)}})
+	r.commit(testCommit{when: "2024-03-01T11:00:00Z", message: "feat(b): add b", files: map[string]string{"b": lines(2)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", message: "fix: shorten a", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-02T11:00:00Z", message: "wip: more b", files: map[string]string{"b": lines(4)}})
+
+	tests := []struct {
+		types string
+		rows  map[string][]string // additions, deletions and commits by type
+	}{
+		{"", map[string][]string{
+			"feat":  {"5", "0", "2"},
+			"fix":   {"0", "2", "1"},
+			"other": {"2", "0", "1"},
+		}},
+		{"wip,fix", map[string][]string{
+			"wip":   {"2", "0", "1"},
+			"fix":   {"0", "2", "1"},
+			"other": {"5", "0", "2"},
+		}},
+	}
+	for _, tt := range tests {
+		args := []string{"--by-type", "--per-commit", ".", "2024-03-01", "2024-03-02"}
+		if tt.types != "" {
+			args = append([]string{"--types", tt.types}, args...)
+		}
+		out := mustRun(t, r.dir, args...)
+		for label, want := range tt.rows {
+			row := tableRow(out, label)
+			if row == nil {
+				t.Errorf("--types %q: no row for %s:\n%s", tt.types, label, out)
+				continue
+			}
+			if got := []string{row[2], row[3], row[5]}; strings.Join(got, " ") != strings.Join(want, " ") {
+				t.Errorf("--types %q: %s has %v, want %v", tt.types, label, got, want)
+			}
+		}
+	}
+}
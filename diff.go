@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// diffColumns are the columns of the --diff table, the stats saved in a
+// JSON report.
+var diffColumns = []struct {
+	title string
+	width int
+	value func(day jsonDay) int
+}{
+	{"Files Changed", filesChangedWidth, func(day jsonDay) int { return day.FilesChanged }},
+	{"Additions", additionsWidth, func(day jsonDay) int { return day.Additions }},
+	{"Deletions", deletionsWidth, func(day jsonDay) int { return day.Deletions }},
+	{"Total Changes", totalChangesWidth, func(day jsonDay) int { return day.TotalChanges }},
+	{"Commits", commitsWidth, func(day jsonDay) int { return day.Commits }},
+}
+
+// loadJSONReport reads a report saved with --format json.
+func loadJSONReport(filename string) (*jsonReport, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var report jsonReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return &report, nil
+}
+
+// printReportDiff prints how the stats of every day changed compared to the
+// baseline report. Days found in only one of the reports are compared with
+// zero and marked as new or gone.
+func printReportDiff(w io.Writer, report *Report, baseline *jsonReport) {
+	current := newJSONReport(report)
+
+	before := make(map[string]jsonDay, len(baseline.Days))
+	after := make(map[string]jsonDay, len(current.Days))
+	for _, day := range baseline.Days {
+		before[day.Date] = day
+	}
+	for _, day := range current.Days {
+		after[day.Date] = day
+	}
+
+	dates := make([]string, 0, len(before)+len(after))
+	for date := range before {
+		dates = append(dates, date)
+	}
+	for date := range after {
+		if _, ok := before[date]; !ok {
+			dates = append(dates, date)
+		}
+	}
+	sort.Strings(dates)
+
+	widths := []int{labelWidth}
+	cells := []string{centerText("Date", labelWidth)}
+	for _, col := range diffColumns {
+		widths = append(widths, col.width)
+		cells = append(cells, centerText(col.title, col.width))
+	}
+
+	fmt.Fprintf(w, "Compared with %s ~ %s\n", baseline.StartDate, baseline.EndDate)
+	printCells(w, cells...)
+	printRule(w, widths...)
+
+	for _, date := range dates {
+		old, inBefore := before[date]
+		cur, inAfter := after[date]
+
+		note := ""
+		if !inBefore {
+			note = " (new)"
+		} else if !inAfter {
+			note = " (gone)"
+		}
+		printDiffRow(w, date, old, cur, note, widths)
+	}
+
+	printDiffRow(w, "Total", totalAsDay(baseline.Total), totalAsDay(current.Total), "", widths)
+}
+
+func printDiffRow(w io.Writer, label string, old, cur jsonDay, note string, widths []int) {
+	cells := []string{padText(label, labelWidth)}
+	for _, col := range diffColumns {
+		delta := col.value(cur) - col.value(old)
+		cells = append(cells, colorArrow(centerText(formatArrowDelta(delta), col.width), delta))
+	}
+	cells[len(cells)-1] += note
+	printCells(w, cells...)
+	printRule(w, widths...)
+}
+
+func totalAsDay(total jsonTotal) jsonDay {
+	return jsonDay{
+		FilesChanged: total.FilesChanged,
+		Additions:    total.Additions,
+		Deletions:    total.Deletions,
+		TotalChanges: total.TotalChanges,
+		Commits:      total.Commits,
+	}
+}
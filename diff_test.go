@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReportDiff(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(3)}})
+
+	dir := t.TempDir()
+	baseline := filepath.Join(dir, "prev.json")
+	mustRun(t, r.dir, "--format", "json", "--output", baseline, ".", "2024-03-01", "2024-03-03")
+
+	// Since the baseline, the commit of the first day was amended to change
+	// more and a commit was made on the second.
+	r = newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(5), "b": lines(1)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(2)}})
+
+	// A day found only in the baseline, written by hand.
+	edited := filepath.Join(dir, "edited.json")
+	data, err := os.ReadFile(baseline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := `{"date": "2024-03-03", "files_changed": 1, "additions": 4, "deletions": 1, "total_changes": 5, "commits": 2}`
+	data = []byte(strings.Replace(string(data), `"days": [`, `"days": [`+day+",", 1))
+	if err := os.WriteFile(edited, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		baseline string
+		rows     map[string][]string // files changed, additions, deletions, total changes and commits
+	}{
+		{baseline, map[string][]string{
+			"2024-03-01": {"+1", "+3", "0", "+3", "0"},
+			"2024-03-02": {"+1", "0", "+3", "+3", "+1 (new)"},
+			"Total":      {"+1", "+3", "+3", "+6", "+1"},
+		}},
+		{edited, map[string][]string{
+			"2024-03-03": {"-1", "-4", "-1", "-5", "-2 (gone)"},
+		}},
+	}
+	for _, tt := range tests {
+		out := mustRun(t, r.dir, "--diff", tt.baseline, ".", "2024-03-01", "2024-03-03")
+		if first, _, _ := strings.Cut(out, "\n"); first != "Compared with 2024-03-01 ~ 2024-03-03" {
+			t.Errorf("%s: starts with %q", filepath.Base(tt.baseline), first)
+		}
+		for label, want := range tt.rows {
+			row := tableRow(out, label)
+			for i := range row {
+				row[i] = strings.Join(strings.Fields(row[i]), " ")
+			}
+			if row == nil || strings.Join(row[1:], " | ") != strings.Join(want, " | ") {
+				t.Errorf("%s: %s is %q, want %q:\n%s", filepath.Base(tt.baseline), label, row, want, out)
+			}
+		}
+	}
+
+	if err := os.WriteFile(edited, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadJSONReport(edited); err == nil || !strings.Contains(err.Error(), edited) {
+		t.Errorf("loading a broken report: %v, want an error naming the file", err)
+	}
+}
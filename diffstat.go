@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// commitFileStats returns the same per-file statistics as c.Stats(), which
+// detects renames with a threshold of 60. Instead of building the patch of
+// the whole commit first, which keeps the contents of every changed file in
+// memory at once, each file is diffed on its own and only the number of
+// changed lines is kept.
+//
+// A file deleted and one added in the same commit count as a rename when
+// their contents are at least renameThreshold percent similar; 100 only
+// detects files moved without changes. With normalizeEOL, CRLF line endings
+// are compared as LF, so converting a file between them changes nothing.
+//
+// Once ctx is done, the error of the context is returned, between two files
+// at the latest.
+func commitFileStats(ctx context.Context, c *object.Commit, renameThreshold int, normalizeEOL bool) (object.FileStats, error) {
+	changes, err := commitChangesContext(ctx, c, renameThreshold)
+	if err != nil {
+		return nil, err
+	}
+
+	var fileStats object.FileStats
+	for _, change := range changes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		stat, ok, err := changeFileStat(change, normalizeEOL)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			fileStats = append(fileStats, stat)
+		}
+	}
+
+	return fileStats, nil
+}
+
+// commitChanges returns the changes of c against its first parent, or
+// against an empty tree for a root commit.
+func commitChanges(c *object.Commit, renameThreshold int) (object.Changes, error) {
+	return commitChangesContext(context.Background(), c, renameThreshold)
+}
+
+// unchangedTree reports whether c changes no files at all: its tree is that
+// of its first parent, or empty for a root commit. Commits only changing
+// binary files or file modes have no line stats, but do change the tree.
+func unchangedTree(c *object.Commit) (bool, error) {
+	if c.NumParents() == 0 {
+		tree, err := c.Tree()
+		if err != nil {
+			return false, err
+		}
+		return len(tree.Entries) == 0, nil
+	}
+
+	parent, err := c.Parent(0)
+	if err != nil {
+		return false, err
+	}
+	return parent.TreeHash == c.TreeHash, nil
+}
+
+// commitChangesContext is commitChanges giving up once ctx is done.
+func commitChangesContext(ctx context.Context, c *object.Commit, renameThreshold int) (object.Changes, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	parentTree := &object.Tree{}
+	if c.NumParents() != 0 {
+		parent, err := c.Parents().Next()
+		if err != nil {
+			return nil, err
+		}
+
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	diffOpts := *object.DefaultDiffTreeOptions
+	diffOpts.RenameScore = uint(renameThreshold)
+	diffOpts.OnlyExactRenames = renameThreshold >= 100
+
+	changes, err := object.DiffTreeWithOptions(ctx, parentTree, tree, &diffOpts)
+	if errors.Is(err, object.ErrCanceled) && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// changeFileStat counts the lines added and deleted by a single change. Like
+// c.Stats(), binary files and changes without a diff (submodule updates) are
+// reported as not ok, as are files whose only change is their line endings
+// when normalizeEOL is set.
+func changeFileStat(change *object.Change, normalizeEOL bool) (object.FileStat, bool, error) {
+	from, to, err := change.Files()
+	if err != nil {
+		return object.FileStat{}, false, err
+	}
+
+	fromContent, fromBinary, err := fileContent(from)
+	if err != nil {
+		return object.FileStat{}, false, err
+	}
+
+	toContent, toBinary, err := fileContent(to)
+	if err != nil {
+		return object.FileStat{}, false, err
+	}
+
+	if fromBinary || toBinary {
+		return object.FileStat{}, false, nil
+	}
+
+	if normalizeEOL {
+		fromContent = strings.ReplaceAll(fromContent, "\r\n", "\n")
+		toContent = strings.ReplaceAll(toContent, "\r\n", "\n")
+		if from != nil && to != nil && fromContent == toContent {
+			return object.FileStat{}, false, nil
+		}
+	}
+
+	// This is the line diff of diff.Do, minus turning the result back into
+	// text: every rune stands for one line, so counting runes counts lines.
+	dmp := diffmatchpatch.New()
+	dmp.DiffTimeout = time.Hour
+	fromLines, toLines, _ := dmp.DiffLinesToRunes(fromContent, toContent)
+	diffs := dmp.DiffMainRunes(fromLines, toLines, false)
+	if len(diffs) == 0 {
+		return object.FileStat{}, false, nil
+	}
+
+	stat := object.FileStat{Name: fileStatName(change, from, to)}
+	for _, d := range diffs {
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			stat.Addition += utf8.RuneCountInString(d.Text)
+		case diffmatchpatch.DiffDelete:
+			stat.Deletion += utf8.RuneCountInString(d.Text)
+		}
+	}
+
+	return stat, true, nil
+}
+
+// binarySniffLen is how many leading bytes git (and go-git) look at for a
+// NUL byte to decide that a file is binary.
+const binarySniffLen = 8000
+
+// fileContent reads the contents of f once and tells whether it is binary,
+// where f.IsBinary() followed by f.Contents() would read the blob twice.
+func fileContent(f *object.File) (content string, isBinary bool, err error) {
+	if f == nil {
+		return "", false, nil
+	}
+
+	content, err = f.Contents()
+	if err != nil {
+		return "", false, err
+	}
+
+	if strings.IndexByte(content[:min(len(content), binarySniffLen)], 0) >= 0 {
+		return "", true, nil
+	}
+	return content, false, nil
+}
+
+// fileStatName names a change the way c.Stats() does, using "old => new" for
+// renames. The names of the files themselves lack the directory, so the
+// paths are taken from the change entries.
+func fileStatName(change *object.Change, from, to *object.File) string {
+	switch {
+	case from == nil:
+		return change.To.Name
+	case to == nil:
+		return change.From.Name
+	case change.From.Name != change.To.Name:
+		return fmt.Sprintf("%s => %s", change.From.Name, change.To.Name)
+	}
+	return change.From.Name
+}
+
+// commitByteChanges returns how many bytes each file changed by in c, the
+// difference between its blob sizes before and after, keyed by the same
+// names as commitFileStats. Only the size of the blobs is needed, not their
+// contents, but it still costs a tree diff per commit.
+func commitByteChanges(c *object.Commit, renameThreshold int) (map[string]int, error) {
+	changes, err := commitChanges(c, renameThreshold)
+	if err != nil {
+		return nil, err
+	}
+
+	byteChanges := make(map[string]int, len(changes))
+	for _, change := range changes {
+		from, to, err := change.Files()
+		if err != nil {
+			return nil, err
+		}
+
+		var delta int64
+		if to != nil {
+			delta += to.Size
+		}
+		if from != nil {
+			delta -= from.Size
+		}
+		if delta < 0 {
+			delta = -delta
+		}
+		byteChanges[fileStatName(change, from, to)] = int(delta)
+	}
+
+	return byteChanges, nil
+}
+
+// commitNetFiles returns +1 for every file c adds and -1 for every file it
+// deletes, keyed by the same names as commitFileStats. Modified and renamed
+// files are left out.
+func commitNetFiles(c *object.Commit, renameThreshold int) (map[string]int, error) {
+	changes, err := commitChanges(c, renameThreshold)
+	if err != nil {
+		return nil, err
+	}
+
+	netFiles := make(map[string]int)
+	for _, change := range changes {
+		switch {
+		case change.From.Name == "":
+			netFiles[change.To.Name] = 1
+		case change.To.Name == "":
+			netFiles[change.From.Name] = -1
+		}
+	}
+
+	return netFiles, nil
+}
+
+// statsContext returns the context computing the changes of a commit is
+// given with --stats-timeout, which has no deadline when timeout is zero.
+func statsContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), timeout)
+}
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// diffStatRepo returns a repository whose commits cover every kind of change
+// commitFileStats tells apart, with the commits by name.
+func diffStatRepo(t testing.TB) (*testRepo, map[string]plumbing.Hash) {
+	r := newTestRepo(t)
+	commits := make(map[string]plumbing.Hash)
+	for _, c := range []struct {
+		name  string
+		files map[string]string
+	}{
+		{"root", map[string]string{"a.txt": lines(10), "dir/b.txt": lines(3), "image.png": "PNG\x00\x01"}},
+		{"modify", map[string]string{"a.txt": strings.Replace(lines(12), "line 5\n", "five\n", 1)}},
+		{"delete", map[string]string{"dir/b.txt": ""}},
+		{"rename", map[string]string{"a.txt": "", "c.txt": strings.Replace(lines(12), "line 5\n", "five\n", 1) + "more\n"}},
+		{"binary", map[string]string{"image.png": "PNG\x00\x02"}},
+		{"crlf", map[string]string{"c.txt": strings.ReplaceAll(strings.Replace(lines(12), "line 5\n", "five\n", 1)+"more\n", "\n", "\r\n")}},
+	} {
+		commits[c.name] = r.commit(testCommit{when: "2024-03-01T10:00:00Z", message: c.name, files: c.files})
+	}
+	return r, commits
+}
+
+func sortedStats(stats object.FileStats) string {
+	var names []string
+	for _, stat := range stats {
+		names = append(names, fmt.Sprintf("%s +%d -%d", stat.Name, stat.Addition, stat.Deletion))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func TestCommitFileStats(t *testing.T) {
+	r, commits := diffStatRepo(t)
+
+	tests := []struct {
+		commit          string
+		renameThreshold int
+		normalizeEOL    bool
+		want            string
+	}{
+		{"root", 60, false, "a.txt +10 -0, dir/b.txt +3 -0"},
+		{"modify", 60, false, "a.txt +3 -1"},
+		{"delete", 60, false, "dir/b.txt +0 -3"},
+		{"rename", 60, false, "a.txt => c.txt +1 -0"},
+		{"rename", 100, false, "a.txt +0 -12, c.txt +13 -0"},
+		{"binary", 60, false, ""},
+		{"crlf", 60, false, "c.txt +13 -13"},
+		{"crlf", 60, true, ""},
+	}
+	for _, tt := range tests {
+		c, err := r.repo.CommitObject(commits[tt.commit])
+		if err != nil {
+			t.Fatal(err)
+		}
+		stats, err := commitFileStats(context.Background(), c, tt.renameThreshold, tt.normalizeEOL)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.commit, err)
+		}
+		if got := sortedStats(stats); got != tt.want {
+			t.Errorf("%s with a rename threshold of %d and normalizeEOL %t: %q, want %q",
+				tt.commit, tt.renameThreshold, tt.normalizeEOL, got, tt.want)
+		}
+
+		// The default settings are those of c.Stats(), which must agree.
+		if tt.renameThreshold == 60 && !tt.normalizeEOL {
+			want, err := c.Stats()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := sortedStats(stats); got != sortedStats(want) {
+				t.Errorf("%s: %q, but c.Stats() gives %q", tt.commit, got, sortedStats(want))
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c, err := r.repo.CommitObject(commits["root"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := commitFileStats(ctx, c, 60, false); err != context.Canceled {
+		t.Errorf("with a canceled context: %v, want %v", err, context.Canceled)
+	}
+}
+
+// benchmarkCommit is a commit changing many lines of many files, so the
+// difference in allocations between the two ways of counting them shows.
+func benchmarkCommit(b *testing.B) *object.Commit {
+	r := newTestRepo(b)
+	files := make(map[string]string)
+	for i := 0; i < 20; i++ {
+		files[fmt.Sprintf("file%d.txt", i)] = lines(2000)
+	}
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: files})
+	for name := range files {
+		files[name] = strings.ReplaceAll(lines(2000), "0\n", "0 changed\n")
+	}
+	c, err := r.repo.CommitObject(r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: files}))
+	if err != nil {
+		b.Fatal(err)
+	}
+	return c
+}
+
+func BenchmarkCommitFileStats(b *testing.B) {
+	c := benchmarkCommit(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := commitFileStats(context.Background(), c, 60, false); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkCommitStats measures c.Stats(), which commitFileStats replaces,
+// for comparing their time and allocations.
+func BenchmarkCommitStats(b *testing.B) {
+	c := benchmarkCommit(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.Stats(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestRenameThreshold(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"old.txt": lines(10)}})
+	// Moved with 4 of its 10 lines rewritten.
+	edited := strings.Replace(lines(10), "line 1\nline 2\nline 3\nline 4\n", "one\ntwo\nthree\nfour\n", 1)
+	moved := r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"old.txt": "", "new.txt": edited}})
+	c, err := r.repo.CommitObject(moved)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		threshold int
+		want      string
+		deletions string
+	}{
+		{1, "old.txt => new.txt +4 -4", "4"},
+		{50, "old.txt => new.txt +4 -4", "4"},
+		{70, "new.txt +10 -0, old.txt +0 -10", "10"},
+		{100, "new.txt +10 -0, old.txt +0 -10", "10"},
+	}
+	for _, tt := range tests {
+		stats, err := commitFileStats(context.Background(), c, tt.threshold, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := sortedStats(stats); got != tt.want {
+			t.Errorf("rename threshold %d: %q, want %q", tt.threshold, got, tt.want)
+		}
+
+		out := mustRun(t, r.dir, "--rename-threshold", fmt.Sprint(tt.threshold), ".", "2024-03-01", "2024-03-02")
+		if row := tableRow(out, "Total"); row[3] != tt.deletions {
+			t.Errorf("--rename-threshold %d: %s deletions, want %s", tt.threshold, row[3], tt.deletions)
+		}
+	}
+
+	for _, threshold := range []string{"0", "101"} {
+		if stdout, stderr, code := runGitStat(t, r.dir, "--rename-threshold", threshold, ".", "2024-03-01", "2024-03-02"); code == 0 || !strings.Contains(stdout+stderr, "must be between 1 and 100") {
+			t.Errorf("--rename-threshold %s exited with %d: %s%s", threshold, code, stdout, stderr)
+		}
+	}
+}
+
+func TestCommitByteChanges(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{
+		"a.txt":       lines(10),
+		"b.txt":       "0123456789\n",
+		"data.min.js": strings.Repeat("x", 1000),
+	}})
+	edit := r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{
+		"a.txt":       lines(12),
+		"b.txt":       "",
+		"data.min.js": strings.Repeat("y", 400),
+	}})
+	move := r.commit(testCommit{when: "2024-03-03T10:00:00Z", files: map[string]string{"a.txt": "", "c.txt": lines(13)}})
+
+	tests := []struct {
+		commit plumbing.Hash
+		want   map[string]int
+	}{
+		{edit, map[string]int{"a.txt": 16, "b.txt": 11, "data.min.js": 600}},
+		{move, map[string]int{"a.txt => c.txt": 8}},
+	}
+	for _, tt := range tests {
+		c, err := r.repo.CommitObject(tt.commit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		byteChanges, err := commitByteChanges(c, 60)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(byteChanges) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: %v, want %v", c.Message, byteChanges, tt.want)
+		}
+	}
+
+	out := mustRun(t, r.dir, "--bytes", ".", "2024-03-01", "2024-03-03")
+	for label, want := range map[string]string{"2024-03-01": "1082", "2024-03-02": "627", "2024-03-03": "8", "Total": "1717"} {
+		if row := tableRow(out, label); row == nil || row[5] != want {
+			t.Errorf("--bytes: %s %v, want %s bytes changed:\n%s", label, row, want, out)
+		}
+	}
+	if out := mustRun(t, r.dir, ".", "2024-03-01", "2024-03-03"); strings.Contains(out, "Bytes Changed") {
+		t.Errorf("bytes shown without --bytes:\n%s", out)
+	}
+}
+
+func TestCommitNetFiles(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a.txt": lines(10), "b.txt": "b\n", "c.txt": "c\n"}})
+	edit := r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a.txt": lines(12), "b.txt": "", "d.txt": "d\n"}})
+	move := r.commit(testCommit{when: "2024-03-03T10:00:00Z", files: map[string]string{"a.txt": "", "e.txt": lines(13), "c.txt": ""}})
+
+	tests := []struct {
+		commit plumbing.Hash
+		want   map[string]int
+	}{
+		{edit, map[string]int{"b.txt": -1, "d.txt": 1}},
+		{move, map[string]int{"c.txt": -1}},
+	}
+	for _, tt := range tests {
+		c, err := r.repo.CommitObject(tt.commit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		netFiles, err := commitNetFiles(c, 60)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(netFiles) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: %v, want %v", c.Message, netFiles, tt.want)
+		}
+	}
+
+	out := mustRun(t, r.dir, "--net-files", ".", "2024-03-01", "2024-03-03")
+	for label, want := range map[string]string{"2024-03-01": "+3", "2024-03-02": "0", "2024-03-03": "-1", "Total": "+2"} {
+		if row := tableRow(out, label); row == nil || row[5] != want {
+			t.Errorf("--net-files: %s %v, want %s net files:\n%s", label, row, want, out)
+		}
+	}
+	if out := mustRun(t, r.dir, ".", "2024-03-01", "2024-03-03"); strings.Contains(out, "Net Files") {
+		t.Errorf("net files shown without --net-files:\n%s", out)
+	}
+}
+
+func TestNormalizeLineEndings(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-02-28T10:00:00Z", files: map[string]string{"a.txt": "x\ny\nz\n"}})
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a.txt": "x\r\ny\r\nz\r\n"}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a.txt": "x\r\nY\r\nz\r\nw\r\n"}})
+	r.commit(testCommit{when: "2024-03-03T10:00:00Z", files: map[string]string{"a.txt": "x\nY\nz\nW\n", "b.txt": "b\r\n"}})
+
+	tests := []struct {
+		flag string
+		days map[string][2]string // the additions and deletions by row
+	}{
+		{"", map[string][2]string{
+			"2024-03-01": {"3", "3"},
+			"2024-03-02": {"2", "1"},
+			"2024-03-03": {"5", "4"},
+			"Total":      {"10", "8"},
+		}},
+		// Converting the line endings is no change, leaving a day of
+		// zero churn, though the lines changed along with it still
+		// are, and new files keep all of their lines.
+		{"--normalize-line-endings", map[string][2]string{
+			"2024-03-01": {"0", "0"},
+			"2024-03-02": {"2", "1"},
+			"2024-03-03": {"2", "1"},
+			"Total":      {"4", "2"},
+		}},
+	}
+	for _, tt := range tests {
+		args := []string{".", "2024-03-01", "2024-03-03"}
+		if tt.flag != "" {
+			args = append([]string{tt.flag}, args...)
+		}
+		out := mustRun(t, r.dir, args...)
+		for label, want := range tt.days {
+			if row := tableRow(out, label); row == nil || row[2] != want[0] || row[3] != want[1] {
+				t.Errorf("%q: %s %v, want +%s -%s:\n%s", tt.flag, label, row, want[0], want[1], out)
+			}
+		}
+	}
+}
+
+func TestStatsContext(t *testing.T) {
+	tests := []struct {
+		timeout      time.Duration
+		wantDeadline bool
+	}{
+		{0, false},
+		{time.Minute, true},
+	}
+	for _, tt := range tests {
+		ctx, cancel := statsContext(tt.timeout)
+		deadline, ok := ctx.Deadline()
+		if ok != tt.wantDeadline {
+			t.Errorf("statsContext(%s) has a deadline: %t, want %t", tt.timeout, ok, tt.wantDeadline)
+		}
+		if ok && time.Until(deadline) > tt.timeout {
+			t.Errorf("statsContext(%s) has the deadline %s", tt.timeout, deadline)
+		}
+		cancel()
+		if ctx.Err() != context.Canceled {
+			t.Errorf("statsContext(%s) after cancel: %v, want %v", tt.timeout, ctx.Err(), context.Canceled)
+		}
+	}
+}
+
+func TestStatsTimeout(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-02-28T10:00:00Z", files: map[string]string{"a.txt": lines(1)}})
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a.txt": lines(3)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a.txt": lines(6), "b.txt": lines(2)}})
+
+	tests := []struct {
+		timeout   string
+		additions string // in the total row, none for no row
+		skipped   string
+	}{
+		{"0", "7", ""},
+		{"1m", "7", ""},
+		// Nothing can be computed in a nanosecond, so every commit
+		// is skipped and reported.
+		{"1ns", "", "Skipped 2 commits (timeout) whose changes took longer than 1ns to compute"},
+	}
+	for _, tt := range tests {
+		stdout, stderr, code := runGitStat(t, r.dir, "--stats-timeout", tt.timeout, ".", "2024-03-01", "2024-03-02")
+		if code != 0 {
+			t.Fatalf("--stats-timeout %s exited with %d: %s%s", tt.timeout, code, stdout, stderr)
+		}
+		row := tableRow(stdout, "Total")
+		if tt.additions == "" && row != nil && row[2] != "0" {
+			t.Errorf("--stats-timeout %s: total row %v, want none counted", tt.timeout, row)
+		}
+		if tt.additions != "" && (row == nil || row[2] != tt.additions) {
+			t.Errorf("--stats-timeout %s: total row %v, want %s additions", tt.timeout, row, tt.additions)
+		}
+		if tt.skipped == "" && strings.Contains(stderr, "(timeout)") || !strings.Contains(stderr, tt.skipped) {
+			t.Errorf("--stats-timeout %s: stderr %q, want %q", tt.timeout, stderr, tt.skipped)
+		}
+	}
+
+	_, stderr, code := runGitStat(t, r.dir, "--stats-timeout", "-1s", ".", "2024-03-01", "2024-03-02")
+	if code == 0 || !strings.Contains(stderr, "--stats-timeout must not be negative") {
+		t.Errorf("--stats-timeout -1s exited with %d: %s", code, stderr)
+	}
+}
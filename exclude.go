@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// readCommitList returns the commits listed in filename, one per line.
+// Blank lines and lines starting with # are skipped, as is anything after
+// the hash, so the output of `git log --oneline` can be used as is.
+func readCommitList(filename string) ([]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var revs []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		revs = append(revs, fields[0])
+	}
+	return revs, scanner.Err()
+}
+
+// resolveCommits resolves the possibly abbreviated hashes of revs. It is an
+// error for one of them not to name a commit, so a typo does not go
+// unnoticed.
+func resolveCommits(repo *git.Repository, revs []string) (map[plumbing.Hash]bool, error) {
+	hashes := make(map[plumbing.Hash]bool, len(revs))
+	for _, rev := range revs {
+		hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+		if err != nil {
+			return nil, fmt.Errorf("cannot resolve commit %q: %w", rev, err)
+		}
+		hashes[*hash] = true
+	}
+	return hashes, nil
+}
+
+// listedCommits resolves the commits of --commits-file, which are analyzed
+// instead of those in a date range. Unknown hashes are warned about and
+// skipped, unless strict makes them an error. The commits are returned
+// newest first, as they would be walked, along with the first and last day
+// they were authored on.
+func listedCommits(repo *git.Repository, filename string, strict bool) ([]*object.Commit, time.Time, time.Time, error) {
+	revs, err := readCommitList(filename)
+	if err != nil {
+		return nil, time.Time{}, time.Time{}, err
+	}
+
+	var commits []*object.Commit
+	seen := make(map[plumbing.Hash]bool, len(revs))
+	for _, rev := range revs {
+		hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+		if err == nil {
+			var commit *object.Commit
+			if commit, err = repo.CommitObject(*hash); err == nil {
+				if !seen[commit.Hash] {
+					seen[commit.Hash] = true
+					commits = append(commits, commit)
+				}
+				continue
+			}
+		}
+		if strict {
+			return nil, time.Time{}, time.Time{}, fmt.Errorf("cannot resolve commit %q: %w", rev, err)
+		}
+		warnf("skipping %s from %s: %v", rev, filename, err)
+	}
+	if len(commits) == 0 {
+		return nil, time.Time{}, time.Time{}, fmt.Errorf("%s lists no known commits", filename)
+	}
+
+	sort.Slice(commits, func(i, j int) bool {
+		return commits[i].Committer.When.After(commits[j].Committer.When)
+	})
+
+	// The days are those the commits are counted on, in the time zone of
+	// their author, which need not be in the same order as the times.
+	var startDay, endDay time.Time
+	for i, commit := range commits {
+		day, _ := parseDate(commit.Author.When.Format("2006-01-02"))
+		if i == 0 || day.Before(startDay) {
+			startDay = day
+		}
+		if i == 0 || day.After(endDay) {
+			endDay = day
+		}
+	}
+	return commits, startDay, endDay, nil
+}
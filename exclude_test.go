@@ -0,0 +1,172 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestReadCommitList(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "commits")
+	content := "# bulk reformats\n1a2b3c4 Reformat everything\n\n  deadbeef\n#5e6f7a8\nHEAD~2 trailing words\n"
+	if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	revs, err := readCommitList(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"1a2b3c4", "deadbeef", "HEAD~2"}; !slices.Equal(revs, want) {
+		t.Errorf("readCommitList = %q, want %q", revs, want)
+	}
+
+	if _, err := readCommitList(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("reading a missing commit list succeeded")
+	}
+}
+
+func TestExcludeCommit(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	bulk := r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(101)}})
+	typo := r.commit(testCommit{when: "2024-03-02T11:00:00Z", files: map[string]string{"b": lines(10)}})
+	r.commit(testCommit{when: "2024-03-03T10:00:00Z", files: map[string]string{"b": lines(12)}})
+
+	list := filepath.Join(t.TempDir(), "excluded")
+	if err := os.WriteFile(list, []byte(bulk.String()[:7]+" Reformat\n"+typo.String()+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args     []string
+		changes  string
+		excluded string
+	}{
+		{nil, "113", ""},
+		{[]string{"--exclude-commit", bulk.String()}, "13", "Excluded 1 commits"},
+		{[]string{"--exclude-commit", bulk.String()[:7]}, "13", "Excluded 1 commits"},
+		{[]string{"--exclude-commit", bulk.String()[:7], "--exclude-commit", typo.String()[:10]}, "3", "Excluded 2 commits"},
+		{[]string{"--exclude-commits-file", list}, "3", "Excluded 2 commits"},
+	}
+	for _, tt := range tests {
+		stdout, stderr, code := runGitStat(t, r.dir, append(tt.args, ".", "2024-03-01", "2024-03-03")...)
+		if code != 0 {
+			t.Fatalf("%v exited with %d: %s", tt.args, code, stderr)
+		}
+		if row := tableRow(stdout, "Total"); row[4] != tt.changes {
+			t.Errorf("%v: %s changes, want %s", tt.args, row[4], tt.changes)
+		}
+		if tt.excluded == "" && strings.Contains(stderr, "Excluded") || !strings.Contains(stderr, tt.excluded) {
+			t.Errorf("%v: stderr %q, want %q", tt.args, stderr, tt.excluded)
+		}
+	}
+
+	if stdout, stderr, code := runGitStat(t, r.dir, "--exclude-commit", "0123456", ".", "2024-03-01", "2024-03-03"); code == 0 || !strings.Contains(stderr, `cannot resolve commit "0123456"`) {
+		t.Errorf("excluding an unknown commit exited with %d: %s%s", code, stdout, stderr)
+	}
+}
+
+func TestListedCommits(t *testing.T) {
+	defer func(level logLevel) { currentLogLevel = level }(currentLogLevel)
+	currentLogLevel = levelError
+
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	second := r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(3)}})
+	// Authored on 03-05 in its own time zone, before the next commit in UTC.
+	third := r.commit(testCommit{when: "2024-03-05T01:00:00+09:00", files: map[string]string{"b": lines(4)}})
+	fourth := r.commit(testCommit{when: "2024-03-04T20:00:00Z", files: map[string]string{"a": lines(4)}})
+
+	tests := []struct {
+		name       string
+		list       string
+		strict     bool
+		want       []plumbing.Hash
+		start, end string
+		wantErr    string
+	}{
+		{"newest first", second.String() + "\n" + fourth.String()[:7] + "\n" + third.String() + "\n", false, []plumbing.Hash{fourth, third, second}, "2024-03-02", "2024-03-05", ""},
+		{"duplicates", second.String() + "\n" + second.String()[:7] + "\n", false, []plumbing.Hash{second}, "2024-03-02", "2024-03-02", ""},
+		{"unknown skipped", "0123456\n" + fourth.String() + "\n", false, []plumbing.Hash{fourth}, "2024-03-04", "2024-03-04", ""},
+		{"unknown with strict", "0123456\n" + fourth.String() + "\n", true, nil, "", "", `cannot resolve commit "0123456"`},
+		{"nothing known", "0123456\n", false, nil, "", "", "lists no known commits"},
+	}
+	for _, tt := range tests {
+		filename := filepath.Join(t.TempDir(), "commits")
+		if err := os.WriteFile(filename, []byte(tt.list), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		commits, start, end, err := listedCommits(r.repo, filename, tt.strict)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: error %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		var hashes []plumbing.Hash
+		for _, c := range commits {
+			hashes = append(hashes, c.Hash)
+		}
+		if !slices.Equal(hashes, tt.want) {
+			t.Errorf("%s: %v, want %v", tt.name, hashes, tt.want)
+		}
+		if got := start.Format(time.DateOnly) + " " + end.Format(time.DateOnly); got != tt.start+" "+tt.end {
+			t.Errorf("%s: range %s, want %s %s", tt.name, got, tt.start, tt.end)
+		}
+	}
+}
+
+func TestCommitsFile(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	second := r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(3)}})
+	r.commit(testCommit{when: "2024-03-03T10:00:00Z", files: map[string]string{"a": lines(10)}})
+	fourth := r.commit(testCommit{when: "2024-03-04T10:00:00Z", files: map[string]string{"b": lines(4)}})
+
+	list := filepath.Join(t.TempDir(), "commits")
+	if err := os.WriteFile(list, []byte(fourth.String()+"\n# cherry-picked\n"+second.String()[:7]+"\n0123456\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, code := runGitStat(t, r.dir, "--commits-file", list, ".")
+	if code != 0 {
+		t.Fatalf("--commits-file exited with %d: %s%s", code, stdout, stderr)
+	}
+	for label, additions := range map[string]string{"2024-03-02": "2", "2024-03-04": "4", "Total": "6"} {
+		if row := tableRow(stdout, label); row == nil || row[2] != additions {
+			t.Errorf("%s: %v, want %s additions:\n%s", label, row, additions, stdout)
+		}
+	}
+	if row := tableRow(stdout, "2024-03-03"); row != nil && row[2] != "0" {
+		t.Errorf("the unlisted commit is counted: %v", row)
+	}
+	if !strings.Contains(stderr, "skipping 0123456") {
+		t.Errorf("no warning about the unknown commit: %s", stderr)
+	}
+
+	failures := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--commits-file", list, "--strict", "."}, `Invalid --commits-file: cannot resolve commit "0123456"`},
+		{[]string{"--strict", ".", "2024-03-01", "2024-03-04"}, "--strict needs --commits-file"},
+		{[]string{"--commits-file", list, "--since-commit", second.String(), "."}, "--commits-file cannot be used with"},
+		{[]string{"--commits-file", list, ".", "2024-03-01", "2024-03-04"}, "Usage: git-stat"},
+	}
+	for _, tt := range failures {
+		stdout, stderr, code := runGitStat(t, r.dir, tt.args...)
+		if code == 0 || !strings.Contains(stdout+stderr, tt.want) {
+			t.Errorf("%v exited with %d: %s%s\nwant the error %q", tt.args, code, stdout, stderr, tt.want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// explainedCommit is a commit counted toward the day given to --explain,
+// with the files it contributed.
+type explainedCommit struct {
+	Hash    string
+	When    time.Time
+	Subject string
+	Files   []explainedFile
+}
+
+// explainedFile is the part of a file in an explainedCommit. Changes is
+// counted as set by --churn-mode.
+type explainedFile struct {
+	Name      string
+	Additions int
+	Deletions int
+	Changes   int
+}
+
+// explainCommit records c and its file stats for --explain.
+func explainCommit(c *object.Commit, stats object.FileStats, mode string) explainedCommit {
+	explained := explainedCommit{
+		Hash:    c.Hash.String(),
+		When:    c.Author.When,
+		Subject: strings.TrimSpace(strings.SplitN(c.Message, "\n", 2)[0]),
+	}
+	for _, stat := range stats {
+		explained.Files = append(explained.Files, explainedFile{
+			Name:      stat.Name,
+			Additions: stat.Addition,
+			Deletions: stat.Deletion,
+			Changes:   fileChanges(stat, mode),
+		})
+	}
+	return explained
+}
+
+// printExplanation lists the commits counted toward the day of --explain, in
+// the order they were made, each followed by its files, so the numbers of
+// the day's row can be traced back to them.
+func printExplanation(w io.Writer, report *Report) {
+	date := report.Options.Explain
+	stats, ok := report.DailyStats[date]
+	if !ok {
+		fmt.Fprintf(w, "\nNo commits were counted on %s\n", date)
+		return
+	}
+
+	commits := append([]explainedCommit(nil), stats.Explained...)
+	sort.Slice(commits, func(i, j int) bool {
+		if !commits[i].When.Equal(commits[j].When) {
+			return commits[i].When.Before(commits[j].When)
+		}
+		return commits[i].Hash < commits[j].Hash
+	})
+
+	fmt.Fprintf(w, "\n%s: %d commits, %d files, +%d -%d, %d changes\n",
+		date, stats.Commits, len(stats.FilesChanged), stats.Additions, stats.Deletions, stats.Changes)
+	for _, c := range commits {
+		additions, deletions, changes := 0, 0, 0
+		nameWidth := 0
+		for _, file := range c.Files {
+			additions += file.Additions
+			deletions += file.Deletions
+			changes += file.Changes
+			nameWidth = max(nameWidth, textWidth(file.Name))
+		}
+		fmt.Fprintf(w, "  %s %s  +%d -%d, %d changes  %s\n",
+			c.Hash[:7], c.When.Format("15:04"), additions, deletions, changes, c.Subject)
+		for _, file := range c.Files {
+			fmt.Fprintf(w, "      %s  +%d -%d\n", padText(file.Name, nameWidth), file.Additions, file.Deletions)
+		}
+	}
+}
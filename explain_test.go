@@ -0,0 +1,91 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExplainCommit(t *testing.T) {
+	r, commits := diffStatRepo(t)
+
+	tests := []struct {
+		commit string
+		mode   string
+		want   []explainedFile
+	}{
+		{"modify", churnSum, []explainedFile{{"a.txt", 3, 1, 4}}},
+		{"modify", churnMax, []explainedFile{{"a.txt", 3, 1, 3}}},
+		{"delete", churnNet, []explainedFile{{"dir/b.txt", 0, 3, -3}}},
+	}
+	for _, tt := range tests {
+		c, err := r.repo.CommitObject(commits[tt.commit])
+		if err != nil {
+			t.Fatal(err)
+		}
+		stats, err := c.Stats()
+		if err != nil {
+			t.Fatal(err)
+		}
+		explained := explainCommit(c, stats, tt.mode)
+		if explained.Hash != c.Hash.String() || explained.Subject != tt.commit || !explained.When.Equal(c.Author.When) {
+			t.Errorf("%s: commit %s %s %q", tt.commit, explained.Hash, explained.When, explained.Subject)
+		}
+		if len(explained.Files) != len(tt.want) {
+			t.Errorf("%s with the churn mode %q: files %v, want %v", tt.commit, tt.mode, explained.Files, tt.want)
+			continue
+		}
+		for i, file := range explained.Files {
+			if file != tt.want[i] {
+				t.Errorf("%s with the churn mode %q: file %v, want %v", tt.commit, tt.mode, file, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestExplain(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-02-28T10:00:00Z", files: map[string]string{"a.txt": lines(1)}})
+	second := r.commit(testCommit{when: "2024-03-01T15:30:00Z", message: "Second\n\nThe body.", files: map[string]string{"a.txt": lines(3), "docs/guide.md": lines(2)}})
+	first := r.commit(testCommit{when: "2024-03-01T09:00:00Z", message: "First", files: map[string]string{"a.txt": "line 1\n"}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"b.txt": lines(1)}})
+
+	tests := []struct {
+		day  string
+		want []string // the lines below the table
+	}{
+		// The commits are listed in the order they were made, with
+		// their files aligned.
+		{"2024-03-01", []string{
+			"2024-03-01: 2 commits, 2 files, +4 -2, 6 changes",
+			"  " + first.String()[:7] + " 09:00  +0 -2, 2 changes  First",
+			"      a.txt  +0 -2",
+			"  " + second.String()[:7] + " 15:30  +4 -0, 4 changes  Second",
+			"      a.txt          +2 -0",
+			"      docs/guide.md  +2 -0",
+		}},
+		{"2024-03-03", []string{"No commits were counted on 2024-03-03"}},
+	}
+	for _, tt := range tests {
+		out := mustRun(t, r.dir, "--explain", tt.day, ".", "2024-03-01", "2024-03-03")
+		_, explanation, ok := strings.Cut(out, "\n\n")
+		if !ok || strings.TrimRight(explanation, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("--explain %s:\n%s\nwant below the table:\n%s", tt.day, out, strings.Join(tt.want, "\n"))
+		}
+	}
+
+	failures := []struct {
+		flags []string
+		want  string
+	}{
+		{[]string{"--explain", "03/01"}, `expected a date as YYYY-MM-DD, got "03/01"`},
+		{[]string{"--explain", "2024-02-28"}, "--explain 2024-02-28 is outside of the date range"},
+		{[]string{"--explain", "2024-03-01", "--by-author"}, "--explain cannot be used with --top-days"},
+		{[]string{"--explain", "2024-03-01", "--top-days", "1"}, "--explain cannot be used with --top-days"},
+	}
+	for _, tt := range failures {
+		stdout, stderr, code := runGitStat(t, r.dir, append(tt.flags, ".", "2024-03-01", "2024-03-03")...)
+		if code == 0 || !strings.Contains(stdout+stderr, tt.want) {
+			t.Errorf("%v exited with %d: %s%s\nwant the error %q", tt.flags, code, stdout, stderr, tt.want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// fileCount is the number of files under --path at the end of a period.
+type fileCount struct {
+	Period period
+	Files  int
+}
+
+// getFileCounts counts the files under opts.Paths in the tree of the last
+// first-parent commit made by the end of each period. Periods before the
+// first commit have no files.
+func getFileCounts(repo *git.Repository, periods []period, opts *Options) ([]fileCount, error) {
+	from, _, err := resolveBranch(repo, opts.Branch)
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := repo.CommitObject(from)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]fileCount, len(periods))
+	cache := make(map[plumbing.Hash]int)
+
+	// Walk back from the newest period, following first parents until a
+	// commit made by the end of the period is found.
+	for i := len(periods) - 1; i >= 0; i-- {
+		end := periods[i].End.Add(24 * time.Hour).Add(-time.Second)
+
+		for commit != nil && commit.Committer.When.After(end) {
+			if commit.NumParents() == 0 {
+				commit = nil
+				break
+			}
+			if commit, err = commit.Parent(0); err != nil {
+				return nil, err
+			}
+		}
+
+		counts[i].Period = periods[i]
+		if commit == nil {
+			continue
+		}
+
+		n, ok := cache[commit.Hash]
+		if !ok {
+			if n, err = countFiles(commit, opts.Paths); err != nil {
+				return nil, err
+			}
+			cache[commit.Hash] = n
+		}
+		counts[i].Files = n
+	}
+
+	return counts, nil
+}
+
+// countFiles counts the files in the tree of c that lie within paths (or all
+// files when no paths are given).
+func countFiles(c *object.Commit, paths []string) (int, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	err = tree.Files().ForEach(func(f *object.File) error {
+		if len(paths) == 0 || inPaths(f.Name, paths) {
+			n++
+		}
+		return nil
+	})
+	return n, err
+}
+
+// printFileCountTable prints the file count at the end of every period and
+// how it changed since the previous period.
+func printFileCountTable(w io.Writer, counts []fileCount, periodName string) {
+	const filesWidth, deltaWidth = 11, 11
+
+	printCells(w,
+		centerText("Period", labelWidth),
+		centerText("Files", filesWidth),
+		centerText("Change", deltaWidth))
+	printRule(w, labelWidth, filesWidth, deltaWidth)
+
+	for i, count := range counts {
+		delta := centerText("", deltaWidth)
+		if i > 0 {
+			change := count.Files - counts[i-1].Files
+			delta = colorArrow(centerText(formatArrowDelta(change), deltaWidth), change)
+		}
+
+		printCells(w,
+			padText(count.Period.label(periodName, i), labelWidth),
+			centerText(formatCount(count.Files), filesWidth),
+			delta)
+		printRule(w, labelWidth, filesWidth, deltaWidth)
+	}
+}
+
+// formatDelta formats a change with an explicit sign, e.g. +3 or -1.
+func formatDelta(n int) string {
+	if n > 0 {
+		return "+" + strconv.Itoa(n)
+	}
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatDelta(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{3, "+3"},
+		{0, "0"},
+		{-1, "-1"},
+	}
+	for _, tt := range tests {
+		if got := formatDelta(tt.n); got != tt.want {
+			t.Errorf("formatDelta(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestFileCount(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-04T10:00:00Z", files: map[string]string{"src/a": lines(1), "src/b": lines(1), "x": lines(1)}})
+	r.commit(testCommit{when: "2024-03-12T10:00:00Z", files: map[string]string{"src/c": lines(1), "y": lines(1)}})
+	r.commit(testCommit{when: "2024-03-19T10:00:00Z", files: map[string]string{"src/a": "", "src/b": ""}})
+
+	tests := []struct {
+		path string
+		rows map[string][]string // files and change by week
+	}{
+		{"src", map[string][]string{
+			"2024-03-01 ~ 03-03": {"0", ""},
+			"2024-03-04 ~ 03-10": {"2", "+2"},
+			"2024-03-11 ~ 03-17": {"3", "+1"},
+			"2024-03-18 ~ 03-24": {"1", "-2"},
+		}},
+		{"", map[string][]string{
+			"2024-03-04 ~ 03-10": {"3", "+3"},
+			"2024-03-11 ~ 03-17": {"5", "+2"},
+			"2024-03-18 ~ 03-24": {"3", "-2"},
+		}},
+	}
+	for _, tt := range tests {
+		args := []string{"--file-count", "--period", "week", ".", "2024-03-01", "2024-03-24"}
+		if tt.path != "" {
+			args = append([]string{"--path", tt.path}, args...)
+		}
+		out := mustRun(t, r.dir, args...)
+		for label, want := range tt.rows {
+			row := tableRow(out, label)
+			if row == nil {
+				t.Errorf("--path %q: no row for %s:\n%s", tt.path, label, out)
+				continue
+			}
+			if got := row[1:]; strings.Join(got, " ") != strings.Join(want, " ") {
+				t.Errorf("--path %q: %s has %q, want %q", tt.path, label, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// fileFollower keeps the changes to the file given with --file. With
+// --follow the file is followed back through renames: commits are seen
+// newest first, so once the commit moving the file to its current name has
+// been seen, older commits are matched against its previous name. Every
+// walked commit is tracked, including those the other options leave out,
+// so they cannot break the chain of renames.
+type fileFollower struct {
+	name   string
+	follow bool
+}
+
+func newFileFollower(opts *Options) *fileFollower {
+	return &fileFollower{name: normalizePath(opts.File), follow: opts.Follow}
+}
+
+// filter returns the stats of the followed file within the commit's stats.
+func (f *fileFollower) filter(stats object.FileStats) object.FileStats {
+	var filtered object.FileStats
+	for _, stat := range stats {
+		if from, to := splitRename(stat.Name); to == f.name || from == f.name {
+			filtered = append(filtered, stat)
+		}
+	}
+	return filtered
+}
+
+// track follows the file back to its previous name when a commit moved it,
+// given the new names of the files renamed by the commit mapped to their old
+// ones. It is called once the commit has been filtered.
+func (f *fileFollower) track(moves map[string]string) {
+	if previous, ok := moves[f.name]; ok && f.follow {
+		debugf("following %s back to %s", f.name, previous)
+		f.name = previous
+	}
+}
+
+// commitMoves returns the files renamed by c, their new names mapped to
+// their old ones. Only the trees are compared, so files moved without
+// changes to their lines, which have no stats, are found as well.
+func commitMoves(c *object.Commit, renameThreshold int) (map[string]string, error) {
+	changes, err := commitChanges(c, renameThreshold)
+	if err != nil {
+		return nil, err
+	}
+
+	moves := make(map[string]string)
+	for _, change := range changes {
+		if change.From.Name != "" && change.To.Name != "" && change.From.Name != change.To.Name {
+			moves[change.To.Name] = change.From.Name
+		}
+	}
+	return moves, nil
+}
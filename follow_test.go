@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestFileFollower(t *testing.T) {
+	stats := object.FileStats{
+		{Name: "a.go", Addition: 1},
+		{Name: "old.go => new.go", Addition: 2},
+		{Name: "new.go.orig", Addition: 4},
+	}
+
+	tests := []struct {
+		file    string
+		follow  bool
+		moves   map[string]string
+		want    string
+		tracked string
+	}{
+		{"a.go", false, nil, "a.go +1 -0", "a.go"},
+		{"./new.go", false, map[string]string{"new.go": "old.go"}, "old.go => new.go +2 -0", "new.go"},
+		{"new.go", true, map[string]string{"new.go": "old.go"}, "old.go => new.go +2 -0", "old.go"},
+		{"old.go", true, map[string]string{"new.go": "old.go"}, "old.go => new.go +2 -0", "old.go"},
+		{"b.go", true, map[string]string{"new.go": "old.go"}, "", "b.go"},
+	}
+	for _, tt := range tests {
+		f := newFileFollower(&Options{File: tt.file, Follow: tt.follow})
+		if got := sortedStats(f.filter(stats)); got != tt.want {
+			t.Errorf("--file %s: %q, want %q", tt.file, got, tt.want)
+		}
+		f.track(tt.moves)
+		if f.name != tt.tracked {
+			t.Errorf("--file %s with --follow %t: tracking %s after the commit, want %s", tt.file, tt.follow, f.name, tt.tracked)
+		}
+	}
+}
+
+func TestFollow(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"old.go": lines(3), "other.go": lines(1)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"old.go": lines(5), "other.go": lines(2)}})
+	r.commit(testCommit{when: "2024-03-03T20:00:00Z", files: map[string]string{"old.go": "", "new.go": lines(5)}})
+	r.commit(testCommit{when: "2024-03-04T10:00:00Z", files: map[string]string{"new.go": lines(6), "other.go": lines(3)}})
+
+	tests := []struct {
+		args      []string
+		additions string
+	}{
+		{[]string{"--file", "new.go"}, "1"},
+		{[]string{"--file", "new.go", "--follow"}, "6"},
+		// The move is made after hours, yet still followed.
+		{[]string{"--file", "new.go", "--follow", "--hours", "9-12"}, "6"},
+		{[]string{"--file", "old.go"}, "5"},
+		{[]string{"--file", "other.go", "--follow"}, "3"},
+	}
+	for _, tt := range tests {
+		out := mustRun(t, r.dir, append(tt.args, ".", "2024-03-01", "2024-03-04")...)
+		if row := tableRow(out, "Total"); row[2] != tt.additions {
+			t.Errorf("%v: %s additions, want %s:\n%s", tt.args, row[2], tt.additions, out)
+		}
+	}
+
+	if stdout, stderr, code := runGitStat(t, r.dir, "--follow", ".", "2024-03-01", "2024-03-04"); code == 0 || !strings.Contains(stdout+stderr, "--file") {
+		t.Errorf("--follow without --file exited with %d: %s%s", code, stdout, stderr)
+	}
+}
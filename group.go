@@ -0,0 +1,87 @@
+package main
+
+import (
+	"io"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// getGroupStats aggregates the changes in the date range by the group that
+// groupOf assigns to each changed file. A commit is counted once for every
+// group it touches.
+func getGroupStats(repo *git.Repository, startDate, endDate time.Time, opts *Options, groupOf func(c *object.Commit, stat object.FileStat) string) (map[string]*DailyStats, error) {
+	groupStats := make(map[string]*DailyStats)
+
+	err := walkCommits(repo, startDate, endDate, opts, func(c *object.Commit, stats object.FileStats) error {
+		touched := make(map[string]struct{})
+
+		for _, stat := range stats {
+			group := groupOf(c, stat)
+
+			if _, ok := groupStats[group]; !ok {
+				groupStats[group] = &DailyStats{
+					FilesChanged: make(map[string]struct{}),
+					Authors:      make(map[string]struct{}),
+				}
+			}
+
+			if _, ok := touched[group]; !ok {
+				touched[group] = struct{}{}
+				groupStats[group].Commits++
+			}
+
+			groupStats[group].FilesChanged[stat.Name] = struct{}{}
+			groupStats[group].Authors[authorKey(c.Author, opts)] = struct{}{}
+			groupStats[group].Additions += stat.Addition
+			groupStats[group].Deletions += stat.Deletion
+			groupStats[group].Changes += fileChanges(stat, opts.ChurnMode)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return groupStats, nil
+}
+
+// printGroupTable prints one row per group, the groups with the most changes
+// first.
+func printGroupTable(w io.Writer, title string, groupStats map[string]*DailyStats) {
+	rows := make([]*DailyStats, 0, len(groupStats))
+	for _, stats := range groupStats {
+		rows = append(rows, stats)
+	}
+	fitColumns(rows)
+
+	groups := sortedByChanges(groupStats)
+	fitLabels(groups)
+
+	printTableHeader(w, title)
+
+	for _, group := range groups {
+		printTableRow(w, group, groupStats[group], "")
+	}
+}
+
+// printWeekdayTable prints one row per weekday, Monday first.
+func printWeekdayTable(w io.Writer, weekdayStats map[string]*DailyStats) {
+	rows := make([]*DailyStats, 0, len(weekdayStats))
+	for _, stats := range weekdayStats {
+		rows = append(rows, stats)
+	}
+	fitColumns(rows)
+
+	printTableHeader(w, "Weekday")
+
+	for i := 1; i <= 7; i++ {
+		day := time.Weekday(i % 7)
+		if stats, ok := weekdayStats[day.String()]; ok {
+			printTableRow(w, weekdayName(day), stats, "")
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"io"
+	"sort"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// hotspotColumns replace the table columns with --hotspots, where every row
+// is a single file.
+var hotspotColumns = []tableColumn{commitsColumn, tableColumns[1], tableColumns[2], tableColumns[3]}
+
+// getFileStats aggregates the changes by file. Renamed files are counted
+// under their new name.
+func getFileStats(repo *git.Repository, startDate, endDate time.Time, opts *Options) (map[string]*DailyStats, error) {
+	return getGroupStats(repo, startDate, endDate, opts, func(c *object.Commit, stat object.FileStat) string {
+		_, to := splitRename(stat.Name)
+		return to
+	})
+}
+
+// printHotspotsTable prints the n files changed by the most commits, most
+// first and by name on a tie.
+func printHotspotsTable(w io.Writer, fileStats map[string]*DailyStats, n int) {
+	files := make([]string, 0, len(fileStats))
+	for file := range fileStats {
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		a, b := fileStats[files[i]], fileStats[files[j]]
+		if a.Commits != b.Commits {
+			return a.Commits > b.Commits
+		}
+		return files[i] < files[j]
+	})
+	if len(files) > n {
+		files = files[:n]
+	}
+
+	rows := make([]*DailyStats, 0, len(files))
+	for _, file := range files {
+		rows = append(rows, fileStats[file])
+	}
+	fitColumns(rows)
+	fitLabels(files)
+
+	printTableHeader(w, "File")
+
+	for _, file := range files {
+		printTableRow(w, file, fileStats[file], "")
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestHotspots(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a.go": lines(1), "old.go": lines(10), "d.go": lines(1)}})
+	r.commit(testCommit{when: "2024-03-01T11:00:00Z", files: map[string]string{"a.go": lines(2), "b.go": lines(50)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a.go": lines(3), "old.go": "", "c.go": lines(11)}})
+	r.commit(testCommit{when: "2024-03-02T11:00:00Z", files: map[string]string{"c.go": lines(12), "d.go": lines(2)}})
+
+	tests := []struct {
+		n    string
+		want [][]string // file, commits, additions, deletions and total changes
+	}{
+		{"10", [][]string{
+			{"a.go", "3", "3", "0", "3"},
+			// The commit renaming old.go counts toward its new name.
+			{"c.go", "2", "2", "0", "2"},
+			{"d.go", "2", "2", "0", "2"},
+			{"b.go", "1", "50", "0", "50"},
+			{"old.go", "1", "10", "0", "10"},
+		}},
+		{"2", [][]string{
+			{"a.go", "3", "3", "0", "3"},
+			{"c.go", "2", "2", "0", "2"},
+		}},
+	}
+	for _, tt := range tests {
+		out := mustRun(t, r.dir, "--hotspots", tt.n, ".", "2024-03-01", "2024-03-02")
+		var rows [][]string
+		for _, line := range strings.Split(out, "\n") {
+			cells := strings.Split(line, "|")
+			if len(cells) != 5 || strings.TrimSpace(cells[0]) == "File" {
+				continue
+			}
+			for i := range cells {
+				cells[i] = strings.TrimSpace(cells[i])
+			}
+			rows = append(rows, cells)
+		}
+		if len(rows) != len(tt.want) {
+			t.Fatalf("--hotspots %s: %d rows, want %d:\n%s", tt.n, len(rows), len(tt.want), out)
+		}
+		for i, row := range rows {
+			if !slices.Equal(row, tt.want[i]) {
+				t.Errorf("--hotspots %s: row %d is %q, want %q", tt.n, i+1, row, tt.want[i])
+			}
+		}
+	}
+
+	if stdout, stderr, code := runGitStat(t, r.dir, "--hotspots", "-1", ".", "2024-03-01", "2024-03-02"); code == 0 || !strings.Contains(stderr, "--hotspots must not be negative") {
+		t.Errorf("--hotspots -1 exited with %d: %s%s", code, stdout, stderr)
+	}
+}
+
+func TestHotspotsPerCommit(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a.go": lines(1), "b.go": lines(9)}})
+	r.commit(testCommit{when: "2024-03-01T11:00:00Z", files: map[string]string{"a.go": lines(4)}})
+
+	out := mustRun(t, r.dir, "--hotspots", "10", "--per-commit", ".", "2024-03-01", "2024-03-01")
+	tests := []struct {
+		label string
+		want  []string
+	}{
+		// The commits are shown once, first, as without --per-commit.
+		{"File", []string{"File", "Commits", "Additions", "Deletions", "Total Changes", "Per Commit"}},
+		{"a.go", []string{"a.go", "2", "4", "0", "4", "2.0"}},
+		{"b.go", []string{"b.go", "1", "9", "0", "9", "9.0"}},
+	}
+	for _, tt := range tests {
+		if row := tableRow(out, tt.label); !slices.Equal(row, tt.want) {
+			t.Errorf("%s: %q, want %q:\n%s", tt.label, row, tt.want, out)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// hourRange is a range of hours of the day, both ends included. A range
+// whose start is after its end wraps around midnight, so 22-2 covers 22:00
+// to 02:59.
+type hourRange struct {
+	From int
+	To   int
+}
+
+// parseHourRange parses the `from-to` value of --hours.
+func parseHourRange(value string) (*hourRange, error) {
+	fromStr, toStr, ok := strings.Cut(value, "-")
+	if !ok {
+		return nil, fmt.Errorf("expected from-to, got %q", value)
+	}
+
+	from, err := strconv.Atoi(strings.TrimSpace(fromStr))
+	if err != nil || from < 0 || from > 23 {
+		return nil, fmt.Errorf("invalid hour %q, expected 0 to 23", fromStr)
+	}
+	to, err := strconv.Atoi(strings.TrimSpace(toStr))
+	if err != nil || to < 0 || to > 23 {
+		return nil, fmt.Errorf("invalid hour %q, expected 0 to 23", toStr)
+	}
+
+	return &hourRange{from, to}, nil
+}
+
+func (r *hourRange) contains(hour int) bool {
+	if r.From <= r.To {
+		return hour >= r.From && hour <= r.To
+	}
+	return hour >= r.From || hour <= r.To
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseHourRange(t *testing.T) {
+	tests := []struct {
+		value    string
+		from, to int
+		err      string
+	}{
+		{"18-23", 18, 23, ""},
+		{" 22 - 2 ", 22, 2, ""},
+		{"0-0", 0, 0, ""},
+		{"18", 0, 0, "expected from-to"},
+		{"18-24", 0, 0, "invalid hour"},
+		{"-1-3", 0, 0, "invalid hour"},
+		{"six-9", 0, 0, "invalid hour"},
+	}
+	for _, tt := range tests {
+		r, err := parseHourRange(tt.value)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("parseHourRange(%q): %v, want an error containing %q", tt.value, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseHourRange(%q): %v", tt.value, err)
+			continue
+		}
+		if r.From != tt.from || r.To != tt.to {
+			t.Errorf("parseHourRange(%q) = %d-%d, want %d-%d", tt.value, r.From, r.To, tt.from, tt.to)
+		}
+	}
+}
+
+func TestHourRangeContains(t *testing.T) {
+	tests := []struct {
+		r     hourRange
+		hours []int
+		want  []bool
+	}{
+		{hourRange{18, 23}, []int{17, 18, 20, 23, 0}, []bool{false, true, true, true, false}},
+		{hourRange{22, 2}, []int{21, 22, 23, 0, 2, 3}, []bool{false, true, true, true, true, false}},
+		{hourRange{9, 9}, []int{8, 9, 10}, []bool{false, true, false}},
+	}
+	for _, tt := range tests {
+		for i, hour := range tt.hours {
+			if got := tt.r.contains(hour); got != tt.want[i] {
+				t.Errorf("%d-%d contains %d = %t, want %t", tt.r.From, tt.r.To, hour, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestHours(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"morning": lines(1)}})
+	r.commit(testCommit{when: "2024-03-01T20:00:00Z", files: map[string]string{"evening": lines(2)}})
+	r.commit(testCommit{when: "2024-03-02T01:00:00+09:00", files: map[string]string{"night": lines(4)}})
+	r.commit(testCommit{when: "2024-03-02T23:30:00-05:00", files: map[string]string{"late": lines(8)}})
+
+	tests := []struct {
+		hours   string
+		changes string
+	}{
+		{"", "15"},
+		{"18-23", "10"},
+		{"22-2", "12"},
+		{"9-11", "1"},
+		{"3-5", "0"},
+	}
+	for _, tt := range tests {
+		args := []string{".", "2024-03-01", "2024-03-03"}
+		if tt.hours != "" {
+			args = append([]string{"--hours", tt.hours}, args...)
+		}
+		if row := tableRow(mustRun(t, r.dir, args...), "Total"); row[4] != tt.changes {
+			t.Errorf("--hours %q: %s changes, want %s", tt.hours, row[4], tt.changes)
+		}
+	}
+
+	if stdout, stderr, code := runGitStat(t, r.dir, "--hours", "18-24", ".", "2024-03-01", "2024-03-02"); code == 0 || !strings.Contains(stdout+stderr, "invalid hour") {
+		t.Errorf("--hours 18-24 exited with %d: %s%s", code, stdout, stderr)
+	}
+}
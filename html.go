@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"io"
+)
+
+// Dimensions of the bar chart of --format html, in pixels.
+const (
+	chartBarWidth = 12
+	chartHeight   = 160
+)
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>git-stat {{.Range}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292f; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #d0d7de; padding: 4px 12px; text-align: center; }
+th { background: #f6f8fa; }
+td.label { text-align: left; }
+tr.total td { font-weight: bold; background: #f6f8fa; }
+.tags { color: #0969da; }
+.additions { fill: #2da44e; }
+.deletions { fill: #cf222e; }
+</style>
+</head>
+<body>
+<h1>{{.Range}}</h1>
+{{if .PrimaryLanguage}}<p>Primary language: {{.PrimaryLanguage}}</p>
+{{end}}{{if .Bars}}<svg width="{{.ChartWidth}}" height="{{.ChartHeight}}" role="img" aria-label="Additions and deletions per day">
+<line x1="0" y1="{{.Middle}}" x2="{{.ChartWidth}}" y2="{{.Middle}}" stroke="#d0d7de"/>
+{{range .Bars}}<g><title>{{.Date}}: +{{.Additions}} -{{.Deletions}}</title>
+<rect class="additions" x="{{.X}}" y="{{.AdditionsY}}" width="{{.Width}}" height="{{.AdditionsHeight}}"/>
+<rect class="deletions" x="{{.X}}" y="{{$.Middle}}" width="{{.Width}}" height="{{.DeletionsHeight}}"/></g>
+{{end}}</svg>
+{{end}}<table>
+<tr><th>Date</th>{{range .Titles}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr><td class="label">{{.Label}}{{if .Tags}} <span class="tags">[{{.Tags}}]</span>{{end}}</td>{{range .Cells}}<td>{{.}}</td>{{end}}</tr>
+{{end}}<tr class="total"><td class="label">Total</td>{{range .Total}}<td>{{.}}</td>{{end}}</tr>
+</table>
+{{if .Summary}}<pre>{{.Summary}}</pre>
+{{end}}</body>
+</html>
+`))
+
+type htmlRow struct {
+	Label string
+	Tags  string
+	Cells []string
+}
+
+type htmlBar struct {
+	Date            string
+	Additions       int
+	Deletions       int
+	X               int
+	Width           int
+	AdditionsY      int
+	AdditionsHeight int
+	DeletionsHeight int
+}
+
+type htmlReport struct {
+	Range           string
+	PrimaryLanguage string
+	Titles          []string
+	Rows            []htmlRow
+	Total           []string
+	Summary         string
+	Bars            []htmlBar
+	ChartWidth      int
+	ChartHeight     int
+	Middle          int
+}
+
+// writeHTML writes the report as a self-contained page: a bar chart of the
+// additions and deletions of every active day above the table, with the
+// same columns as the text table and the summary lines below it. All text
+// is escaped by html/template.
+func writeHTML(w io.Writer, report *Report) error {
+	page := htmlReport{
+		Range:           formatDateRange(report.StartDate, report.EndDate),
+		PrimaryLanguage: report.PrimaryLanguage,
+		ChartHeight:     chartHeight,
+		Middle:          chartHeight / 2,
+	}
+
+	for _, col := range tableColumns {
+		page.Titles = append(page.Titles, col.title)
+	}
+	cells := func(stats *DailyStats) []string {
+		values := make([]string, len(tableColumns))
+		for i, col := range tableColumns {
+			values[i] = col.value(stats)
+		}
+		return values
+	}
+
+	dates := sortedDates(report.DailyStats)
+
+	largest := 0
+	for _, date := range dates {
+		stats := report.DailyStats[date]
+		largest = max(largest, stats.Additions, stats.Deletions)
+	}
+
+	for i, date := range dates {
+		stats := report.DailyStats[date]
+		row := htmlRow{Label: date, Cells: cells(stats)}
+		for j, tag := range report.TagDates[date] {
+			if j > 0 {
+				row.Tags += ", "
+			}
+			row.Tags += tag
+		}
+		page.Rows = append(page.Rows, row)
+
+		if largest > 0 {
+			bar := htmlBar{
+				Date:            date,
+				Additions:       stats.Additions,
+				Deletions:       stats.Deletions,
+				X:               i * chartBarWidth,
+				Width:           chartBarWidth - 2,
+				AdditionsHeight: stats.Additions * page.Middle / largest,
+				DeletionsHeight: stats.Deletions * page.Middle / largest,
+			}
+			bar.AdditionsY = page.Middle - bar.AdditionsHeight
+			page.Bars = append(page.Bars, bar)
+		}
+	}
+	page.ChartWidth = len(page.Bars) * chartBarWidth
+	total := totalStats(report)
+	page.Total = cells(total)
+
+	var summary bytes.Buffer
+	printSummary(&summary, report, total)
+	page.Summary = stripANSI(summary.String())
+
+	return htmlTemplate.Execute(w, page)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestHTML(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-02-28T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(5)}})
+	release := r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(3)}})
+	if err := r.repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewTagReferenceName("v1<b>"), release)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		flags string
+		want  []string
+		not   []string
+	}{
+		{
+			"",
+			[]string{
+				"<title>git-stat 2024-03-01 ~ 03-03</title>",
+				"<tr><th>Date</th><th>Files Changed</th><th>Additions</th><th>Deletions</th><th>Total Changes</th></tr>",
+				"<tr><td class=\"label\">2024-03-01</td><td>1</td><td>4</td><td>0</td><td>4</td></tr>",
+				"<tr><td class=\"label\">2024-03-02</td><td>1</td><td>0</td><td>2</td><td>2</td></tr>",
+				"<tr class=\"total\"><td class=\"label\">Total</td><td>1</td><td>4</td><td>2</td><td>6</td></tr>",
+				// The largest day fills the upper half of the chart.
+				"<title>2024-03-01: +4 -0</title>",
+				"<rect class=\"additions\" x=\"0\" y=\"0\" width=\"10\" height=\"80\"/>",
+				"<rect class=\"deletions\" x=\"0\" y=\"80\" width=\"10\" height=\"0\"/>",
+				"<rect class=\"additions\" x=\"12\" y=\"80\" width=\"10\" height=\"0\"/>",
+				"<rect class=\"deletions\" x=\"12\" y=\"80\" width=\"10\" height=\"40\"/>",
+				"<svg width=\"24\" height=\"160\"",
+			},
+			[]string{"<pre>", "class=\"tags\""},
+		},
+		{
+			"--annotate",
+			[]string{"2024-03-02 <span class=\"tags\">[v1&lt;b&gt;]</span></td>"},
+			[]string{"v1<b>"},
+		},
+		{
+			"--highlights",
+			[]string{"<pre>Most additions: 2024-03-01 (&#43;4)\nMost deletions: 2024-03-02 (-2)\n</pre>"},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		out := mustRun(t, r.dir, append(strings.Fields(tt.flags), "--format", "html", ".", "2024-03-01", "2024-03-03")...)
+		for _, want := range tt.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("%q: want %q in:\n%s", tt.flags, want, out)
+			}
+		}
+		for _, not := range tt.not {
+			if strings.Contains(out, not) {
+				t.Errorf("%q: want no %q in:\n%s", tt.flags, not, out)
+			}
+		}
+	}
+
+	// Without changes there is nothing to chart.
+	if out := mustRun(t, r.dir, "--format", "html", ".", "2024-03-05", "2024-03-06"); strings.Contains(out, "<svg") {
+		t.Errorf("a chart without changes:\n%s", out)
+	}
+}
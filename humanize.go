@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	humanizeNone    = ""
+	humanizeComma   = "comma"
+	humanizeCompact = "compact"
+)
+
+// humanizeStyle controls how counts are printed in the table. JSON and CSV
+// output always use the raw numbers.
+var humanizeStyle = humanizeNone
+
+// humanizeFlag implements --humanize, which may be given without a value to
+// select the comma style.
+type humanizeFlag struct {
+	style *string
+}
+
+func (f humanizeFlag) String() string {
+	if f.style == nil {
+		return ""
+	}
+	return *f.style
+}
+
+func (f humanizeFlag) Set(value string) error {
+	switch value {
+	case "true":
+		*f.style = humanizeComma
+	case "false":
+		*f.style = humanizeNone
+	case humanizeComma, humanizeCompact:
+		*f.style = value
+	default:
+		return fmt.Errorf("unknown style %q, expected %s or %s", value, humanizeComma, humanizeCompact)
+	}
+	return nil
+}
+
+func (f humanizeFlag) IsBoolFlag() bool {
+	return true
+}
+
+// formatCount formats n for the table according to humanizeStyle.
+func formatCount(n int) string {
+	switch humanizeStyle {
+	case humanizeComma:
+		return formatComma(n)
+	case humanizeCompact:
+		return formatCompact(n)
+	}
+	return strconv.Itoa(n)
+}
+
+// formatComma groups the digits of n in thousands, e.g. 1,234,567.
+func formatComma(n int) string {
+	digits := strconv.Itoa(n)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	return sign + b.String()
+}
+
+// formatCompact abbreviates n with a unit suffix, e.g. 1.2M. Numbers below
+// one thousand are printed as is.
+func formatCompact(n int) string {
+	units := []string{"K", "M", "B", "T"}
+
+	value := float64(n)
+	if value < 0 {
+		value = -value
+	}
+	if value < 1000 {
+		return strconv.Itoa(n)
+	}
+
+	unit := ""
+	for _, u := range units {
+		if value < 999.95 {
+			break
+		}
+		value /= 1000
+		unit = u
+	}
+
+	text := strconv.FormatFloat(value, 'f', 1, 64) + unit
+	if n < 0 {
+		text = "-" + text
+	}
+	return text
+}
+
+// precision is the number of decimals of averages, shares and the other
+// computed values, set with --precision. Below zero each value keeps its
+// own number of decimals.
+var precision = -1
+
+// formatFloat formats a computed value with precision decimals, or with
+// digits when --precision is not given.
+func formatFloat(value float64, digits int) string {
+	if precision >= 0 {
+		digits = precision
+	}
+	return strconv.FormatFloat(value, 'f', digits, 64)
+}
+
+// formatSigned is formatFloat with a plus sign in front of positive values.
+func formatSigned(value float64, digits int) string {
+	text := formatFloat(value, digits)
+	if !strings.HasPrefix(text, "-") {
+		text = "+" + text
+	}
+	return text
+}
+
+// formatPercent is formatFloat followed by a percent sign.
+func formatPercent(value float64, digits int) string {
+	return formatFloat(value, digits) + "%"
+}
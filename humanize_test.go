@@ -0,0 +1,128 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatCount(t *testing.T) {
+	defer func(style string) { humanizeStyle = style }(humanizeStyle)
+
+	tests := []struct {
+		style string
+		n     int
+		want  string
+	}{
+		{humanizeNone, 1234567, "1234567"},
+		{humanizeComma, 1234567, "1,234,567"},
+		{humanizeCompact, 1234567, "1.2M"},
+		{humanizeComma, 999, "999"},
+		{humanizeComma, 1000, "1,000"},
+		{humanizeComma, -1234567, "-1,234,567"},
+		{humanizeCompact, 999, "999"},
+		{humanizeCompact, 1500, "1.5K"},
+		{humanizeCompact, 999960, "1.0M"},
+		{humanizeCompact, -1234567, "-1.2M"},
+		{humanizeCompact, 2500000000, "2.5B"},
+	}
+	for _, tt := range tests {
+		humanizeStyle = tt.style
+		if got := formatCount(tt.n); got != tt.want {
+			t.Errorf("formatCount(%d) with %q = %q, want %q", tt.n, tt.style, got, tt.want)
+		}
+	}
+}
+
+func TestHumanizeFlag(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(1234)}})
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, "1234"},
+		{[]string{"--humanize"}, "1,234"},
+		{[]string{"--humanize=comma"}, "1,234"},
+		{[]string{"--humanize=compact"}, "1.2K"},
+	}
+	for _, tt := range tests {
+		args := append(append([]string{}, tt.args...), ".", "2024-03-01", "2024-03-01")
+		if row := tableRow(mustRun(t, r.dir, args...), "Total"); row[2] != tt.want {
+			t.Errorf("%v: %s additions, want %s", tt.args, row[2], tt.want)
+		}
+	}
+
+	for _, format := range []string{"json", "csv"} {
+		out := mustRun(t, r.dir, "--humanize", "--format", format, ".", "2024-03-01", "2024-03-01")
+		if !strings.Contains(out, "1234") || strings.Contains(out, "1,234") {
+			t.Errorf("--humanize changed the numbers of --format %s:\n%s", format, out)
+		}
+	}
+
+	if stdout, _, code := runGitStat(t, r.dir, "--humanize=roman", ".", "2024-03-01", "2024-03-01"); code == 0 || !strings.Contains(stdout, "unknown style") {
+		t.Errorf("--humanize=roman exited with %d: %s", code, stdout)
+	}
+}
+
+func TestFormatFloat(t *testing.T) {
+	defer func(p int) { precision = p }(precision)
+
+	tests := []struct {
+		precision int
+		value     float64
+		digits    int
+		float     string
+		signed    string
+		percent   string
+	}{
+		{-1, 3.14159, 1, "3.1", "+3.1", "3.1%"},
+		{-1, 3.14159, 2, "3.14", "+3.14", "3.14%"},
+		{-1, -2.5, 0, "-2", "-2", "-2%"},
+		{-1, 0, 1, "0.0", "+0.0", "0.0%"},
+		{0, 3.14159, 2, "3", "+3", "3%"},
+		{3, 3.14159, 1, "3.142", "+3.142", "3.142%"},
+		{3, -0.5, 1, "-0.500", "-0.500", "-0.500%"},
+	}
+	for _, tt := range tests {
+		precision = tt.precision
+		if got := formatFloat(tt.value, tt.digits); got != tt.float {
+			t.Errorf("formatFloat(%v, %d) with precision %d = %q, want %q", tt.value, tt.digits, tt.precision, got, tt.float)
+		}
+		if got := formatSigned(tt.value, tt.digits); got != tt.signed {
+			t.Errorf("formatSigned(%v, %d) with precision %d = %q, want %q", tt.value, tt.digits, tt.precision, got, tt.signed)
+		}
+		if got := formatPercent(tt.value, tt.digits); got != tt.percent {
+			t.Errorf("formatPercent(%v, %d) with precision %d = %q, want %q", tt.value, tt.digits, tt.precision, got, tt.percent)
+		}
+	}
+}
+
+func TestPrecision(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-02-28T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(5)}})
+	r.commit(testCommit{when: "2024-03-03T10:00:00Z", files: map[string]string{"b": lines(6)}})
+
+	tests := []struct {
+		flags string
+		want  string
+	}{
+		{"", "Average changes: 5.0 per active day, 3.3 per calendar day\n"},
+		{"--precision 3", "Average changes: 5.000 per active day, 3.333 per calendar day\n"},
+		{"--precision 0", "Average changes: 5 per active day, 3 per calendar day\n"},
+	}
+	for _, tt := range tests {
+		out := mustRun(t, r.dir, append(strings.Fields("--averages "+tt.flags), ".", "2024-03-01", "2024-03-03")...)
+		if !strings.Contains(out, tt.want) {
+			t.Errorf("%q: want %q in:\n%s", tt.flags, tt.want, out)
+		}
+	}
+
+	for _, value := range []string{"-1", "11", "two"} {
+		stdout, stderr, code := runGitStat(t, r.dir, "--precision", value, ".", "2024-03-01", "2024-03-03")
+		if code == 0 || !strings.Contains(stdout+stderr, "expected 0 to 10 decimals") {
+			t.Errorf("--precision %s exited with %d: %s%s", value, code, stdout, stderr)
+		}
+	}
+}
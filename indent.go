@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"io"
+)
+
+// indentWriter prefixes every line written through it, for nesting the
+// report inside a larger log. The prefix goes before any color codes at the
+// start of a line, so it is never colored itself.
+type indentWriter struct {
+	w           io.Writer
+	prefix      []byte
+	atLineStart bool
+}
+
+func newIndentWriter(w io.Writer, spaces int) *indentWriter {
+	return &indentWriter{
+		w:           w,
+		prefix:      bytes.Repeat([]byte(" "), spaces),
+		atLineStart: true,
+	}
+}
+
+func (iw *indentWriter) Write(p []byte) (int, error) {
+	var buf bytes.Buffer
+	for _, b := range p {
+		if iw.atLineStart {
+			buf.Write(iw.prefix)
+			iw.atLineStart = false
+		}
+		buf.WriteByte(b)
+		if b == '\n' {
+			iw.atLineStart = true
+		}
+	}
+
+	if _, err := iw.w.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestIndentWriter(t *testing.T) {
+	tests := []struct {
+		spaces int
+		writes []string
+		want   string
+	}{
+		{2, []string{"a\nb\n"}, "  a\n  b\n"},
+		{2, []string{"a", "b\n", "c\n"}, "  ab\n  c\n"},
+		{1, []string{"\n\n"}, " \n \n"},
+		{3, []string{colorRed + "busy" + colorReset + "\n"}, "   " + colorRed + "busy" + colorReset + "\n"},
+		{0, []string{"a\n"}, "a\n"},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		w := newIndentWriter(&b, tt.spaces)
+		for _, s := range tt.writes {
+			if n, err := w.Write([]byte(s)); n != len(s) || err != nil {
+				t.Errorf("writing %q: %d, %v", s, n, err)
+			}
+		}
+		if b.String() != tt.want {
+			t.Errorf("%q indented by %d: %q, want %q", tt.writes, tt.spaces, b.String(), tt.want)
+		}
+	}
+}
+
+func TestIndent(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(1)}})
+
+	for _, format := range []string{"table", "json", "csv"} {
+		plain := mustRun(t, r.dir, "--format", format, ".", "2024-03-01", "2024-03-03")
+		want := plain
+		if format == "table" {
+			// The banner of the range without commits carries color codes,
+			// which the indentation must precede.
+			want = "    " + strings.ReplaceAll(strings.TrimSuffix(plain, "\n"), "\n", "\n    ") + "\n"
+		}
+		if got := mustRun(t, r.dir, "--indent", "4", "--format", format, ".", "2024-03-01", "2024-03-03"); got != want {
+			t.Errorf("--format %s indented by 4:\n%s\nwant:\n%s", format, got, want)
+		}
+	}
+
+	if stdout, stderr, code := runGitStat(t, r.dir, "--indent", "-1", ".", "2024-03-01", "2024-03-03"); code == 0 || !strings.Contains(stdout+stderr, "must not be negative") {
+		t.Errorf("--indent -1 exited with %d: %s%s", code, stdout, stderr)
+	}
+}
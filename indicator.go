@@ -0,0 +1,29 @@
+package main
+
+// Glyphs shown in front of the day rows with --indicators.
+const (
+	indicatorBusy   = "🔥"
+	indicatorIdle   = "😴"
+	indicatorNormal = "✅"
+)
+
+// dayIndicator returns the glyph for a day with the given stats: busy when
+// its total changes exceed threshold, idle when nothing was committed.
+func dayIndicator(stats *DailyStats, threshold int) string {
+	switch {
+	case stats == nil || stats.Commits == 0:
+		return indicatorIdle
+	case stats.Changes > threshold:
+		return indicatorBusy
+	}
+	return indicatorNormal
+}
+
+// withIndicator puts the indicator glyph in front of a row label, if
+// indicators are shown.
+func withIndicator(label string, stats *DailyStats, opts *Options) string {
+	if !opts.Indicators {
+		return label
+	}
+	return dayIndicator(stats, opts.BusyThreshold) + " " + label
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDayIndicator(t *testing.T) {
+	tests := []struct {
+		stats     *DailyStats
+		threshold int
+		want      string
+	}{
+		{nil, 500, indicatorIdle},
+		{&DailyStats{}, 500, indicatorIdle},
+		{&DailyStats{Commits: 1, Changes: 0}, 500, indicatorNormal},
+		{&DailyStats{Commits: 3, Changes: 500}, 500, indicatorNormal},
+		{&DailyStats{Commits: 3, Changes: 501}, 500, indicatorBusy},
+		{&DailyStats{Commits: 1, Changes: 20}, 10, indicatorBusy},
+	}
+	for _, tt := range tests {
+		if got := dayIndicator(tt.stats, tt.threshold); got != tt.want {
+			t.Errorf("dayIndicator(%+v, %d) = %s, want %s", tt.stats, tt.threshold, got, tt.want)
+		}
+	}
+}
+
+func TestWithIndicator(t *testing.T) {
+	stats := &DailyStats{Commits: 1, Changes: 600}
+	tests := []struct {
+		opts Options
+		want string
+	}{
+		{Options{}, "2024-03-01"},
+		{Options{Indicators: true, BusyThreshold: 500}, indicatorBusy + " 2024-03-01"},
+		{Options{Indicators: true, BusyThreshold: 1000}, indicatorNormal + " 2024-03-01"},
+	}
+	for _, tt := range tests {
+		if got := withIndicator("2024-03-01", stats, &tt.opts); got != tt.want {
+			t.Errorf("withIndicator with %+v = %q, want %q", tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestIndicatorsPiped(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(1)}})
+
+	for _, args := range [][]string{{"--indicators"}, {"--indicators", "--no-emoji"}} {
+		out := mustRun(t, r.dir, append(args, ".", "2024-03-01", "2024-03-02")...)
+		for _, glyph := range []string{indicatorBusy, indicatorIdle, indicatorNormal} {
+			if strings.Contains(out, glyph) {
+				t.Errorf("%v printed %s to a pipe:\n%s", args, glyph, out)
+			}
+		}
+	}
+}
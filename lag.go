@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// reviewLag returns how long after being authored the commit was committed,
+// a rough measure of how long work waits before it lands. Committer dates
+// before the author date, caused by clock skew, are reported as skewed and
+// count as no lag.
+func reviewLag(c *object.Commit) (lag time.Duration, skewed bool) {
+	lag = c.Committer.When.Sub(c.Author.When)
+	if lag < 0 {
+		return 0, true
+	}
+	return lag, false
+}
+
+// printReviewLag prints the average review lag of the commits in total.
+func printReviewLag(w io.Writer, total *DailyStats) {
+	if total.Commits == 0 {
+		return
+	}
+
+	average := total.ReviewLag / time.Duration(total.Commits)
+	fmt.Fprintf(w, "Average review lag: %s", formatDuration(average))
+	if total.ClockSkew == 1 {
+		fmt.Fprint(w, " (1 commit dated before its author date counted as 0)")
+	} else if total.ClockSkew > 1 {
+		fmt.Fprintf(w, " (%d commits dated before their author date counted as 0)", total.ClockSkew)
+	}
+	fmt.Fprintln(w)
+}
+
+// formatDuration formats d rounded to the minute, such as 1d4h30m. Days are
+// used for lags longer than 24 hours.
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Minute {
+		return "0m"
+	}
+
+	var b strings.Builder
+	if days := d / (24 * time.Hour); days > 0 {
+		fmt.Fprintf(&b, "%dd", days)
+		d -= days * 24 * time.Hour
+	}
+	if hours := d / time.Hour; hours > 0 {
+		fmt.Fprintf(&b, "%dh", hours)
+		d -= hours * time.Hour
+	}
+	if minutes := d / time.Minute; minutes > 0 {
+		fmt.Fprintf(&b, "%dm", minutes)
+	}
+	return b.String()
+}
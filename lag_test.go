@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0m"},
+		{29 * time.Second, "0m"},
+		{90 * time.Second, "2m"},
+		{80 * time.Minute, "1h20m"},
+		{3 * time.Hour, "3h"},
+		{28*time.Hour + 30*time.Minute, "1d4h30m"},
+		{48 * time.Hour, "2d"},
+	}
+	for _, tt := range tests {
+		if got := formatDuration(tt.d); got != tt.want {
+			t.Errorf("formatDuration(%s) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestReviewLag(t *testing.T) {
+	authored := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		committed time.Time
+		lag       time.Duration
+		skewed    bool
+	}{
+		{authored, 0, false},
+		{authored.Add(90 * time.Minute), 90 * time.Minute, false},
+		{authored.Add(-time.Hour), 0, true},
+		// The same instant in another time zone is no lag.
+		{authored.In(time.FixedZone("", 2*3600)), 0, false},
+	}
+	for _, tt := range tests {
+		c := &object.Commit{Author: object.Signature{When: authored}, Committer: object.Signature{When: tt.committed}}
+		if lag, skewed := reviewLag(c); lag != tt.lag || skewed != tt.skewed {
+			t.Errorf("committed at %s: %s, %t, want %s, %t", tt.committed, lag, skewed, tt.lag, tt.skewed)
+		}
+	}
+}
+
+func TestPrintReviewLag(t *testing.T) {
+	tests := []struct {
+		total DailyStats
+		want  string
+	}{
+		{DailyStats{}, ""},
+		{DailyStats{Commits: 3, ReviewLag: 4 * time.Hour}, "Average review lag: 1h20m\n"},
+		{DailyStats{Commits: 3, ReviewLag: 4 * time.Hour, ClockSkew: 1}, "Average review lag: 1h20m (1 commit dated before its author date counted as 0)\n"},
+		{DailyStats{Commits: 2, ClockSkew: 2}, "Average review lag: 0m (2 commits dated before their author date counted as 0)\n"},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		printReviewLag(&b, &tt.total)
+		if b.String() != tt.want {
+			t.Errorf("%+v: %q, want %q", tt.total, b.String(), tt.want)
+		}
+	}
+}
+
+func TestReviewLagFlag(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", committed: "2024-03-01T11:00:00Z", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-01T12:00:00Z", committed: "2024-03-01T15:00:00+00:00", files: map[string]string{"a": lines(2)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", committed: "2024-03-02T08:00:00Z", files: map[string]string{"a": lines(3)}})
+
+	out := mustRun(t, r.dir, "--review-lag", ".", "2024-03-01", "2024-03-02")
+	if want := "Average review lag: 1h20m (1 commit dated before its author date counted as 0)\n"; !strings.Contains(out, want) {
+		t.Errorf("output lacks %q:\n%s", want, out)
+	}
+}
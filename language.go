@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+const otherLanguage = "Other"
+
+// defaultLanguages maps file extensions (and a few well-known file names) to
+// the language they are written in.
+var defaultLanguages = map[string]string{
+	".go":            "Go",
+	".py":            "Python",
+	".js":            "JavaScript",
+	".jsx":           "JavaScript",
+	".mjs":           "JavaScript",
+	".ts":            "TypeScript",
+	".tsx":           "TypeScript",
+	".java":          "Java",
+	".kt":            "Kotlin",
+	".scala":         "Scala",
+	".c":             "C",
+	".h":             "C",
+	".cc":            "C++",
+	".cpp":           "C++",
+	".hpp":           "C++",
+	".cs":            "C#",
+	".m":             "Objective-C",
+	".swift":         "Swift",
+	".rb":            "Ruby",
+	".rs":            "Rust",
+	".php":           "PHP",
+	".pl":            "Perl",
+	".lua":           "Lua",
+	".r":             "R",
+	".dart":          "Dart",
+	".ex":            "Elixir",
+	".exs":           "Elixir",
+	".erl":           "Erlang",
+	".hs":            "Haskell",
+	".vue":           "Vue",
+	".sh":            "Shell",
+	".bash":          "Shell",
+	".sql":           "SQL",
+	".html":          "HTML",
+	".css":           "CSS",
+	".scss":          "SCSS",
+	".md":            "Markdown",
+	".json":          "JSON",
+	".yml":           "YAML",
+	".yaml":          "YAML",
+	".toml":          "TOML",
+	".xml":           "XML",
+	".proto":         "Protocol Buffers",
+	"Makefile":       "Makefile",
+	"Dockerfile":     "Dockerfile",
+	"Rakefile":       "Ruby",
+	"Gemfile":        "Ruby",
+	"CMakeLists.txt": "CMake",
+}
+
+// loadLanguageMap reads a mapping file with one `<extension> = <language>`
+// entry per line and merges it over the built-in table. Blank lines and lines
+// starting with `#` are ignored.
+func loadLanguageMap(filename string) (map[string]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	languages := make(map[string]string, len(defaultLanguages))
+	for ext, lang := range defaultLanguages {
+		languages[ext] = lang
+	}
+
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		ext, lang, ok := strings.Cut(line, "=")
+		ext, lang = strings.TrimSpace(ext), strings.TrimSpace(lang)
+		if !ok || ext == "" || lang == "" {
+			return nil, fmt.Errorf("%s:%d: expected `<extension> = <language>`", filename, lineNo)
+		}
+
+		if strings.HasPrefix(ext, ".") {
+			ext = strings.ToLower(ext)
+		}
+		languages[ext] = lang
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return languages, nil
+}
+
+// languageOf returns the language of the file at the given path. The file
+// name is looked up first so that entries like `Makefile` win over the
+// extension.
+func languageOf(filename string, languages map[string]string) string {
+	base := path.Base(filename)
+	if lang, ok := languages[base]; ok {
+		return lang
+	}
+	if lang, ok := languages[strings.ToLower(path.Ext(base))]; ok {
+		return lang
+	}
+	return otherLanguage
+}
+
+// primaryLanguage returns the language with the most changed files, ties
+// going to the alphabetically first. Files of unknown languages only count
+// when there is nothing else.
+func primaryLanguage(total *DailyStats, languages map[string]string) string {
+	counts := make(map[string]int)
+	for file := range total.FilesChanged {
+		counts[languageOf(file, languages)]++
+	}
+
+	primary := ""
+	for lang, n := range counts {
+		if lang == otherLanguage && len(counts) > 1 {
+			continue
+		}
+		if primary == "" || n > counts[primary] || n == counts[primary] && lang < primary {
+			primary = lang
+		}
+	}
+	return primary
+}
+
+func getLanguageStats(repo *git.Repository, startDate, endDate time.Time, opts *Options, languages map[string]string) (map[string]*DailyStats, error) {
+	return getGroupStats(repo, startDate, endDate, opts, func(c *object.Commit, stat object.FileStat) string {
+		return languageOf(stat.Name, languages)
+	})
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLanguageOf(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"main.go", "Go"},
+		{"cmd/tool/main.go", "Go"},
+		{"script.py", "Python"},
+		{"WEIRD.PY", "Python"},
+		{"build/Makefile", "Makefile"},
+		{"notes.unknown", otherLanguage},
+		{"LICENSE", otherLanguage},
+	}
+	for _, tt := range tests {
+		if got := languageOf(tt.name, defaultLanguages); got != tt.want {
+			t.Errorf("languageOf(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestByLanguage(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{
+		"main.go":    lines(3),
+		"util.go":    lines(2),
+		"tool.py":    lines(4),
+		"data.weird": lines(1),
+		"languages":  ".weird = Weird\n.py = Snake\n",
+	}})
+
+	out := mustRun(t, r.dir, "--by-language", ".", "2024-03-01", "2024-03-01")
+	tests := []struct {
+		language  string
+		additions string
+	}{
+		{"Go", "5"},
+		{"Python", "4"},
+		{otherLanguage, "3"},
+	}
+	for _, tt := range tests {
+		row := tableRow(out, tt.language)
+		if row == nil {
+			t.Fatalf("no row for %s in:\n%s", tt.language, out)
+		}
+		if row[2] != tt.additions {
+			t.Errorf("%s has %s additions, want %s", tt.language, row[2], tt.additions)
+		}
+	}
+
+	out = mustRun(t, r.dir, "--by-language", "--languages", filepath.Join(r.dir, "languages"), ".", "2024-03-01", "2024-03-01")
+	for _, language := range []string{"Go", "Snake", "Weird"} {
+		if tableRow(out, language) == nil {
+			t.Errorf("no row for %s with --languages in:\n%s", language, out)
+		}
+	}
+	if tableRow(out, "Python") != nil {
+		t.Errorf("--languages did not override .py:\n%s", out)
+	}
+}
+
+func TestLoadLanguageMapErrors(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "languages")
+	if err := os.WriteFile(file, []byte("# comment\n\n.go Go\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadLanguageMap(file); err == nil {
+		t.Error("a line without = was accepted")
+	}
+}
+
+func TestPrimaryLanguage(t *testing.T) {
+	tests := []struct {
+		files []string
+		want  string
+	}{
+		{[]string{"main.go", "util.go", "README.md"}, "Go"},
+		{[]string{"a.py", "b.go"}, "Go"},
+		{[]string{"a.py", "b.py", "c.go", "LICENSE", "NOTICE", "COPYING"}, "Python"},
+		{[]string{"LICENSE"}, otherLanguage},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		total := &DailyStats{FilesChanged: make(map[string]struct{})}
+		for _, file := range tt.files {
+			total.FilesChanged[file] = struct{}{}
+		}
+		if got := primaryLanguage(total, defaultLanguages); got != tt.want {
+			t.Errorf("primaryLanguage(%v) = %q, want %q", tt.files, got, tt.want)
+		}
+	}
+}
+
+func TestPrimaryLanguageHeader(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{
+		"main.go": lines(1), "cmd/run.go": lines(1), "README.md": lines(40),
+	}})
+
+	tests := []struct {
+		flag  string
+		first string
+	}{
+		{"", "Primary language: Go"},
+		{"--primary-language=false", "Date Range"},
+	}
+	for _, tt := range tests {
+		args := []string{".", "2024-03-01", "2024-03-01"}
+		if tt.flag != "" {
+			args = append([]string{tt.flag}, args...)
+		}
+		first, _, _ := strings.Cut(mustRun(t, r.dir, args...), "\n")
+		if strings.TrimSpace(strings.Split(first, "|")[0]) != tt.first {
+			t.Errorf("%q: the output starts with %q, want %q", tt.flag, first, tt.first)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/gitattributes"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// linguistAttributes are the .gitattributes markers GitHub's language stats
+// use to leave files out.
+var linguistAttributes = []string{"linguist-generated", "linguist-vendored"}
+
+// loadLinguistMatcher reads the .gitattributes files of the commit at from,
+// or returns nil when none of them mentions a linguist marker. The attributes
+// of that one commit apply to the whole history, as they do on GitHub.
+func loadLinguistMatcher(repo *git.Repository, from plumbing.Hash) (gitattributes.Matcher, error) {
+	commit, err := repo.CommitObject(from)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the names of the files are walked, so no blob other than those of
+	// the .gitattributes files is read.
+	var files []*object.File
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, err := walker.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !entry.Mode.IsFile() || path.Base(name) != ".gitattributes" {
+			continue
+		}
+		f, err := tree.File(name)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	// Deeper files take precedence, so they go last.
+	sort.SliceStable(files, func(i, j int) bool {
+		return strings.Count(files[i].Name, "/") < strings.Count(files[j].Name, "/")
+	})
+
+	var stack []gitattributes.MatchAttribute
+	for _, f := range files {
+		content, err := f.Contents()
+		if err != nil {
+			return nil, err
+		}
+		if !strings.Contains(content, "linguist-") {
+			continue
+		}
+
+		var domain []string
+		if dir := path.Dir(f.Name); dir != "." {
+			domain = strings.Split(dir, "/")
+		}
+		attributes, err := gitattributes.ReadAttributes(strings.NewReader(content), domain, f.Name == ".gitattributes")
+		if err != nil {
+			warnf("ignoring %s: %v", f.Name, err)
+			continue
+		}
+		stack = append(stack, attributes...)
+	}
+
+	if len(stack) == 0 {
+		return nil, nil
+	}
+	return gitattributes.NewMatcher(stack), nil
+}
+
+// isLinguistExcluded reports whether name is marked as generated or vendored.
+func isLinguistExcluded(matcher gitattributes.Matcher, name string) bool {
+	parts := strings.Split(name, "/")
+	for _, attribute := range linguistAttributes {
+		// Asking for one attribute at a time gives the last matching line.
+		results, _ := matcher.Match(parts, []string{attribute})
+		attr, ok := results[attribute]
+		if !ok {
+			continue
+		}
+		if attr.IsSet() || attr.IsValueSet() && attr.Value() != "false" {
+			return true
+		}
+	}
+	return false
+}
+
+// filterLinguist drops the stats of generated and vendored files.
+func filterLinguist(stats object.FileStats, matcher gitattributes.Matcher) object.FileStats {
+	var filtered object.FileStats
+	for _, stat := range stats {
+		if _, to := splitRename(stat.Name); !isLinguistExcluded(matcher, to) {
+			filtered = append(filtered, stat)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,80 @@
+package main
+
+import "testing"
+
+func TestIsLinguistExcluded(t *testing.T) {
+	r := newTestRepo(t)
+	head := r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{
+		".gitattributes":     "*.pb.go linguist-generated=true\nvendor/** linguist-vendored\ndocs/** linguist-documentation\n",
+		"web/.gitattributes": "dist/** linguist-generated\nkeep.pb.go linguist-generated=false\n",
+		"main.go":            lines(1),
+	}})
+
+	matcher, err := loadLinguistMatcher(r.repo, head)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if matcher == nil {
+		t.Fatal("no matcher for .gitattributes with linguist markers")
+	}
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"main.go", false},
+		{"api/service.pb.go", true},
+		{"vendor/lib/lib.go", true},
+		{"docs/guide.md", false},
+		{"web/dist/app.js", true},
+		{"dist/app.js", false},
+		{"web/keep.pb.go", false},
+		{"web/other.pb.go", true},
+	}
+	for _, tt := range tests {
+		if got := isLinguistExcluded(matcher, tt.name); got != tt.want {
+			t.Errorf("isLinguistExcluded(%q) = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+
+	plain := r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{
+		".gitattributes":     "*.sh text eol=lf\n",
+		"web/.gitattributes": "", // removed
+	}})
+	if matcher, err := loadLinguistMatcher(r.repo, plain); err != nil || matcher != nil {
+		t.Errorf("loadLinguistMatcher without linguist markers = %v, %v, want nil", matcher, err)
+	}
+}
+
+func TestLinguist(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{
+		".gitattributes": "gen/** linguist-generated=true\n",
+		"main.go":        lines(2),
+		"gen/api.go":     lines(100),
+	}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"gen/api.go": lines(150)}})
+
+	tests := []struct {
+		flag    string
+		files   string
+		changes string
+	}{
+		{"", "2", "3"},
+		{"--linguist=false", "3", "153"},
+	}
+	for _, tt := range tests {
+		args := []string{".", "2024-03-01", "2024-03-02"}
+		if tt.flag != "" {
+			args = append([]string{tt.flag}, args...)
+		}
+		out := mustRun(t, r.dir, args...)
+		if row := tableRow(out, "Total"); row[1] != tt.files || row[4] != tt.changes {
+			t.Errorf("%q: %s files and %s changes, want %s and %s:\n%s", tt.flag, row[1], row[4], tt.files, tt.changes, out)
+		}
+		// The day only changing generated files has no commits left.
+		if row := tableRow(out, "2024-03-02"); tt.flag == "" && row != nil {
+			t.Errorf("%q: 2024-03-02 is counted: %v", tt.flag, row)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+// localeNames holds the names of the weekdays in one language, starting
+// with Sunday like time.Weekday.
+type localeNames [7]string
+
+// supportedLocales lists the languages weekday names are available in; the
+// first one is the fallback. Months are only ever shown as numbers, in ISO
+// dates and period labels, so they need no names.
+var supportedLocales = []language.Tag{
+	language.English,
+	language.French,
+	language.German,
+	language.Spanish,
+	language.Italian,
+	language.Portuguese,
+	language.Dutch,
+}
+
+var localeTable = map[language.Tag]localeNames{
+	language.English:    {"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+	language.French:     {"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+	language.German:     {"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+	language.Spanish:    {"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+	language.Italian:    {"domenica", "lunedì", "martedì", "mercoledì", "giovedì", "venerdì", "sabato"},
+	language.Portuguese: {"domingo", "segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado"},
+	language.Dutch:      {"zondag", "maandag", "dinsdag", "woensdag", "donderdag", "vrijdag", "zaterdag"},
+}
+
+// names is the language used for weekday names, set by --locale.
+var names = localeTable[language.English]
+
+// setLocale selects the language matching locale, such as "fr" or "fr-CA".
+// Unsupported locales fall back to English with a warning.
+func setLocale(locale string) {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		warnf("invalid locale %q, using English", locale)
+		return
+	}
+
+	_, index, confidence := language.NewMatcher(supportedLocales).Match(tag)
+	if confidence == language.No {
+		warnf("locale %q is not supported, using English", locale)
+		return
+	}
+
+	names = localeTable[supportedLocales[index]]
+}
+
+func weekdayName(day time.Weekday) string {
+	return names[day]
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetLocale(t *testing.T) {
+	defer func(saved localeNames) { names = saved }(names)
+	defer func(level logLevel) { currentLogLevel = level }(currentLogLevel)
+	currentLogLevel = levelError // no warnings about the fallbacks
+
+	tests := []struct {
+		locale string
+		monday string
+	}{
+		{"fr", "lundi"},
+		{"fr-CA", "lundi"},
+		{"de-DE", "Montag"},
+		{"es", "lunes"},
+		{"en-GB", "Monday"},
+		{"ja", "Monday"},
+		{"not a locale", "Monday"},
+	}
+	for _, tt := range tests {
+		names = localeTable[supportedLocales[0]]
+		setLocale(tt.locale)
+		if got := weekdayName(time.Monday); got != tt.monday {
+			t.Errorf("Monday in %q is %q, want %q", tt.locale, got, tt.monday)
+		}
+	}
+}
+
+func TestLocaleFlag(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-04T10:00:00Z", files: map[string]string{"a": lines(1)}}) // a Monday
+
+	tests := []struct {
+		locale  string
+		row     string
+		warning string
+	}{
+		{"fr", "lundi", ""},
+		{"de", "Montag", ""},
+		{"ja", "Monday", `locale "ja" is not supported, using English`},
+	}
+	for _, tt := range tests {
+		stdout, stderr, code := runGitStat(t, r.dir, "--by-weekday", "--locale", tt.locale, ".", "2024-03-04", "2024-03-04")
+		if code != 0 {
+			t.Fatalf("--locale %s exited with %d: %s", tt.locale, code, stderr)
+		}
+		if row := tableRow(stdout, tt.row); row == nil || row[4] != "1" {
+			t.Errorf("--locale %s: row %s is %q:\n%s", tt.locale, tt.row, row, stdout)
+		}
+		if tt.warning == "" && stderr != "" || !strings.Contains(stderr, tt.warning) {
+			t.Errorf("--locale %s: stderr %q, want %q", tt.locale, stderr, tt.warning)
+		}
+	}
+}
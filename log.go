@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// logLevel selects which diagnostics are written to stderr. The report
+// itself always goes to stdout.
+type logLevel int
+
+const (
+	levelError logLevel = iota
+	levelWarn
+	levelInfo
+	levelDebug
+)
+
+var currentLogLevel = levelInfo
+
+func logf(level logLevel, prefix, format string, args ...any) {
+	if level > currentLogLevel {
+		return
+	}
+	fmt.Fprintf(os.Stderr, prefix+format+"\n", args...)
+}
+
+func errorf(format string, args ...any) {
+	logf(levelError, "", format, args...)
+}
+
+func warnf(format string, args ...any) {
+	logf(levelWarn, "Warning: ", format, args...)
+}
+
+func infof(format string, args ...any) {
+	logf(levelInfo, "", format, args...)
+}
+
+func debugf(format string, args ...any) {
+	logf(levelDebug, "Debug: ", format, args...)
+}
+
+// fatalf logs an error and exits.
+func fatalf(format string, args ...any) {
+	errorf(format, args...)
+	os.Exit(1)
+}
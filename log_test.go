@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLogLevels(t *testing.T) {
+	r := newTestRepo(t)
+	first := r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(2)}})
+
+	// The run warns about its end date, reports the excluded commit as
+	// information and says which commits it skipped when debugging.
+	args := []string{"--exclude-commit", first.String(), ".", "2024-03-01", "2999-01-01"}
+	want := mustRun(t, r.dir, args...)
+
+	tests := []struct {
+		flag    string
+		want    []string
+		notWant []string
+	}{
+		{"", []string{"Warning: end date", "Excluded 1 commits"}, []string{"Debug:"}},
+		{"--verbose", []string{"Warning: end date", "Excluded 1 commits", "Debug: walking commits from master", "Debug: walked 2 commits"}, nil},
+		{"--quiet", nil, []string{"Warning:", "Excluded", "Debug:"}},
+	}
+	for _, tt := range tests {
+		runArgs := args
+		if tt.flag != "" {
+			runArgs = append([]string{tt.flag}, args...)
+		}
+		stdout, stderr, code := runGitStat(t, r.dir, runArgs...)
+		if code != 0 {
+			t.Fatalf("%q exited with %d: %s", tt.flag, code, stderr)
+		}
+		if stdout != want {
+			t.Errorf("%q changed the report:\n%s\nwant:\n%s", tt.flag, stdout, want)
+		}
+		for _, s := range tt.want {
+			if !strings.Contains(stderr, s) {
+				t.Errorf("%q: stderr lacks %q:\n%s", tt.flag, s, stderr)
+			}
+		}
+		for _, s := range tt.notWant {
+			if strings.Contains(stderr, s) {
+				t.Errorf("%q: stderr has %q:\n%s", tt.flag, s, stderr)
+			}
+		}
+	}
+
+	if _, stderr, code := runGitStat(t, r.dir, "--quiet", "missing", "2024-03-01", "2024-03-02"); code == 0 || !strings.Contains(stderr, "Error opening repository") {
+		t.Errorf("--quiet hid the error of a missing repository, exiting with %d: %s", code, stderr)
+	}
+}
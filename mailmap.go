@@ -0,0 +1,120 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// mailmapEntry maps the name and email a commit was made with to the proper
+// ones. An empty commitName matches any name.
+type mailmapEntry struct {
+	properName  string
+	properEmail string
+	commitName  string
+	commitEmail string
+}
+
+// mailmap is the .mailmap of a repository.
+type mailmap []mailmapEntry
+
+// loadMailmap reads the .mailmap file of the commit at from. A repository
+// without one gets a nil mailmap, which leaves every signature as it is.
+func loadMailmap(repo *git.Repository, from plumbing.Hash) (mailmap, error) {
+	commit, err := repo.CommitObject(from)
+	if err != nil {
+		return nil, err
+	}
+	file, err := commit.File(".mailmap")
+	if err == object.ErrFileNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return nil, err
+	}
+	return parseMailmap(content), nil
+}
+
+// parseMailmap parses the lines of a .mailmap in any of the forms
+// described in gitmailmap(5):
+//
+//	Proper Name <commit@email>
+//	<proper@email> <commit@email>
+//	Proper Name <proper@email> <commit@email>
+//	Proper Name <proper@email> Commit Name <commit@email>
+func parseMailmap(content string) mailmap {
+	var m mailmap
+
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+
+		var names, emails []string
+		rest := line
+		for len(emails) < 2 {
+			open := strings.Index(rest, "<")
+			end := strings.Index(rest, ">")
+			if open < 0 || end < open {
+				break
+			}
+			names = append(names, strings.TrimSpace(rest[:open]))
+			emails = append(emails, strings.ToLower(strings.TrimSpace(rest[open+1:end])))
+			rest = rest[end+1:]
+		}
+
+		switch len(emails) {
+		case 1:
+			if names[0] != "" {
+				m = append(m, mailmapEntry{properName: names[0], commitEmail: emails[0]})
+			}
+		case 2:
+			m = append(m, mailmapEntry{
+				properName:  names[0],
+				properEmail: emails[0],
+				commitName:  names[1],
+				commitEmail: emails[1],
+			})
+		}
+	}
+
+	return m
+}
+
+// apply returns sig with the proper name and email. Entries naming the
+// commit name as well win over those matching the email alone; both are
+// compared ignoring case, as git does.
+func (m mailmap) apply(sig object.Signature) object.Signature {
+	email := strings.ToLower(sig.Email)
+
+	var match *mailmapEntry
+	for i := range m {
+		entry := &m[i]
+		if entry.commitEmail != email {
+			continue
+		}
+		if strings.EqualFold(entry.commitName, sig.Name) {
+			match = entry
+			break
+		}
+		if entry.commitName == "" {
+			match = entry
+		}
+	}
+
+	if match != nil {
+		if match.properName != "" {
+			sig.Name = match.properName
+		}
+		if match.properEmail != "" {
+			sig.Email = match.properEmail
+		}
+	}
+	return sig
+}
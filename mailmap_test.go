@@ -0,0 +1,63 @@
+package main
+
+import "testing"
+
+func TestMailmap(t *testing.T) {
+	m := parseMailmap(`# comment
+Alice Smith <alice@example.com>
+<bob@example.com> <bob@old.example.com>
+Carol Jones <carol@example.com> <cj@example.com>
+Dave <dave@example.com> dave <DAVE@laptop.local>
+Dave Other <other@example.com> <dave@laptop.local>
+<no-name@example.com>
+`)
+
+	tests := []struct {
+		author string
+		want   string
+	}{
+		{"alice <alice@example.com>", "Alice Smith <alice@example.com>"},
+		{"alice <ALICE@example.com>", "Alice Smith <ALICE@example.com>"},
+		{"Bob <bob@old.example.com>", "Bob <bob@example.com>"},
+		{"cj <cj@example.com>", "Carol Jones <carol@example.com>"},
+		{"Dave <dave@laptop.local>", "Dave <dave@example.com>"},
+		{"David <dave@laptop.local>", "Dave Other <other@example.com>"},
+		{"Nobody <no-name@example.com>", "Nobody <no-name@example.com>"},
+		{"Eve <eve@example.com>", "Eve <eve@example.com>"},
+	}
+	for _, tt := range tests {
+		sig := m.apply(parseTestAuthor(tt.author))
+		if got := sig.Name + " <" + sig.Email + ">"; got != tt.want {
+			t.Errorf("apply(%s) = %s, want %s", tt.author, got, tt.want)
+		}
+	}
+
+	if sig := mailmap(nil).apply(parseTestAuthor("Eve <eve@example.com>")); sig.Name != "Eve" || sig.Email != "eve@example.com" {
+		t.Errorf("a nil mailmap changed the signature to %v", sig)
+	}
+}
+
+func TestAuthorsColumn(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", author: "Alice <alice@example.com>", files: map[string]string{".mailmap": "Alice <alice@example.com> <alice@laptop.local>\n"}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", author: "Alice <alice@example.com>", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-02T11:00:00Z", author: "Bob <bob@example.com>", files: map[string]string{"a": lines(2)}})
+	r.commit(testCommit{when: "2024-03-03T10:00:00Z", author: "Alice <alice@example.com>", files: map[string]string{"a": lines(3)}})
+	r.commit(testCommit{when: "2024-03-03T11:00:00Z", author: "alice <alice@laptop.local>", files: map[string]string{"a": lines(4)}})
+
+	out := mustRun(t, r.dir, "--authors", ".", "2024-03-01", "2024-03-03")
+	tests := []struct {
+		label   string
+		authors string
+	}{
+		{"2024-03-01", "1"},
+		{"2024-03-02", "2"},
+		{"2024-03-03", "1"}, // the same person, after the .mailmap
+		{"Total", "2"},
+	}
+	for _, tt := range tests {
+		if row := tableRow(out, tt.label); row == nil || row[5] != tt.authors {
+			t.Errorf("%s: %v, want %s authors:\n%s", tt.label, row, tt.authors, out)
+		}
+	}
+}
@@ -1,13 +1,23 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/gitattributes"
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
@@ -15,69 +25,759 @@ type DailyStats struct {
 	FilesChanged map[string]struct{}
 	Additions    int
 	Deletions    int
+	Commits      int
+
+	// Changes is the total change of the files, counted as set by
+	// --churn-mode.
+	Changes int
+
+	// Hours counts the commits made in each hour of the day, in the
+	// author's time zone.
+	Hours [24]int
+
+	// ReviewLag adds up the time between author and committer date of the
+	// commits, ClockSkew counts those committed before they were authored.
+	ReviewLag time.Duration
+	ClockSkew int
+
+	// TestAdditions and TestDeletions are the part of the additions and
+	// deletions made to test files, counted with --split-tests.
+	TestAdditions int
+	TestDeletions int
+
+	// Authors holds the authors of the commits, named as in .mailmap.
+	Authors map[string]struct{}
+
+	// Bytes adds up by how many bytes the files grew or shrank, counted
+	// with --bytes.
+	Bytes int
+
+	// Reverts counts the revert commits, RevertChanges the part of Changes
+	// they make up.
+	Reverts       int
+	RevertChanges int
+
+	// Times holds the author times of the commits, kept for --velocity.
+	Times []time.Time
+
+	// NetFiles is the number of files added minus the number deleted,
+	// counted with --net-files.
+	NetFiles int
+
+	// AfterHours and WeekendCommits count the commits made outside the
+	// working hours of a weekday and on weekends, for --after-hours-report.
+	AfterHours     int
+	WeekendCommits int
+
+	// Files holds the lines added to and deleted from every file, kept
+	// for --verbose-files.
+	Files map[string]fileChurn
+
+	// Explained holds the commits of the day given to --explain.
+	Explained []explainedCommit
+
+	// AgeWeighted adds up the changes weighted by the age of their files,
+	// counted with --age-weighted.
+	AgeWeighted float64
 }
 
-const (
-	colorReset  = "\033[0m"
-	colorOrange = "\033[38;5;208m"
-	colorCyan   = "\033[36m"
-)
+// Report holds the computed statistics handed to the output renderers.
+type Report struct {
+	StartDate  time.Time
+	EndDate    time.Time
+	DailyStats map[string]*DailyStats
+	TagDates   map[string][]string
+	Options    *Options
+
+	// PrimaryLanguage is the language most of the changed files are
+	// written in, if known.
+	PrimaryLanguage string
+}
+
+type Options struct {
+	Annotate      bool
+	ByLanguage    bool
+	LanguagesFile string
+	NoMerges      bool
+	MergesOnly    bool
+
+	IncludeEmptyCommits bool
+	PerCommit           bool
+	Format              string
+	OutputDir           string
+	Branch              string
+	OnlyActiveDays      bool
+	ByType              bool
+	CommitTypes         []string
+	ClampFuture         bool
+	ByAuthor            bool
+	AuthorEmailOnly     bool
+	AuthorPercentage    bool
+	Anonymize           bool
+	ExclusiveEnd        bool
+	Validate            bool
+	Indicators          bool
+	BusyThreshold       int
+	NoEmoji             bool
+	DiffFile            string
+	Paths               []string
+	PathRenames         string
+	Quiet               bool
+	Verbose             bool
+	Verify              bool
+	MaxFilesPerCommit   int
+	ByWeekday           bool
+	Locale              string
+	FileCount           bool
+	Period              string
+	DedupeAcrossDays    bool
+	Indent              int
+	PrimaryLanguage     bool
+	CommitsTable        bool
+	ExcludeRanges       []period
+	PeakHour            bool
+	Output              string
+	ChurnMode           string
+	Style               string
+	ReviewLag           bool
+	CommitURL           string
+	Streaks             bool
+	TopDays             int
+	Hours               *hourRange
+	Trend               bool
+	SplitTests          bool
+	TestPatterns        []string
+	RenameThreshold     int
+	Highlights          bool
+	ConfigPrint         bool
+	File                string
+	Follow              bool
+	BatchRepos          bool
+	SinceCommit         string
+	Authors             bool
+	ExcludeCommits      []string
+	RelativeWeeks       bool
+	MinAdditions        int
+	MinDeletions        int
+	Bytes               bool
+	Width               int
+	ByTeam              bool
+	TeamsFile           string
+	Clipboard           bool
+	ResumeGap           int
+	Hotspots            int
+	AddWeight           float64
+	DelWeight           float64
+	Header              bool
+	Append              bool
+	Ref                 string
+	Reverts             bool
+	ExcludeReverts      bool
+	Averages            bool
+	TZOffset            *time.Duration
+	TZTolerance         time.Duration
+	Velocity            bool
+	SessionGap          time.Duration
+	ChartSize           chartSize
+	NetFiles            bool
+	Checkpoint          string
+	Resume              bool
+	Anomalies           bool
+	IncludeAuthors      []string
+	ShowDelta           bool
+	AfterHoursReport    bool
+	WorkHours           *hourRange
+	CommitsFile         string
+	Strict              bool
+	NormalizeEOL        bool
+	VerboseFiles        bool
+	SummaryMarker       bool
+	Presets             []string
+	PresetsFile         string
+	StatsTimeout        time.Duration
+	ByMerge             bool
+	Explain             string
+	Tee                 bool
+	Arrows              bool
+	AgeWeighted         bool
+
+	IncludeInitialCommit bool
+	Linguist             bool
+
+	// CommitFilter, when set, is called for every commit in the date range
+	// before its changes are computed. Returning false skips the commit.
+	// Filters are added with addCommitFilter, which keeps those set before.
+	CommitFilter func(c *object.Commit) bool `json:"-"`
+
+	// PathPatterns are the patterns of the --preset presets, which files
+	// must match to be counted.
+	PathPatterns []string `json:"-"`
+
+	// Commits, when set, are walked instead of the commits in the date
+	// range of the branch.
+	Commits []*object.Commit `json:"-"`
+
+	// Stop, when set, is called before every commit walked, whether it is
+	// counted or not. An error ends the walk with it.
+	Stop func() error `json:"-"`
+}
+
+// addCommitFilter adds keep to the CommitFilter hook. A commit is only
+// counted when every filter added keeps it; they are asked in the order
+// they were added.
+func (opts *Options) addCommitFilter(keep func(c *object.Commit) bool) {
+	previous := opts.CommitFilter
+	if previous == nil {
+		opts.CommitFilter = keep
+		return
+	}
+	opts.CommitFilter = func(c *object.Commit) bool {
+		return previous(c) && keep(c)
+	}
+}
+
+func (opts *Options) validate() error {
+	if opts.NoMerges && opts.MergesOnly {
+		return errors.New("--no-merges and --merges-only cannot be used together")
+	}
+	modes := 0
+	for _, mode := range []bool{opts.ByLanguage, opts.ByType, opts.ByAuthor, opts.ByWeekday, opts.FileCount, opts.CommitsTable, opts.SplitTests, opts.BatchRepos, opts.ByTeam, opts.Hotspots > 0, opts.AfterHoursReport, opts.ByMerge} {
+		if mode {
+			modes++
+		}
+	}
+	if modes > 1 {
+		return errors.New("only one of --by-language, --by-type, --by-author, --by-team, --by-weekday, --file-count, --commits-table, --split-tests, --batch-repos, --hotspots, --after-hours-report and --by-merge can be used")
+	}
+	if opts.Follow && opts.File == "" {
+		return errors.New("--follow needs --file")
+	}
+	if opts.Ref != "" && opts.Branch != "" {
+		return errors.New("--ref and --branch cannot be used together")
+	}
+	if opts.SinceCommit != "" && opts.BatchRepos {
+		return errors.New("--since-commit cannot be used with --batch-repos")
+	}
+	if opts.CommitsFile != "" && (opts.SinceCommit != "" || opts.BatchRepos || opts.Verify) {
+		return errors.New("--commits-file cannot be used with --since-commit, --batch-repos or --verify")
+	}
+	if opts.PresetsFile != "" && len(opts.Presets) == 0 {
+		return errors.New("--presets needs --preset")
+	}
+	if opts.Strict && opts.CommitsFile == "" {
+		return errors.New("--strict needs --commits-file")
+	}
+	if opts.Validate && opts.BatchRepos {
+		return errors.New("--validate cannot be used with --batch-repos")
+	}
+	if opts.RelativeWeeks && opts.Period != periodWeek {
+		return errors.New("--relative-weeks needs --period week")
+	}
+	if opts.ByTeam && opts.TeamsFile == "" {
+		return errors.New("--by-team needs --teams")
+	}
+	if opts.AuthorPercentage && !opts.ByAuthor {
+		return errors.New("--author-percentage needs --by-author")
+	}
+	if opts.Quiet && opts.Verbose {
+		return errors.New("--quiet and --verbose cannot be used together")
+	}
+	if opts.RenameThreshold < 1 || opts.RenameThreshold > 100 {
+		return errors.New("--rename-threshold must be between 1 and 100")
+	}
+	if opts.SessionGap <= 0 {
+		return errors.New("--session-gap must be positive")
+	}
+	if opts.Resume && opts.Checkpoint == "" {
+		return errors.New("--resume needs --checkpoint")
+	}
+	if opts.Checkpoint != "" && opts.BatchRepos {
+		return errors.New("--checkpoint cannot be used with --batch-repos")
+	}
+	if opts.Explain != "" && (modes > 0 || opts.TopDays > 0) {
+		return errors.New("--explain cannot be used with --top-days or the options replacing the daily table")
+	}
+	if opts.StatsTimeout < 0 {
+		return errors.New("--stats-timeout must not be negative")
+	}
+	if opts.TZTolerance < 0 {
+		return errors.New("--tz-tolerance must not be negative")
+	}
+	if opts.TZTolerance > 0 && opts.TZOffset == nil {
+		return errors.New("--tz-tolerance needs --tz-offset")
+	}
+	if opts.AddWeight < 0 || opts.DelWeight < 0 {
+		return errors.New("--add-weight and --del-weight must not be negative")
+	}
+	if opts.Append && (opts.Output == "" || !slices.Contains(strings.Split(opts.Format, ","), "csv")) {
+		return errors.New("--append needs --format csv and --output")
+	}
+	if opts.Tee && (opts.Output == "" || strings.Contains(opts.Format, ",")) {
+		return errors.New("--tee needs --output and a single --format")
+	}
+	if _, ok := fileRenderers[opts.Format]; ok && opts.Tee {
+		return fmt.Errorf("--tee cannot be used with --format %s", opts.Format)
+	}
+	if opts.Tee && (opts.Append || opts.Clipboard || opts.OutputDir != "") {
+		return errors.New("--tee cannot be used with --append, --clipboard or --output-dir")
+	}
+	if opts.Hotspots < 0 {
+		return errors.New("--hotspots must not be negative")
+	}
+	if opts.ResumeGap < 0 {
+		return errors.New("--resume-gap must not be negative")
+	}
+	if opts.Width < 0 {
+		return errors.New("--width must not be negative")
+	}
+	if opts.MinAdditions < 0 || opts.MinDeletions < 0 {
+		return errors.New("--min-additions and --min-deletions must not be negative")
+	}
+	if opts.TopDays < 0 {
+		return errors.New("--top-days must not be negative")
+	}
+	if opts.Indent < 0 {
+		return errors.New("--indent must not be negative")
+	}
+	if err := validateStyle(opts.Style); err != nil {
+		return err
+	}
+	if err := validateChurnMode(opts.ChurnMode); err != nil {
+		return err
+	}
+	if err := validatePeriod(opts.Period); err != nil {
+		return err
+	}
+	if err := validateRenames(opts.PathRenames); err != nil {
+		return err
+	}
+	if _, err := routeFormats(opts.Format, opts.Output); err != nil {
+		return err
+	}
+	return nil
+}
+
+// walkCommits calls fn with every commit in the date range together with its
+// per-file statistics.
+func walkCommits(repo *git.Repository, startDate, endDate time.Time, opts *Options, fn func(c *object.Commit, stats object.FileStats) error) (err error) {
+	defer func() { err = permissionError(repositoryRoot(repo), err) }()
+
+	endDate = endDate.Add(24 * time.Hour).Add(-time.Second)
+
+	from, name, err := resolveBranch(repo, opts.Branch)
+	if err != nil {
+		return err
+	}
+	if short := from.String()[:7]; name != short {
+		debugf("walking commits from %s (%s)", name, short)
+	} else {
+		debugf("walking commits from %s", short)
+	}
+
+	if shallow, err := repo.Storer.Shallow(); err == nil && len(shallow) > 0 {
+		warnf("repository is a shallow clone, history beyond the shallow boundary is not available")
+	}
+
+	var linguist gitattributes.Matcher
+	if opts.Linguist {
+		if linguist, err = loadLinguistMatcher(repo, from); err != nil {
+			return err
+		}
+	}
+
+	excludedCommits, err := resolveCommits(repo, opts.ExcludeCommits)
+	if err != nil {
+		return err
+	}
+
+	authors, err := loadMailmap(repo, from)
+	if err != nil {
+		return err
+	}
+	includedAuthors := authorSet(opts.IncludeAuthors)
+
+	var follower *fileFollower
+	if opts.File != "" {
+		follower = newFileFollower(opts)
+	}
+
+	started := time.Now()
+	walked, tooLarge, skipped, tooSmall, reverts, timedOut := 0, 0, 0, 0, 0, 0
+	defer func() {
+		debugf("walked %d commits in %s", walked, time.Since(started).Round(time.Millisecond))
+		if skipped > 0 {
+			infof("Excluded %d commits given with --exclude-commit", skipped)
+		}
+		if tooLarge > 0 {
+			infof("Skipped %d commits changing more than %d files", tooLarge, opts.MaxFilesPerCommit)
+		}
+		if reverts > 0 {
+			infof("Excluded %d revert commits", reverts)
+		}
+		if tooSmall > 0 {
+			infof("Skipped %d commits below --min-additions or --min-deletions", tooSmall)
+		}
+		if timedOut > 0 {
+			infof("Skipped %d commits (timeout) whose changes took longer than %s to compute", timedOut, opts.StatsTimeout)
+		}
+	}()
+
+	walk := func(fn func(c *object.Commit) error) error {
+		return logCommits(repo, from, startDate, endDate, fn)
+	}
+	if opts.Commits != nil {
+		walk = func(fn func(c *object.Commit) error) error {
+			for _, c := range opts.Commits {
+				if err := fn(c); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	return walk(func(c *object.Commit) (err error) {
+		if opts.Stop != nil {
+			if err := opts.Stop(); err != nil {
+				return err
+			}
+		}
+
+		walked++
+		short := c.Hash.String()[:7]
+		c.Author = authors.apply(c.Author)
+
+		if follower != nil && opts.Follow {
+			defer func() {
+				if err != nil {
+					return
+				}
+				var moves map[string]string
+				if moves, err = commitMoves(c, opts.RenameThreshold); err == nil {
+					follower.track(moves)
+				}
+			}()
+		}
+
+		if reason := skipCommit(c, opts, includedAuthors, excludedCommits); reason != "" {
+			debugf("skipping %s: %s", short, reason)
+			switch reason {
+			case skipExcludedCommit:
+				skipped++
+			case skipRevert:
+				reverts++
+			}
+			return nil
+		}
+
+		ctx, cancel := statsContext(opts.StatsTimeout)
+		stats, err := commitFileStats(ctx, c, opts.RenameThreshold, opts.NormalizeEOL)
+		cancel()
+		if errors.Is(err, context.DeadlineExceeded) {
+			debugf("skipping %s: changes not computed within --stats-timeout", short)
+			timedOut++
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		empty, err := unchangedTree(c)
+		if err != nil {
+			return err
+		}
 
+		stats, reason := filterStats(stats, empty, opts, linguist, follower)
+		if reason != "" {
+			debugf("skipping %s: %s", short, reason)
+			switch reason {
+			case skipTooLarge:
+				tooLarge++
+			case skipTooSmall:
+				tooSmall++
+			}
+			return nil
+		}
+
+		return fn(c, stats)
+	})
+}
+
+// Reasons for skipCommit and filterStats to leave out a commit.
 const (
-	dateRangeWidth    = 25
-	filesChangedWidth = 15
-	additionsWidth    = 11
-	deletionsWidth    = 11
-	totalChangesWidth = 15
+	skipAuthor         = "author not in --authors-file"
+	skipFilter         = "commit filter"
+	skipExcludedCommit = "--exclude-commit"
+	skipRevert         = "revert"
+	skipExcludedRange  = "excluded range"
+	skipHours          = "outside --hours"
+	skipTimeZone       = "outside --tz-offset"
+	skipInitial        = "initial commit"
+	skipMerge          = "merge filter"
+	skipTooLarge       = "more files changed than --max-files-per-commit"
+	skipEmpty          = "no file changes"
+	skipPaths          = "no changes within --path"
+	skipPreset         = "no changes matching --preset"
+	skipLinguist       = "only generated or vendored files"
+	skipFile           = "does not change --file"
+	skipTooSmall       = "below --min-additions or --min-deletions"
 )
 
-func getGitStats(repoPath string, startDate, endDate time.Time) (map[string]*DailyStats, error) {
-	repo, err := git.PlainOpen(repoPath)
-	if err != nil {
-		return nil, err
+// skipCommit returns why the options leave out c before its changes are
+// looked at, or "" when it is counted. The author of c must already be
+// named as in .mailmap. --verify applies it to the commits of git log, so
+// both count the same commits.
+func skipCommit(c *object.Commit, opts *Options, includedAuthors map[string]bool, excludedCommits map[plumbing.Hash]bool) string {
+	isMerge := len(c.ParentHashes) > 1
+	switch {
+	case !includesAuthor(includedAuthors, c.Author.Name, c.Author.Email):
+		return skipAuthor
+	case opts.CommitFilter != nil && !opts.CommitFilter(c):
+		return skipFilter
+	case excludedCommits[c.Hash]:
+		return skipExcludedCommit
+	case opts.ExcludeReverts && isRevert(c):
+		return skipRevert
+	case excluded(c.Author.When, opts.ExcludeRanges):
+		return skipExcludedRange
+	case opts.Hours != nil && !opts.Hours.contains(c.Author.When.Hour()):
+		return skipHours
+	case opts.TZOffset != nil && !inTimeZone(c.Author.When, *opts.TZOffset, opts.TZTolerance):
+		return skipTimeZone
+	case len(c.ParentHashes) == 0 && !opts.IncludeInitialCommit:
+		return skipInitial
+	case opts.NoMerges && isMerge || opts.MergesOnly && !isMerge:
+		return skipMerge
 	}
+	return ""
+}
 
-	endDate = endDate.Add(24 * time.Hour).Add(-time.Second)
+// filterStats keeps the changes of a commit that the options count. It
+// returns why the commit is left out instead when none are kept, or when
+// the commit as a whole is too large or too small. empty tells whether the
+// commit changes no files at all, as found by unchangedTree.
+func filterStats(stats object.FileStats, empty bool, opts *Options, linguist gitattributes.Matcher, follower *fileFollower) (object.FileStats, string) {
+	if opts.MaxFilesPerCommit > 0 && len(stats) > opts.MaxFilesPerCommit {
+		return nil, skipTooLarge
+	}
+
+	if empty && !opts.IncludeEmptyCommits {
+		return nil, skipEmpty
+	}
+
+	if len(opts.Paths) > 0 {
+		// Commits that changed nothing within the paths are skipped,
+		// even with --include-empty-commits.
+		if stats = filterPaths(stats, opts); len(stats) == 0 {
+			return nil, skipPaths
+		}
+	}
 
-	commits, err := repo.Log(&git.LogOptions{
-		Since: &startDate,
-		Until: &endDate,
+	if len(opts.PathPatterns) > 0 {
+		if stats = filterPatterns(stats, opts.PathPatterns); len(stats) == 0 {
+			return nil, skipPreset
+		}
+	}
+
+	if linguist != nil {
+		if stats = filterLinguist(stats, linguist); len(stats) == 0 {
+			return nil, skipLinguist
+		}
+	}
+
+	if follower != nil {
+		if stats = follower.filter(stats); len(stats) == 0 {
+			return nil, skipFile
+		}
+	}
+
+	if opts.MinAdditions > 0 || opts.MinDeletions > 0 {
+		additions, deletions := 0, 0
+		for _, stat := range stats {
+			additions += stat.Addition
+			deletions += stat.Deletion
+		}
+		if additions < opts.MinAdditions || deletions < opts.MinDeletions {
+			return nil, skipTooSmall
+		}
+	}
+
+	return stats, ""
+}
+
+// openRepository opens the repository at path. Linked worktrees created with
+// `git worktree add` keep their objects and refs in the main repository, so
+// the `commondir` file is followed to reach them.
+func openRepository(path string) (*git.Repository, error) {
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{
+		EnableDotGitCommonDir: true,
 	})
 	if err != nil {
-		return nil, err
+		return nil, permissionError(path, err)
 	}
+	return repo, nil
+}
 
+func getGitStats(repo *git.Repository, startDate, endDate time.Time, opts *Options) (map[string]*DailyStats, error) {
 	dailyStats := make(map[string]*DailyStats)
 
-	err = commits.ForEach(func(c *object.Commit) error {
-		commitDate := c.Author.When.Format("2006-01-02")
-		stats, err := c.Stats()
+	var cp *checkpoint
+	if opts.Checkpoint != "" {
+		var err error
+		cp, err = newCheckpoint(opts.Checkpoint, opts.Resume, startDate, endDate, opts)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		dailyStats = cp.DailyStats
+
+		interrupted, stop := notifyInterrupt()
+		defer stop()
+
+		// The walk stops right away, even while none of the commits
+		// reached are counted.
+		walkOpts := *opts
+		walkOpts.addCommitFilter(cp.filter)
+		walkOpts.Stop = func() error {
+			select {
+			case <-interrupted:
+				if err := cp.save(); err != nil {
+					return err
+				}
+				return fmt.Errorf("interrupted; the progress is saved in %s, run again with --resume to continue", cp.filename)
+			default:
+			}
+			return nil
+		}
+		opts = &walkOpts
+	}
+
+	var created *creationDates
+	if opts.AgeWeighted {
+		from, _, err := resolveBranch(repo, opts.Branch)
+		if err != nil {
+			return nil, err
 		}
+		if created, err = fileCreationDates(repo, from, opts.RenameThreshold); err != nil {
+			return nil, err
+		}
+	}
+
+	err := walkCommits(repo, startDate, endDate, opts, func(c *object.Commit, stats object.FileStats) error {
+		commitDate := c.Author.When.Format("2006-01-02")
 
 		if _, ok := dailyStats[commitDate]; !ok {
 			dailyStats[commitDate] = &DailyStats{
 				FilesChanged: make(map[string]struct{}),
+				Authors:      make(map[string]struct{}),
+			}
+		}
+
+		dailyStats[commitDate].Commits++
+		dailyStats[commitDate].Authors[authorKey(c.Author, opts)] = struct{}{}
+		dailyStats[commitDate].Hours[c.Author.When.Hour()]++
+
+		lag, skewed := reviewLag(c)
+		dailyStats[commitDate].ReviewLag += lag
+		if skewed {
+			dailyStats[commitDate].ClockSkew++
+		}
+
+		if opts.Bytes {
+			byteChanges, err := commitByteChanges(c, opts.RenameThreshold)
+			if err != nil {
+				return err
+			}
+			for _, stat := range stats {
+				dailyStats[commitDate].Bytes += byteChanges[stat.Name]
+			}
+		}
+
+		if opts.NetFiles {
+			netFiles, err := commitNetFiles(c, opts.RenameThreshold)
+			if err != nil {
+				return err
+			}
+			for _, stat := range stats {
+				dailyStats[commitDate].NetFiles += netFiles[stat.Name]
 			}
 		}
 
+		if commitDate == opts.Explain {
+			dailyStats[commitDate].Explained = append(dailyStats[commitDate].Explained, explainCommit(c, stats, opts.ChurnMode))
+		}
+
+		if opts.Velocity {
+			dailyStats[commitDate].Times = append(dailyStats[commitDate].Times, c.Author.When)
+		}
+
+		revert := isRevert(c)
+		if revert {
+			dailyStats[commitDate].Reverts++
+		}
+
 		for _, stat := range stats {
+			if revert {
+				dailyStats[commitDate].RevertChanges += fileChanges(stat, opts.ChurnMode)
+			}
 			dailyStats[commitDate].FilesChanged[stat.Name] = struct{}{}
 			dailyStats[commitDate].Additions += stat.Addition
 			dailyStats[commitDate].Deletions += stat.Deletion
+			dailyStats[commitDate].Changes += fileChanges(stat, opts.ChurnMode)
+			if opts.AgeWeighted {
+				dailyStats[commitDate].AgeWeighted += float64(fileChanges(stat, opts.ChurnMode)) * created.ageWeight(stat.Name, c.Author.When)
+			}
+			if opts.SplitTests && isTestFile(stat.Name, opts.TestPatterns) {
+				dailyStats[commitDate].TestAdditions += stat.Addition
+				dailyStats[commitDate].TestDeletions += stat.Deletion
+			}
+			if opts.VerboseFiles {
+				if dailyStats[commitDate].Files == nil {
+					dailyStats[commitDate].Files = make(map[string]fileChurn)
+				}
+				churn := dailyStats[commitDate].Files[stat.Name]
+				churn.Additions += stat.Addition
+				churn.Deletions += stat.Deletion
+				dailyStats[commitDate].Files[stat.Name] = churn
+			}
 		}
 
+		if cp != nil {
+			return cp.add(c)
+		}
 		return nil
 	})
 
 	if err != nil {
 		return nil, err
 	}
+	if cp != nil {
+		if err := cp.finish(); err != nil {
+			return nil, err
+		}
+	}
 
 	return dailyStats, nil
 }
 
+// sortedByChanges returns the keys of stats ordered by total changes, largest
+// first. Ties are broken by key so the output is the same on every run.
+func sortedByChanges(stats map[string]*DailyStats) []string {
+	keys := make([]string, 0, len(stats))
+	for key := range stats {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := stats[keys[i]], stats[keys[j]]
+		if a.Changes != b.Changes {
+			return a.Changes > b.Changes
+		}
+		return keys[i] < keys[j]
+	})
+
+	return keys
+}
+
 func parseDate(dateStr string) (time.Time, error) {
 	return time.Parse("2006-01-02", dateStr)
 }
@@ -92,135 +792,805 @@ func formatDateRange(startDate, endDate time.Time) string {
 	return fmt.Sprintf("%s ~ %s", startStr, endStr)
 }
 
-func printTableHeader() {
-	totalWidth := dateRangeWidth + filesChangedWidth + additionsWidth + deletionsWidth + totalChangesWidth + 4 // +4 for separators
+func printUsage(fs *flag.FlagSet) {
+	fmt.Println("Usage: git-stat [options] <repo_path> <start_date> <end_date>")
+	fmt.Println("       git-stat [options] --since-commit <commit> <repo_path> <end_date>")
+	fmt.Println("       git-stat [options] --commits-file <file> <repo_path>")
+	fmt.Println("Example: git-stat /path/to/repo 2023-08-30 2023-09-01")
+	fmt.Println()
+	fmt.Println("Options:")
+	fs.PrintDefaults()
+}
 
-	fmt.Printf("%s|%s|%s|%s|%s\n",
-		centerText("Date Range", dateRangeWidth),
-		centerText("Files Changed", filesChangedWidth),
-		centerText("Additions", additionsWidth),
-		centerText("Deletions", deletionsWidth),
-		centerText("Total Changes", totalChangesWidth))
+// printConfig writes the options in effect, along with the settings kept
+// outside of Options and the positional arguments, as JSON. The options are
+// printed before they are validated so conflicting ones can be inspected.
+func printConfig(w io.Writer, opts *Options, args []string) error {
+	config := struct {
+		*Options
+		Humanize string
+		Args     []string
+	}{opts, humanizeStyle, args}
 
-	fmt.Printf("%s\n", strings.Repeat("-", totalWidth))
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(config)
 }
 
-func printTableRow(dateRange string, filesChanged, additions, deletions, totalChanges int) {
-	fmt.Printf("%s|%s|%s|%s|%s\n",
-		padText(dateRange, dateRangeWidth),
-		centerText(fmt.Sprintf("%d", filesChanged), filesChangedWidth),
-		centerText(fmt.Sprintf("%d", additions), additionsWidth),
-		centerText(fmt.Sprintf("%d", deletions), deletionsWidth),
-		centerText(fmt.Sprintf("%d", totalChanges), totalChangesWidth))
-
-	totalWidth := dateRangeWidth + filesChangedWidth + additionsWidth + deletionsWidth + totalChangesWidth + 4
-	fmt.Printf("%s\n", strings.Repeat("-", totalWidth))
+// printValidation prints the repository, branch and range a run would
+// analyze, exiting with an error when the branch cannot be resolved.
+func printValidation(repo *git.Repository, path string, startDate, endDate time.Time, opts *Options) {
+	if err := printRepoInfo(os.Stdout, repo, path, startDate, endDate, opts); err != nil {
+		fatalf("Error resolving branch: %v", err)
+	}
 }
 
-func printNoChangeRow(dateRange string, days int) {
-	var days_tip = "day"
-	if days > 1 {
-		days_tip = "days"
+// printRepoInfo prints the repository, the branch and the commit it points
+// to, and the date range.
+func printRepoInfo(w io.Writer, repo *git.Repository, path string, startDate, endDate time.Time, opts *Options) error {
+	from, name, err := resolveBranch(repo, opts.Branch)
+	if err != nil {
+		return err
 	}
 
-	message := fmt.Sprintf("%d %s no commits", days, days_tip)
+	root := path
+	if worktree, err := repo.Worktree(); err == nil {
+		root = worktree.Filesystem.Root()
+	}
 
-	totalWidth := dateRangeWidth + filesChangedWidth + additionsWidth + deletionsWidth + totalChangesWidth + 4 // +4 for separators
+	days := int(endDate.Sub(startDate).Hours()/24) + 1
+	fmt.Fprintf(w, "Repository: %s\n", root)
+	fmt.Fprintf(w, "Branch:     %s (%s)\n", name, from.String()[:7])
+	fmt.Fprintf(w, "Range:      %s (%d %s)\n", formatDateRange(startDate, endDate), days, dayUnit(days))
+	return nil
+}
 
-	fmt.Printf("%s%s%s\n", colorOrange, strings.Repeat("-", totalWidth), colorReset)
+// parseArgs parses the command line flags, which may appear before, between
+// or after the positional arguments.
+func parseArgs(args []string) (*Options, []string, error) {
+	opts := &Options{
+		CommitTypes:      defaultCommitTypes,
+		DedupeAcrossDays: true,
+		PrimaryLanguage:  true,
 
-	fmt.Printf("%s%s%s|%s%s%s\n",
-		colorOrange,
-		padText(dateRange, dateRangeWidth),
-		colorReset,
-		colorOrange,
-		centerText(message, totalWidth-dateRangeWidth-1),
-		colorReset)
+		IncludeInitialCommit: true,
+		Linguist:             true,
+		TestPatterns:         defaultTestPatterns,
+		WorkHours:            defaultWorkHours,
+		ChartSize:            defaultChartSize,
+	}
 
-	fmt.Printf("%s\n", strings.Repeat("-", totalWidth))
-}
+	fs := flag.NewFlagSet("git-stat", flag.ContinueOnError)
+	fs.SetOutput(os.Stdout)
+	fs.Usage = func() { printUsage(fs) }
+
+	fs.BoolVar(&opts.Annotate, "annotate", false, "mark days with the tags created on them")
+	fs.BoolVar(&opts.ByLanguage, "by-language", false, "show changes per language instead of per day")
+	fs.StringVar(&opts.LanguagesFile, "languages", "", "file overriding the extension to language `mapping`")
+	fs.BoolVar(&opts.ByType, "by-type", false, "show changes per Conventional Commits type instead of per day")
+	fs.Func("types", "comma separated commit `types` recognized by --by-type", func(value string) error {
+		opts.CommitTypes = nil
+		for _, t := range strings.Split(value, ",") {
+			if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+				opts.CommitTypes = append(opts.CommitTypes, t)
+			}
+		}
+		return nil
+	})
+	fs.BoolVar(&opts.ByAuthor, "by-author", false, "show changes per author instead of per day")
+	fs.BoolVar(&opts.ByTeam, "by-team", false, "show changes per team of the authors, as listed in --teams")
+	fs.StringVar(&opts.TeamsFile, "teams", "", "`file` listing the emails of each team's members for --by-team")
+	fs.BoolVar(&opts.AuthorEmailOnly, "author-email-only", false, "identify authors by email alone, ignoring their name")
+	fs.BoolVar(&opts.AuthorPercentage, "author-percentage", false, "show each author's share of the total changes with --by-author")
+	fs.BoolVar(&opts.Anonymize, "anonymize", false, "replace author names and emails with stable pseudonyms")
+	fs.BoolVar(&opts.ByMerge, "by-merge", false, "show changes per merge into the branch, such as per pull request, instead of per day")
+	fs.BoolVar(&opts.ByWeekday, "by-weekday", false, "show changes per day of the week instead of per day")
+	fs.BoolVar(&opts.FileCount, "file-count", false, "show the number of files under --path at the end of each period")
+	fs.StringVar(&opts.SinceCommit, "since-commit", "", "count only the commits made after `commit` and not already contained in it; replaces <start_date>")
+	fs.StringVar(&opts.CommitsFile, "commits-file", "", "analyze the commits listed in `file`, one hash per line, instead of a date range; replaces <start_date> and <end_date>")
+	fs.BoolVar(&opts.Strict, "strict", false, "stop at a hash of --commits-file that cannot be resolved instead of skipping it")
+	fs.StringVar(&opts.Ref, "ref", "", "walk from the reference `ref`, such as refs/stash, instead of a branch")
+	fs.BoolVar(&opts.BatchRepos, "batch-repos", false, "treat <repo_path> as a directory of repositories and show changes per repository")
+	fs.IntVar(&opts.Hotspots, "hotspots", 0, "list the `n` files changed by the most commits instead of daily totals")
+	fs.BoolVar(&opts.AfterHoursReport, "after-hours-report", false, "count each author's commits made on weekends and outside --work-hours instead of daily totals")
+	fs.Func("work-hours", "working hours `from-to` of --after-hours-report (0-23, author time; default 8-19, 08:00 to 19:59)", func(value string) (err error) {
+		opts.WorkHours, err = parseHourRange(value)
+		return err
+	})
+	fs.BoolVar(&opts.CommitsTable, "commits-table", false, "list the individual commits, newest first, instead of daily totals")
+	fs.StringVar(&opts.CommitURL, "commit-url", "", "link the hashes of --commits-table to `template`, such as https://github.com/org/repo/commit/{hash}")
+	fs.BoolVar(&opts.SplitTests, "split-tests", false, "show the additions and deletions to test files apart from the rest of the code")
+	testPatternsSet := false
+	fs.Func("test-pattern", "file `pattern` of test files for --split-tests, such as *_test.go or test/** (repeatable, replaces the defaults)", func(value string) error {
+		if !testPatternsSet {
+			opts.TestPatterns, testPatternsSet = nil, true
+		}
+		opts.TestPatterns = append(opts.TestPatterns, value)
+		return nil
+	})
+	fs.StringVar(&opts.Period, "period", periodDay, "length of the periods of --file-count and --format authors-json: day, week or month")
+	fs.BoolVar(&opts.RelativeWeeks, "relative-weeks", false, "with --period week, start the weeks on the start date and label them Week 1, Week 2, ... like sprints")
+	fs.StringVar(&opts.Locale, "locale", "", "language of weekday names, such as fr or de (default English); dates and months stay ISO numbers")
+	fs.Func("path", "only count files under `dir` (repeatable)", func(value string) error {
+		opts.Paths = append(opts.Paths, normalizePath(value))
+		return nil
+	})
+	fs.Func("preset", "only count files matching the patterns of the `preset` api, docs, tests or ci, or one of --presets (repeatable)", func(value string) error {
+		opts.Presets = append(opts.Presets, value)
+		return nil
+	})
+	fs.StringVar(&opts.PresetsFile, "presets", "", "`file` defining presets for --preset, one name = pattern, pattern, ... per line")
+	fs.StringVar(&opts.File, "file", "", "only count the changes to the file at `path`, named as at the end of the range")
+	fs.BoolVar(&opts.Follow, "follow", false, "follow --file back through renames, like git log --follow")
+	fs.StringVar(&opts.PathRenames, "path-renames", renamesFollow, "files moved across --path: follow (count by new location) or drop")
+	fs.Func("exclude-range", "leave out the commits made from `start..end`, such as a code freeze (repeatable)", func(value string) error {
+		p, err := parseDateRange(value)
+		if err != nil {
+			return err
+		}
+		opts.ExcludeRanges = append(opts.ExcludeRanges, p)
+		return nil
+	})
+	fs.Func("exclude-commit", "leave out the `commit`, given by its full or abbreviated hash (repeatable)", func(value string) error {
+		opts.ExcludeCommits = append(opts.ExcludeCommits, value)
+		return nil
+	})
+	fs.Func("exclude-commits-file", "leave out the commits listed in `file`, one hash per line", func(value string) error {
+		revs, err := readCommitList(value)
+		if err != nil {
+			return err
+		}
+		opts.ExcludeCommits = append(opts.ExcludeCommits, revs...)
+		return nil
+	})
+	fs.Func("authors-file", "only count the commits of the authors listed in `file`, one name or email per line", func(value string) error {
+		authors, err := readAuthorList(value)
+		if err != nil {
+			return err
+		}
+		opts.IncludeAuthors = append(opts.IncludeAuthors, authors...)
+		return nil
+	})
+	fs.Func("hours", "only count the commits made between the hours `from-to` (0-23, author time), such as 18-23 or 22-2", func(value string) (err error) {
+		opts.Hours, err = parseHourRange(value)
+		return err
+	})
+	fs.IntVar(&opts.MinAdditions, "min-additions", 0, "skip commits adding fewer than `n` lines, such as typo fixes")
+	fs.IntVar(&opts.MinDeletions, "min-deletions", 0, "skip commits deleting fewer than `n` lines")
+	fs.Func("tz-offset", "only count the commits whose author time zone is `offset`, such as +08:00", func(value string) error {
+		offset, err := parseTZOffset(value)
+		opts.TZOffset = &offset
+		return err
+	})
+	fs.DurationVar(&opts.TZTolerance, "tz-tolerance", 0, "with --tz-offset, also count time zones up to `duration` away from it, such as 1h")
+	fs.BoolVar(&opts.NormalizeEOL, "normalize-line-endings", false, "compare CRLF line endings as LF, so converting files between them is not counted as changes")
+	fs.DurationVar(&opts.StatsTimeout, "stats-timeout", 0, "skip commits whose changes take longer than `duration` to compute, such as 5s, and report how many (0 means no limit)")
+	fs.IntVar(&opts.RenameThreshold, "rename-threshold", 60, "how similar, in `percent`, a deleted and an added file must be to count as a rename; 100 only detects unchanged moves")
+	fs.IntVar(&opts.MaxFilesPerCommit, "max-files-per-commit", 0, "skip commits changing more than `n` files, such as bulk reformats (0 means no limit)")
+	fs.BoolVar(&opts.NoMerges, "no-merges", false, "skip merge commits")
+	fs.BoolVar(&opts.MergesOnly, "merges-only", false, "only count merge commits")
+	fs.BoolVar(&opts.Linguist, "linguist", true, "leave out files marked linguist-generated or linguist-vendored in .gitattributes; false counts them")
+	fs.BoolVar(&opts.IncludeInitialCommit, "include-stats-for-initial-commit", true, "count the commits without parents, whose files all count as added; false leaves out initial imports")
+	fs.BoolVar(&opts.IncludeEmptyCommits, "include-empty-commits", false, "count commits without file changes")
+	fs.BoolVar(&opts.PerCommit, "per-commit", false, "show the number of commits and average changes per commit")
+	fs.IntVar(&opts.TopDays, "top-days", 0, "only show the `n` days with the most changes, largest first")
+	fs.BoolVar(&opts.Arrows, "arrows", false, "lead the changes of --show-delta, --diff and --file-count with an arrow up, down or flat (terminals only)")
+	fs.BoolVar(&opts.Indicators, "indicators", false, "mark each day as busy, normal or without commits with an emoji (terminals only)")
+	fs.IntVar(&opts.BusyThreshold, "busy-threshold", 500, "total changes above which --indicators marks a day as busy")
+	fs.BoolVar(&opts.NoEmoji, "no-emoji", false, "never print emoji, even with --indicators")
+	fs.BoolVar(&opts.Highlights, "highlights", false, "print the days with the most additions and deletions below the table")
+	fs.BoolVar(&opts.Streaks, "streaks", false, "print the longest and the current run of consecutive days with commits below the table")
+	fs.BoolVar(&opts.Trend, "trend", false, "print whether the daily changes are increasing, decreasing or stable below the table")
+	fs.BoolVar(&opts.Anomalies, "anomalies", false, "print the days whose changes lie far from the average of the same weekday below the table")
+	fs.BoolVar(&opts.Averages, "averages", false, "print the average changes per day with commits and per calendar day below the table")
+	fs.BoolVar(&opts.Reverts, "reverts", false, "print how many commits were reverts and how many changes they make up below the table")
+	fs.BoolVar(&opts.ExcludeReverts, "exclude-reverts", false, "leave out revert commits, whose subject starts with Revert")
+	fs.BoolVar(&opts.ReviewLag, "review-lag", false, "print the average time between author and committer date below the table")
+	fs.IntVar(&opts.Width, "width", 0, "narrow tables to `n` columns, cutting long labels and subjects (default: the terminal width, or 80 when it cannot be read; no limit for pipes and files)")
+	fs.BoolVar(&opts.Velocity, "velocity", false, "show the estimated hours worked and the changes per hour, from the commit times")
+	fs.DurationVar(&opts.SessionGap, "session-gap", 2*time.Hour, "longest `duration` between two commits of the same work session for --velocity")
+	fs.Func("chart-size", "`size` of the chart of --format svg in pixels, as WIDTHxHEIGHT (default 800x300)", func(value string) (err error) {
+		opts.ChartSize, err = parseChartSize(value)
+		return err
+	})
+	fs.BoolVar(&opts.Bytes, "bytes", false, "show by how many bytes the changed files grew or shrank; reads the size of every changed blob")
+	fs.BoolVar(&opts.AgeWeighted, "age-weighted", false, "add a column with the changes weighted by the age of their files, counting changes to old code higher (walks the whole history)")
+	fs.BoolVar(&opts.NetFiles, "net-files", false, "show how many files were added minus how many were deleted")
+	fs.BoolVar(&opts.Authors, "authors", false, "show the number of people who committed")
+	fs.BoolVar(&opts.PeakHour, "peak-hour", false, "show the hour of the day with the most commits")
+	fs.Func("precision", "number of `decimals` of averages, percentages and other computed values (default: one, two for --trend)", func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 || n > 10 {
+			return fmt.Errorf("expected 0 to 10 decimals, got %q", value)
+		}
+		precision = n
+		return nil
+	})
+	fs.Var(humanizeFlag{&humanizeStyle}, "humanize", "format large numbers in the table: comma (1,234,567) or compact (1.2M)")
+	fs.Func("explain", "list the commits and files counted toward the `date` below the table, to trace its numbers", func(value string) error {
+		if _, err := parseDate(value); err != nil {
+			return fmt.Errorf("expected a date as YYYY-MM-DD, got %q", value)
+		}
+		opts.Explain = value
+		return nil
+	})
+	fs.BoolVar(&opts.Verify, "verify", false, "cross-check the daily totals against `git log --numstat` (needs git on PATH)")
+	fs.BoolVar(&opts.ConfigPrint, "config-print", false, "print the options in effect as JSON and exit, without opening the repository")
+	fs.StringVar(&opts.Checkpoint, "checkpoint", "", "save the progress to `file` while walking the history, and when interrupted with Ctrl-C")
+	fs.BoolVar(&opts.Resume, "resume", false, "continue from the progress saved in the --checkpoint file by an interrupted run with the same options")
+	fs.BoolVar(&opts.SummaryMarker, "summary-marker", false, "print a line reading ### SUMMARY ### above the Total row, for scripts to find the totals and summary by")
+	fs.BoolVar(&opts.Header, "header", false, "print the repository, branch, date range and number of commits above the table")
+	fs.BoolVar(&opts.Validate, "validate", false, "check the repository, branch and date range and print what would be analyzed, without walking the history")
+	fs.BoolVar(&opts.Quiet, "quiet", false, "only print errors to stderr")
+	fs.BoolVar(&opts.Verbose, "verbose", false, "print debugging information to stderr")
+	fs.StringVar(&opts.Style, "style", "ascii", "table borders: ascii, unicode or minimal (no rules)")
+	fs.IntVar(&opts.Indent, "indent", 0, "indent every line of the table by `n` spaces")
+	fs.StringVar(&opts.Format, "format", "table", "output `format`: table, json, csv, tsv, html, svg, prometheus, sqlite, commits-json (one line per commit) or authors-json (authors per --period); several formats, such as table,json, send the table to stdout and the other to --output")
+	fs.StringVar(&opts.Output, "output", "", "write the report to `file` instead of stdout")
+	fs.Float64Var(&opts.AddWeight, "add-weight", 1, "`weight` of an added line in the Weighted column")
+	fs.Float64Var(&opts.DelWeight, "del-weight", 1, "`weight` of a deleted line in the Weighted column, such as 2 to value cleanups")
+	fs.StringVar(&opts.ChurnMode, "churn-mode", churnSum, "how much a changed file counts towards Total Changes: sum (additions + deletions), max or net (additions - deletions)")
+	fs.BoolVar(&opts.DedupeAcrossDays, "dedupe-across-days", true, "count a file changed on several days once in the total; false adds up the daily counts")
+	fs.BoolVar(&opts.PrimaryLanguage, "primary-language", true, "name the language most changed files are written in above the table")
+	fs.BoolVar(&opts.VerboseFiles, "verbose-files", false, "list the files changed on each day below its row, with their additions and deletions")
+	fs.BoolVar(&opts.ShowDelta, "show-delta", false, "append to each day how its total changes differ from the previous day with commits")
+	fs.IntVar(&opts.ResumeGap, "resume-gap", 0, "mark the first day with commits after at least `n` days without any as resumed (0 turns it off)")
+	fs.BoolVar(&opts.OnlyActiveDays, "only-days-with-commits", false, "leave out the rows for days without commits")
+	fs.BoolVar(&opts.ExclusiveEnd, "exclusive-end", false, "leave out the end date, making the range half-open")
+	fs.BoolVar(&opts.ClampFuture, "clamp-future", false, "end the range at today when the end date is in the future")
+	fs.StringVar(&opts.Branch, "branch", "", "branch or revision to analyze (default: the remote's default branch, then HEAD)")
+	fs.StringVar(&opts.DiffFile, "diff", "", "show how each day changed compared with a report saved with --format json to `file`")
+	fs.BoolVar(&opts.Tee, "tee", false, "print the report to stdout as well as writing it to --output, which is left without colors")
+	fs.BoolVar(&opts.Clipboard, "clipboard", false, "copy what would be written to stdout to the clipboard instead, without colors")
+	fs.BoolVar(&opts.Append, "append", false, "with --format csv, add the days missing from the --output file to its end instead of overwriting it")
+	fs.StringVar(&opts.OutputDir, "output-dir", "", "write report.txt, report.json and report.csv to `dir`")
 
-func centerText(text string, width int) string {
-	if len(text) >= width {
-		return text[:width]
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, nil, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	// --commits-file takes the place of both dates.
+	if opts.CommitsFile != "" {
+		if len(positional) != 1 && !(opts.ConfigPrint && len(positional) == 0) {
+			fs.Usage()
+			return nil, nil, errors.New("expected <repo_path> with --commits-file")
+		}
+		return opts, positional, nil
 	}
-	leftPad := (width - len(text)) / 2
-	rightPad := width - len(text) - leftPad
-	return fmt.Sprintf("%s%s%s", strings.Repeat(" ", leftPad), text, strings.Repeat(" ", rightPad))
-}
 
-func padText(text string, width int) string {
-	if len(text) >= width {
-		return text[:width]
+	// --since-commit takes the place of the start date.
+	if opts.SinceCommit != "" {
+		if len(positional) != 2 && !(opts.ConfigPrint && len(positional) == 0) {
+			fs.Usage()
+			return nil, nil, errors.New("expected <repo_path> <end_date> with --since-commit")
+		}
+		return opts, positional, nil
 	}
-	return fmt.Sprintf("%s%s", text, strings.Repeat(" ", width-len(text)))
+
+	if len(positional) != 3 && !(opts.ConfigPrint && len(positional) == 0) {
+		fs.Usage()
+		return nil, nil, errors.New("expected <repo_path> <start_date> <end_date>")
+	}
+
+	return opts, positional, nil
 }
 
 func main() {
-	if len(os.Args) != 4 {
-		fmt.Println("Usage: git-stat <repo_path> <start_date> <end_date>")
-		fmt.Println("Example: git-stat /path/to/repo 2023-08-30 2023-09-01")
+	opts, args, err := parseArgs(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
 		os.Exit(1)
 	}
 
-	repoPath := os.Args[1]
-	startDateStr := os.Args[2]
-	endDateStr := os.Args[3]
+	if opts.ConfigPrint {
+		if err := printConfig(os.Stdout, opts, args); err != nil {
+			fatalf("Error printing configuration: %v", err)
+		}
+		return
+	}
 
-	startDate, err := parseDate(startDateStr)
-	if err != nil {
-		fmt.Printf("Invalid start date format: %v\n", err)
-		os.Exit(1)
+	if err := opts.validate(); err != nil {
+		fatalf("%v", err)
 	}
 
-	endDate, err := parseDate(endDateStr)
-	if err != nil {
-		fmt.Printf("Invalid end date format: %v\n", err)
-		os.Exit(1)
+	switch {
+	case opts.Quiet:
+		currentLogLevel = levelError
+	case opts.Verbose:
+		currentLogLevel = levelDebug
 	}
 
-	if endDate.Before(startDate) {
-		fmt.Println("End date must be after start date")
-		os.Exit(1)
+	if len(opts.Presets) > 0 {
+		if opts.PathPatterns, err = presetPatterns(opts.Presets, opts.PresetsFile); err != nil {
+			fatalf("Invalid --preset: %v", err)
+		}
 	}
 
-	absPath, err := filepath.Abs(repoPath)
-	if err != nil {
-		fmt.Printf("Error resolving repository path: %v\n", err)
-		os.Exit(1)
+	if opts.Locale != "" {
+		setLocale(opts.Locale)
 	}
+	currentStyle = tableStyles[opts.Style]
+
+	repoPath := args[0]
 
-	dailyStats, err := getGitStats(absPath, startDate, endDate)
+	absPath, err := filepath.Abs(repoPath)
 	if err != nil {
-		fmt.Printf("Error getting Git statistics: %v\n", err)
-		os.Exit(1)
+		fatalf("Error resolving repository path: %v", err)
 	}
 
-	printTableHeader()
+	// With --batch-repos the path is a directory of repositories, which are
+	// opened one by one later on.
+	var repo *git.Repository
+	if !opts.BatchRepos {
+		repo, err = openRepository(absPath)
+		if err != nil {
+			fatalf("Error opening repository: %v", err)
+		}
+	}
 
-	var noChangeStartDate time.Time
-	var noChangeDays int
+	if opts.Ref != "" && repo != nil {
+		ref, err := resolveRef(repo, opts.Ref)
+		if err != nil {
+			fatalf("Invalid --ref: %v", err)
+		}
+		opts.Branch = ref.Name().String()
+		if ref.Name() == stashRef {
+			filter, err := stashFilter(repo, ref)
+			if err != nil {
+				fatalf("Invalid --ref: %v", err)
+			}
+			opts.addCommitFilter(filter)
+		}
+	}
 
-	for d := startDate; !d.After(endDate); d = d.AddDate(0, 0, 1) {
-		dateStr := d.Format("2006-01-02")
-		stats, ok := dailyStats[dateStr]
+	var startDate, endDate time.Time
+	if opts.CommitsFile != "" {
+		opts.Commits, startDate, endDate, err = listedCommits(repo, opts.CommitsFile, opts.Strict)
+		if err != nil {
+			fatalf("Invalid --commits-file: %v", err)
+		}
+	} else {
+		endDateStr := args[len(args)-1]
 
-		if !ok {
-			if noChangeDays == 0 {
-				noChangeStartDate = d
+		if opts.SinceCommit != "" {
+			var filter func(c *object.Commit) bool
+			startDate, filter, err = sinceCommit(repo, opts.SinceCommit)
+			if err != nil {
+				fatalf("Invalid --since-commit: %v", err)
 			}
-			noChangeDays++
+			opts.addCommitFilter(filter)
 		} else {
-			if noChangeDays > 0 {
-				printNoChangeRow(formatDateRange(noChangeStartDate, d.AddDate(0, 0, -1)), noChangeDays)
-				noChangeDays = 0
+			startDate, err = parseDate(args[1])
+			if err != nil {
+				fatalf("Invalid start date format: %v", err)
+			}
+		}
+
+		endDate, err = parseDate(endDateStr)
+		if err != nil {
+			fatalf("Invalid end date format: %v", err)
+		}
+
+		if endDate.Before(startDate) {
+			fatalf("End date must be after start date")
+		}
+
+		if opts.ExclusiveEnd {
+			if !endDate.After(startDate) {
+				fatalf("End date must be after start date with --exclusive-end")
+			}
+			endDate = endDate.AddDate(0, 0, -1)
+		}
+
+		today, _ := parseDate(time.Now().Format("2006-01-02"))
+		if endDate.After(today) {
+			if opts.ClampFuture {
+				warnf("end date %s is in the future, using today (%s) instead", endDateStr, today.Format("2006-01-02"))
+				endDate = today
+			} else {
+				warnf("end date %s is in the future, did you mean today (%s)? Use --clamp-future to stop at today", endDateStr, today.Format("2006-01-02"))
 			}
-			totalChanges := stats.Additions + stats.Deletions
-			printTableRow(dateStr, len(stats.FilesChanged), stats.Additions, stats.Deletions, totalChanges)
 		}
+
+		if endDate.Before(startDate) {
+			fatalf("Start date must not be in the future")
+		}
+	}
+
+	if opts.Explain != "" {
+		if day, _ := parseDate(opts.Explain); day.Before(startDate) || day.After(endDate) {
+			fatalf("--explain %s is outside of the date range", opts.Explain)
+		}
+	}
+
+	routes, _ := routeFormats(opts.Format, opts.Output)
+	toTerminal := showsOnTerminal(routes[0], opts, isTerminal(os.Stdout))
+
+	// Tables are narrowed to the width of the terminal. Those written to a
+	// pipe or file are as wide as they need to be.
+	switch {
+	case opts.Width > 0:
+		maxTableWidth = opts.Width
+	case toTerminal:
+		maxTableWidth = terminalWidth(os.Stdout)
+	}
+	if maxTableWidth > 0 && routes[0].format == "table" {
+		maxTableWidth = max(maxTableWidth-opts.Indent, 1)
+	}
+
+	// Emoji and arrows are only printed to terminals, which can be expected
+	// to show them.
+	if opts.NoEmoji || !toTerminal {
+		opts.Indicators = false
+		opts.Arrows = false
+	}
+	deltaArrows = opts.Arrows
+
+	if opts.Validate {
+		printValidation(repo, absPath, startDate, endDate, opts)
+		return
+	}
+
+	if opts.SplitTests {
+		tableColumns = splitTestColumns
+	}
+	if opts.Hotspots > 0 {
+		tableColumns = hotspotColumns
+	}
+	if opts.PerCommit && opts.Hotspots > 0 {
+		// The commits are already the first column of --hotspots.
+		tableColumns = append(tableColumns, perCommitColumns[1:]...)
+	} else if opts.PerCommit {
+		tableColumns = append(tableColumns, perCommitColumns...)
+	}
+	if opts.PeakHour {
+		tableColumns = append(tableColumns, peakHourColumn)
+	}
+	if opts.Authors {
+		tableColumns = append(tableColumns, authorsColumn)
+	}
+	if opts.Bytes {
+		tableColumns = append(tableColumns, bytesColumn)
+	}
+	if opts.NetFiles {
+		tableColumns = append(tableColumns, netFilesColumn)
+	}
+	if opts.Velocity {
+		tableColumns = append(tableColumns, velocityColumns(opts.SessionGap)...)
+	}
+	if opts.AddWeight != 1 || opts.DelWeight != 1 {
+		tableColumns = append(tableColumns, weightedColumn(opts.AddWeight, opts.DelWeight))
+	}
+	if opts.AgeWeighted {
+		tableColumns = append(tableColumns, ageWeightedColumn)
+	}
+
+	// The first route is stdout unless everything goes to --output, or to
+	// both with --tee. The tables of the other modes are written there as
+	// well.
+	var out io.Writer = os.Stdout
+	if opts.Clipboard && routes[0].file == "" {
+		// Collected and copied once everything is written.
+		var clip strings.Builder
+		out = &clip
+		defer func() {
+			if err := copyToClipboard(clip.String(), os.Stdout); err != nil {
+				fatalf("Error writing report: %v", err)
+			}
+		}()
+	}
+	if _, ok := fileRenderers[routes[0].format]; !ok && routes[0].file != "" && !appends(routes[0], opts) {
+		file, err := os.Create(routes[0].file)
+		if err != nil {
+			fatalf("Error creating output file: %v", err)
+		}
+		defer file.Close()
+		// Files are written without colors, like with --output-dir.
+		out = plainWriter{file}
+		if opts.Tee {
+			out = io.MultiWriter(os.Stdout, plainWriter{file})
+		}
+	}
+	// Machine readable formats are never indented.
+	if opts.Indent > 0 && routes[0].format == "table" {
+		out = newIndentWriter(out, opts.Indent)
+	}
+
+	languages := defaultLanguages
+	if opts.LanguagesFile != "" {
+		languages, err = loadLanguageMap(opts.LanguagesFile)
+		if err != nil {
+			fatalf("Error reading language mapping: %v", err)
+		}
+	}
+
+	if opts.BatchRepos {
+		if !opts.PerCommit {
+			tableColumns = append(tableColumns, commitsColumn)
+		}
+
+		repos, err := findRepos(absPath)
+		if err != nil {
+			fatalf("Error scanning for repositories: %v", err)
+		}
+		if len(repos) == 0 {
+			fatalf("No repositories found in %s", absPath)
+		}
+		debugf("found %d repositories", len(repos))
+
+		printBatchTable(out, getBatchStats(absPath, repos, startDate, endDate, opts), opts)
+		return
+	}
+
+	if opts.ByLanguage {
+		languageStats, err := getLanguageStats(repo, startDate, endDate, opts, languages)
+		if err != nil {
+			fatalf("Error getting Git statistics: %v", err)
+		}
+
+		printGroupTable(out, "Language", languageStats)
+		return
+	}
+
+	if opts.ByAuthor {
+		if !opts.PerCommit {
+			tableColumns = append(tableColumns, commitsColumn)
+		}
+
+		authorStats, err := getAuthorStats(repo, startDate, endDate, opts)
+		if err != nil {
+			fatalf("Error getting Git statistics: %v", err)
+		}
+
+		if opts.Anonymize {
+			authorStats = anonymizeStats(authorStats)
+		}
+		if opts.AuthorPercentage {
+			tableColumns = append(tableColumns, shareColumn(authorStats))
+		}
+
+		printGroupTable(out, "Author", authorStats)
+		return
+	}
+
+	if opts.ByMerge {
+		if !opts.PerCommit {
+			tableColumns = append(tableColumns, commitsColumn)
+		}
+
+		mergeStats, err := getMergeStats(repo, startDate, endDate, opts)
+		if err != nil {
+			fatalf("Error getting Git statistics: %v", err)
+		}
+
+		printGroupTable(out, "Merge", mergeStats)
+		return
+	}
+
+	if opts.Hotspots > 0 {
+		fileStats, err := getFileStats(repo, startDate, endDate, opts)
+		if err != nil {
+			fatalf("Error getting Git statistics: %v", err)
+		}
+
+		printHotspotsTable(out, fileStats, opts.Hotspots)
+		return
+	}
+
+	if opts.ByTeam {
+		if !opts.PerCommit {
+			tableColumns = append(tableColumns, commitsColumn)
+		}
+
+		teams, err := loadTeams(opts.TeamsFile)
+		if err != nil {
+			fatalf("Error reading teams: %v", err)
+		}
+
+		teamStats, err := getTeamStats(repo, startDate, endDate, opts, teams)
+		if err != nil {
+			fatalf("Error getting Git statistics: %v", err)
+		}
+
+		printGroupTable(out, "Team", teamStats)
+		return
+	}
+
+	if opts.AfterHoursReport {
+		tableColumns = afterHoursColumns
+
+		authorStats, err := getAfterHoursStats(repo, startDate, endDate, opts, opts.WorkHours)
+		if err != nil {
+			fatalf("Error getting Git statistics: %v", err)
+		}
+
+		if opts.Anonymize {
+			authorStats = anonymizeStats(authorStats)
+		}
+
+		printAfterHoursTable(out, authorStats)
+		return
+	}
+
+	if opts.FileCount {
+		periodName := opts.Period
+		if opts.RelativeWeeks {
+			periodName = periodSprint
+		}
+
+		counts, err := getFileCounts(repo, splitPeriods(startDate, endDate, periodName), opts)
+		if err != nil {
+			fatalf("Error counting files: %v", err)
+		}
+
+		printFileCountTable(out, counts, periodName)
+		return
+	}
+
+	if opts.CommitsTable {
+		rows, err := getCommitRows(repo, startDate, endDate, opts)
+		if err != nil {
+			fatalf("Error getting Git statistics: %v", err)
+		}
+
+		// Links are only written to terminals, where they can be clicked.
+		urlTemplate := ""
+		if toTerminal {
+			urlTemplate = opts.CommitURL
+		}
+
+		printCommitsTable(out, rows, urlTemplate)
+		return
+	}
+
+	if opts.ByWeekday {
+		if !opts.PerCommit {
+			tableColumns = append(tableColumns, commitsColumn)
+		}
+
+		weekdayStats, err := getGroupStats(repo, startDate, endDate, opts, func(c *object.Commit, stat object.FileStat) string {
+			return c.Author.When.Weekday().String()
+		})
+		if err != nil {
+			fatalf("Error getting Git statistics: %v", err)
+		}
+
+		printWeekdayTable(out, weekdayStats)
+		return
 	}
 
-	if noChangeDays > 0 {
-		printNoChangeRow(formatDateRange(noChangeStartDate, endDate), noChangeDays)
+	if opts.ByType {
+		if !opts.PerCommit {
+			tableColumns = append(tableColumns, commitsColumn)
+		}
+
+		typeStats, err := getCommitTypeStats(repo, startDate, endDate, opts)
+		if err != nil {
+			fatalf("Error getting Git statistics: %v", err)
+		}
+
+		printGroupTable(out, "Type", typeStats)
+		return
+	}
+
+	if routes[0].format == formatCommitsJSON {
+		if err := writeCommitsJSON(out, repo, startDate, endDate, opts); err != nil {
+			fatalf("Error getting Git statistics: %v", err)
+		}
+		return
+	}
+
+	if routes[0].format == formatAuthorsJSON {
+		if err := writeAuthorsJSON(out, repo, startDate, endDate, opts); err != nil {
+			fatalf("Error getting Git statistics: %v", err)
+		}
+		return
 	}
+
+	dailyStats, err := getGitStats(repo, startDate, endDate, opts)
+	if err != nil {
+		fatalf("Error getting Git statistics: %v", err)
+	}
+
+	if opts.Verify {
+		gitTotals, err := gitLogTotals(absPath, repo, startDate, endDate, opts)
+		if err != nil {
+			fatalf("Error running git log: %v", err)
+		}
+
+		if !verifyStats(out, dailyStats, gitTotals) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	var tagDates map[string][]string
+	if opts.Annotate {
+		tagDates, err = getTagDates(repo)
+		if err != nil {
+			fatalf("Error reading tags: %v", err)
+		}
+	}
+
+	report := &Report{
+		StartDate:  startDate,
+		EndDate:    endDate,
+		DailyStats: dailyStats,
+		TagDates:   tagDates,
+		Options:    opts,
+	}
+
+	if opts.PrimaryLanguage {
+		report.PrimaryLanguage = primaryLanguage(totalStats(report), languages)
+	}
+
+	if opts.DiffFile != "" {
+		baseline, err := loadJSONReport(opts.DiffFile)
+		if err != nil {
+			fatalf("Error reading baseline report: %v", err)
+		}
+
+		printReportDiff(out, report, baseline)
+		return
+	}
+
+	if opts.OutputDir != "" {
+		if err := writeReportFiles(opts.OutputDir, report); err != nil {
+			fatalf("Error writing reports: %v", err)
+		}
+		return
+	}
+
+	if opts.Header && routes[0].format == "table" {
+		if err := printRepoInfo(out, repo, absPath, startDate, endDate, opts); err != nil {
+			fatalf("Error resolving branch: %v", err)
+		}
+		fmt.Fprintf(out, "Commits:    %s\n\n", formatCount(totalStats(report).Commits))
+	}
+
+	for i, route := range routes {
+		if err := writeRoute(route, i == 0, out, report); err != nil {
+			fatalf("Error writing report: %v", err)
+		}
+	}
+}
+
+// writeRoute writes the report in the format of route. The first route is
+// written to out, which is already open; later ones create their file.
+func writeRoute(route formatRoute, first bool, out io.Writer, report *Report) error {
+	if render, ok := fileRenderers[route.format]; ok {
+		return render(route.file, report)
+	}
+	if appends(route, report.Options) {
+		return appendCSV(route.file, report)
+	}
+	if first {
+		return renderers[route.format](out, report)
+	}
+
+	file, err := os.Create(route.file)
+	if err != nil {
+		return err
+	}
+	if err := renderers[route.format](plainWriter{file}, report); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// appends reports whether route adds to its file with --append rather than
+// overwriting it.
+func appends(route formatRoute, opts *Options) bool {
+	return opts.Append && route.format == "csv" && route.file != ""
 }
@@ -0,0 +1,719 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// runMainEnv makes the test binary run main instead of the tests, so
+// runGitStat can run the command as a user would.
+const runMainEnv = "GIT_STAT_TEST_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMainEnv) == "1" {
+		os.Args = append([]string{"git-stat"}, os.Args[1:]...)
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runGitStat runs git-stat with args in dir and returns what it wrote to
+// stdout and stderr and its exit code.
+func runGitStat(t *testing.T, dir string, args ...string) (stdout, stderr string, code int) {
+	t.Helper()
+
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), runMainEnv+"=1")
+	var out, errOut bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &errOut
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		code = exitErr.ExitCode()
+	case err != nil:
+		t.Fatalf("running git-stat: %v", err)
+	}
+	return out.String(), errOut.String(), code
+}
+
+// mustRun runs git-stat like runGitStat and fails the test unless it
+// succeeds, returning its stdout.
+func mustRun(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	stdout, stderr, code := runGitStat(t, dir, args...)
+	if code != 0 {
+		t.Fatalf("git-stat %s exited with %d: %s", strings.Join(args, " "), code, stderr)
+	}
+	return stdout
+}
+
+// tableRow returns the trimmed cells of the first line of a table whose
+// first cell is label, or nil when there is none.
+func tableRow(out, label string) []string {
+	for _, line := range strings.Split(out, "\n") {
+		cells := strings.Split(line, "|")
+		if strings.TrimSpace(cells[0]) != label {
+			continue
+		}
+		for i := range cells {
+			cells[i] = strings.TrimSpace(cells[i])
+		}
+		return cells
+	}
+	return nil
+}
+
+// testCommit is a commit made by testRepo.commit.
+type testCommit struct {
+	when      string            // RFC 3339 author time
+	committed string            // RFC 3339 committer time, when when empty
+	message   string            // "change" when empty
+	author    string            // "Alice <alice@example.com>" when empty
+	files     map[string]string // new contents by path, "" deleting the file
+	parents   []plumbing.Hash   // another parent, for merges
+}
+
+// testRepo is a repository built commit by commit in a temporary directory.
+type testRepo struct {
+	t    testing.TB
+	dir  string
+	repo *git.Repository
+	wt   *git.Worktree
+}
+
+func newTestRepo(t testing.TB) *testRepo {
+	t.Helper()
+
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testRepo{t: t, dir: dir, repo: repo, wt: wt}
+}
+
+// commit writes and removes the files of c in the worktree and commits
+// them.
+func (r *testRepo) commit(c testCommit) plumbing.Hash {
+	r.t.Helper()
+
+	for name, content := range c.files {
+		path := filepath.Join(r.dir, filepath.FromSlash(name))
+		if content == "" {
+			if _, err := r.wt.Remove(name); err != nil {
+				r.t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			r.t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			r.t.Fatal(err)
+		}
+		if _, err := r.wt.Add(name); err != nil {
+			r.t.Fatal(err)
+		}
+	}
+
+	when, err := time.Parse(time.RFC3339, c.when)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	sig := parseTestAuthor(c.author)
+	sig.When = when
+	committer := sig
+	if c.committed != "" {
+		if committer.When, err = time.Parse(time.RFC3339, c.committed); err != nil {
+			r.t.Fatal(err)
+		}
+	}
+
+	var parents []plumbing.Hash
+	if len(c.parents) > 0 {
+		head, err := r.repo.Head()
+		if err != nil {
+			r.t.Fatal(err)
+		}
+		parents = append([]plumbing.Hash{head.Hash()}, c.parents...)
+	}
+
+	message := c.message
+	if message == "" {
+		message = "change"
+	}
+	hash, err := r.wt.Commit(message, &git.CommitOptions{
+		Author:            &sig,
+		Committer:         &committer,
+		Parents:           parents,
+		AllowEmptyCommits: true,
+	})
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	return hash
+}
+
+// checkout switches the worktree to branch, creating it at the current
+// commit if it does not exist.
+func (r *testRepo) checkout(branch string) {
+	r.t.Helper()
+
+	name := plumbing.NewBranchReferenceName(branch)
+	_, err := r.repo.Reference(name, false)
+	create := errors.Is(err, plumbing.ErrReferenceNotFound)
+	if err := r.wt.Checkout(&git.CheckoutOptions{Branch: name, Create: create}); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+func parseTestAuthor(author string) object.Signature {
+	if author == "" {
+		author = "Alice <alice@example.com>"
+	}
+	name, email, _ := strings.Cut(author, " <")
+	return object.Signature{Name: name, Email: strings.TrimSuffix(email, ">")}
+}
+
+// lines returns n numbered lines, for files changing by a known amount.
+func lines(n int) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	return b.String()
+}
+
+// mergeRepo returns a repository with two commits on master and one merged
+// from a feature branch, which adds 2 lines in its merge.
+func mergeRepo(t *testing.T) *testRepo {
+	t.Helper()
+
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	r.checkout("feature")
+	feature := r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"b": lines(2)}})
+	r.checkout("master")
+	r.commit(testCommit{when: "2024-03-03T10:00:00Z", files: map[string]string{"a": lines(4)}})
+	r.commit(testCommit{
+		when:    "2024-03-04T10:00:00Z",
+		message: "Merge branch 'feature'",
+		files:   map[string]string{"b": lines(2)},
+		parents: []plumbing.Hash{feature},
+	})
+	return r
+}
+
+func TestMergeFilters(t *testing.T) {
+	r := mergeRepo(t)
+
+	tests := []struct {
+		flag  string
+		dates []string
+	}{
+		{"", []string{"2024-03-01", "2024-03-02", "2024-03-03", "2024-03-04"}},
+		{"--no-merges", []string{"2024-03-01", "2024-03-02", "2024-03-03"}},
+		{"--merges-only", []string{"2024-03-04"}},
+	}
+	for _, tt := range tests {
+		args := []string{"--only-days-with-commits", ".", "2024-03-01", "2024-03-04"}
+		if tt.flag != "" {
+			args = append([]string{tt.flag}, args...)
+		}
+		out := mustRun(t, r.dir, args...)
+
+		var dates []string
+		for _, date := range []string{"2024-03-01", "2024-03-02", "2024-03-03", "2024-03-04"} {
+			if tableRow(out, date) != nil {
+				dates = append(dates, date)
+			}
+		}
+		if strings.Join(dates, " ") != strings.Join(tt.dates, " ") {
+			t.Errorf("%q counts %v, want %v", tt.flag, dates, tt.dates)
+		}
+	}
+
+	if row := tableRow(mustRun(t, r.dir, "--merges-only", ".", "2024-03-04", "2024-03-04"), "Total"); row[2] != "2" {
+		t.Errorf("the merge adds %s lines, want its diff against the first parent, 2", row[2])
+	}
+
+	if _, stderr, code := runGitStat(t, r.dir, "--merges-only", "--no-merges", ".", "2024-03-01", "2024-03-04"); code == 0 || !strings.Contains(stderr, "cannot be used together") {
+		t.Errorf("--merges-only with --no-merges exited with %d: %s", code, stderr)
+	}
+}
+
+func TestSortedByChanges(t *testing.T) {
+	stats := map[string]*DailyStats{
+		"b": {Changes: 5},
+		"a": {Changes: 5},
+		"c": {Changes: 9},
+		"d": {Changes: 1},
+	}
+	if got := strings.Join(sortedByChanges(stats), " "); got != "c a b d" {
+		t.Errorf("sortedByChanges = %q, want most changes first and ties by key", got)
+	}
+}
+
+// TestStableOutput runs every mode whose rows come from a map twice on the
+// same history, with ties in the numbers the rows are sorted by, and expects
+// the same bytes both times.
+func TestStableOutput(t *testing.T) {
+	r := newTestRepo(t)
+	for i, author := range []string{"Alice <alice@example.com>", "Bob <bob@example.com>", "Carol <carol@example.com>", "Dave <dave@example.com>"} {
+		r.commit(testCommit{
+			when:    fmt.Sprintf("2024-03-0%dT10:00:00Z", i+1),
+			message: []string{"feat: a", "fix: b", "docs: c", "chore: d"}[i],
+			author:  author,
+			files: map[string]string{
+				fmt.Sprintf("f%d.go", i): lines(2),
+				fmt.Sprintf("f%d.py", i): lines(2),
+				fmt.Sprintf("f%d.md", i): lines(2),
+			},
+		})
+	}
+
+	modes := [][]string{
+		nil,
+		{"--by-author"},
+		{"--by-language"},
+		{"--by-type"},
+		{"--by-weekday"},
+		{"--hotspots", "5"},
+		{"--commits-table"},
+		{"--format", "json"},
+		{"--format", "commits-json"},
+	}
+	for _, mode := range modes {
+		args := append(append([]string{}, mode...), ".", "2024-03-01", "2024-03-04")
+		first := mustRun(t, r.dir, args...)
+		for i := 0; i < 3; i++ {
+			if again := mustRun(t, r.dir, args...); again != first {
+				t.Errorf("%v gave different output on run %d:\n%s\nthen:\n%s", mode, i+2, first, again)
+				break
+			}
+		}
+	}
+}
+
+func TestIncludeEmptyCommits(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", message: "trigger CI"})
+	// Only adding a binary file changes no lines, but is no empty commit.
+	r.commit(testCommit{when: "2024-03-03T10:00:00Z", files: map[string]string{"logo.png": "PNG\x00\x01"}})
+
+	binary := []string{"2024-03-03", "0", "0", "0", "0", "1", "0.0"}
+	tests := []struct {
+		flag    string
+		rows    map[string][]string // the cells of each day, nil for none
+		banner  string
+		commits string
+	}{
+		{"", map[string][]string{"2024-03-02": nil, "2024-03-03": binary}, "2024-03-02 ~ 03-02", "2"},
+		{"--include-empty-commits", map[string][]string{
+			"2024-03-02": {"2024-03-02", "0", "0", "0", "0", "1", "0.0"},
+			"2024-03-03": binary,
+		}, "2024-03-04 ~ 03-04", "3"},
+	}
+	for _, tt := range tests {
+		args := []string{"--per-commit", ".", "2024-03-01", "2024-03-04"}
+		if tt.flag != "" {
+			args = append([]string{tt.flag}, args...)
+		}
+		out := stripANSI(mustRun(t, r.dir, args...))
+
+		for day, want := range tt.rows {
+			if row := tableRow(out, day); strings.Join(row, "|") != strings.Join(want, "|") {
+				t.Errorf("%q: row of %s %q, want %q:\n%s", tt.flag, day, row, want, out)
+			}
+		}
+		if tableRow(out, tt.banner) == nil {
+			t.Errorf("%q: no banner for %s:\n%s", tt.flag, tt.banner, out)
+		}
+		if row := tableRow(out, "Total"); row[5] != tt.commits {
+			t.Errorf("%q: %s commits in total, want %s", tt.flag, row[5], tt.commits)
+		}
+	}
+}
+
+func TestLinkedWorktree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not on PATH")
+	}
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(3)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"b": lines(2)}})
+
+	linked := filepath.Join(t.TempDir(), "linked")
+	if out, err := exec.Command("git", "-C", r.dir, "worktree", "add", "--detach", linked).CombinedOutput(); err != nil {
+		t.Fatalf("git worktree add: %v: %s", err, out)
+	}
+
+	want := mustRun(t, r.dir, ".", "2024-03-01", "2024-03-02")
+	tests := []struct {
+		name string
+		dir  string
+		path string
+	}{
+		{"from the linked worktree", linked, "."},
+		{"to the linked worktree", r.dir, linked},
+	}
+	for _, tt := range tests {
+		if got := mustRun(t, tt.dir, tt.path, "2024-03-01", "2024-03-02"); got != want {
+			t.Errorf("%s:\n%s\nwant the stats of the main worktree:\n%s", tt.name, got, want)
+		}
+	}
+}
+
+func TestFutureEndDate(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(1)}})
+
+	today := time.Now().Format("2006-01-02")
+	future := time.Now().AddDate(0, 0, 30).Format("2006-01-02")
+	tests := []struct {
+		flag    string
+		end     string
+		warning string
+		wantEnd string
+	}{
+		{"", today, "", today},
+		{"", future, "did you mean today (" + today + ")?", future},
+		{"--clamp-future", future, "using today (" + today + ") instead", today},
+		{"--clamp-future", "2024-03-02", "", "2024-03-02"},
+	}
+	for _, tt := range tests {
+		args := []string{"--format", "json", ".", "2024-03-01", tt.end}
+		if tt.flag != "" {
+			args = append([]string{tt.flag}, args...)
+		}
+		stdout, stderr, code := runGitStat(t, r.dir, args...)
+		if code != 0 {
+			t.Fatalf("%q to %s exited with %d: %s", tt.flag, tt.end, code, stderr)
+		}
+
+		switch {
+		case tt.warning == "" && strings.Contains(stderr, "in the future"):
+			t.Errorf("%q to %s warned: %s", tt.flag, tt.end, stderr)
+		case tt.warning != "" && !strings.Contains(stderr, tt.warning):
+			t.Errorf("%q to %s: stderr %q, want a warning containing %q", tt.flag, tt.end, stderr, tt.warning)
+		}
+		if !strings.Contains(stdout, `"end_date": "`+tt.wantEnd+`"`) {
+			t.Errorf("%q to %s: report does not end on %s:\n%s", tt.flag, tt.end, tt.wantEnd, stdout)
+		}
+	}
+}
+
+func TestMaxFilesPerCommit(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(2)}})
+	bulk := make(map[string]string)
+	for i := 0; i < 200; i++ {
+		bulk[fmt.Sprintf("gen/file%03d", i)] = lines(1)
+	}
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: bulk})
+
+	tests := []struct {
+		max     string
+		files   string
+		skipped string
+	}{
+		{"0", "201", ""},
+		{"100", "1", "Skipped 1 commits changing more than 100 files"},
+		{"200", "201", ""},
+	}
+	for _, tt := range tests {
+		stdout, stderr, code := runGitStat(t, r.dir, "--max-files-per-commit", tt.max, ".", "2024-03-01", "2024-03-02")
+		if code != 0 {
+			t.Fatalf("--max-files-per-commit %s exited with %d: %s", tt.max, code, stderr)
+		}
+		if row := tableRow(stdout, "Total"); row[1] != tt.files {
+			t.Errorf("--max-files-per-commit %s: %s files, want %s", tt.max, row[1], tt.files)
+		}
+		if tt.skipped == "" && strings.Contains(stderr, "Skipped") || !strings.Contains(stderr, tt.skipped) {
+			t.Errorf("--max-files-per-commit %s: stderr %q, want %q", tt.max, stderr, tt.skipped)
+		}
+	}
+}
+
+func TestCommitFilter(t *testing.T) {
+	r := newTestRepo(t)
+	for hour := 9; hour <= 12; hour++ {
+		author := "Alice <alice@example.com>"
+		if hour >= 11 {
+			author = "Bob <bob@example.com>"
+		}
+		r.commit(testCommit{
+			when:   fmt.Sprintf("2024-03-01T%02d:00:00Z", hour),
+			author: author,
+			files:  map[string]string{fmt.Sprintf("file%d", hour): lines(hour)},
+		})
+	}
+
+	evenHour := func(c *object.Commit) bool { return c.Author.When.Hour()%2 == 0 }
+	byBob := func(c *object.Commit) bool { return c.Author.Name == "Bob" }
+	tests := []struct {
+		name    string
+		filters []func(c *object.Commit) bool
+		commits int
+		changes int
+	}{
+		{"no filter", nil, 4, 9 + 10 + 11 + 12},
+		{"even hours", []func(c *object.Commit) bool{evenHour}, 2, 10 + 12},
+		{"even hours by Bob", []func(c *object.Commit) bool{evenHour, byBob}, 1, 12},
+	}
+	for _, tt := range tests {
+		opts, args, err := parseArgs([]string{r.dir, "2024-03-01", "2024-03-01"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, filter := range tt.filters {
+			opts.addCommitFilter(filter)
+		}
+		start, _ := parseDate(args[1])
+		end, _ := parseDate(args[2])
+
+		stats, err := getGitStats(r.repo, start, end, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		day := stats["2024-03-01"]
+		if day == nil || day.Commits != tt.commits || day.Changes != tt.changes {
+			t.Errorf("%s: %+v, want %d commits and %d changes", tt.name, day, tt.commits, tt.changes)
+		}
+	}
+}
+
+func TestExclusiveEnd(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T00:00:00Z", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-02T12:00:00Z", files: map[string]string{"a": lines(3)}})
+	r.commit(testCommit{when: "2024-03-03T00:00:00Z", files: map[string]string{"a": lines(6)}})
+	r.commit(testCommit{when: "2024-03-03T23:59:59Z", files: map[string]string{"a": lines(10)}})
+
+	tests := []struct {
+		flag    string
+		end     string
+		changes string
+		endDate string
+	}{
+		{"", "2024-03-03", "10", "2024-03-03"},
+		{"--exclusive-end", "2024-03-03", "3", "2024-03-02"},
+		{"--exclusive-end", "2024-03-04", "10", "2024-03-03"},
+	}
+	for _, tt := range tests {
+		run := func(format string) string {
+			args := []string{"--format", format, ".", "2024-03-01", tt.end}
+			if tt.flag != "" {
+				args = append([]string{tt.flag}, args...)
+			}
+			return mustRun(t, r.dir, args...)
+		}
+		if out := run("json"); !strings.Contains(out, `"end_date": "`+tt.endDate+`"`) {
+			t.Errorf("%q to %s: report does not end on %s:\n%s", tt.flag, tt.end, tt.endDate, out)
+		}
+		if row := tableRow(run("table"), "Total"); row[4] != tt.changes {
+			t.Errorf("%q to %s: %s changes, want %s", tt.flag, tt.end, row[4], tt.changes)
+		}
+	}
+
+	if _, stderr, code := runGitStat(t, r.dir, "--exclusive-end", ".", "2024-03-01", "2024-03-01"); code == 0 || !strings.Contains(stderr, "End date must be after start date with --exclusive-end") {
+		t.Errorf("an empty half-open range exited with %d: %s", code, stderr)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	r := newTestRepo(t)
+	head := r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	root, err := filepath.EvalSymlinks(r.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{
+			[]string{"--verbose", "--validate", "--branch", "master", root, "2024-03-01", "2024-03-07"},
+			0,
+			"Repository: " + root + "\nBranch:     master (" + head.String()[:7] + ")\nRange:      2024-03-01 ~ 03-07 (7 days)\n",
+			"",
+		},
+		{[]string{"--validate", "--branch", "mastr", ".", "2024-03-01", "2024-03-07"}, 1, "", `Error resolving branch: cannot resolve branch "mastr"`},
+		{[]string{"--validate", "missing", "2024-03-01", "2024-03-07"}, 1, "", "Error opening repository"},
+		{[]string{"--validate", ".", "2024-03-07", "2024-03-01"}, 1, "", "End date must be after start date"},
+	}
+	for _, tt := range tests {
+		stdout, stderr, code := runGitStat(t, r.dir, tt.args...)
+		if code != tt.code || tt.stdout != "" && stdout != tt.stdout || !strings.Contains(stderr, tt.stderr) {
+			t.Errorf("%v exited with %d:\n%s%s\nwant %d:\n%s%s", tt.args, code, stdout, stderr, tt.code, tt.stdout, tt.stderr)
+		}
+		if strings.Contains(stderr, "Debug: walk") {
+			t.Errorf("%v walked the history:\n%s", tt.args, stderr)
+		}
+	}
+}
+
+func TestIncludeInitialCommit(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(3), "b": lines(1)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": "line 1\nline two\n"}})
+
+	tests := []struct {
+		flag      string
+		files     string
+		additions string
+		deletions string
+	}{
+		{"", "2", "5", "2"},
+		{"--include-stats-for-initial-commit=true", "2", "5", "2"},
+		{"--include-stats-for-initial-commit=false", "1", "1", "2"},
+	}
+	for _, tt := range tests {
+		args := []string{".", "2024-03-01", "2024-03-02"}
+		if tt.flag != "" {
+			args = append([]string{tt.flag}, args...)
+		}
+		row := tableRow(mustRun(t, r.dir, args...), "Total")
+		if row[1] != tt.files || row[2] != tt.additions || row[3] != tt.deletions {
+			t.Errorf("%q: total %v, want %s files +%s -%s", tt.flag, row, tt.files, tt.additions, tt.deletions)
+		}
+	}
+}
+
+func TestConfigPrint(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	tests := []struct {
+		args []string
+		want map[string]any
+	}{
+		{nil, map[string]any{"RenameThreshold": 60.0, "Format": "table", "NoMerges": false, "Humanize": "", "Args": nil}},
+		{[]string{"--rename-threshold", "80", "--format", "csv"}, map[string]any{"RenameThreshold": 80.0, "Format": "csv"}},
+		{[]string{"--humanize=compact"}, map[string]any{"Humanize": "compact"}},
+		// Conflicting options are printed rather than rejected.
+		{[]string{"--no-merges", "--merges-only"}, map[string]any{"NoMerges": true, "MergesOnly": true}},
+		// The repository is not opened.
+		{[]string{missing, "2024-03-01", "2024-03-02"}, map[string]any{"Args": []any{missing, "2024-03-01", "2024-03-02"}}},
+	}
+	for _, tt := range tests {
+		out := mustRun(t, t.TempDir(), append([]string{"--config-print"}, tt.args...)...)
+		var config map[string]any
+		if err := json.Unmarshal([]byte(out), &config); err != nil {
+			t.Fatalf("%v: %v:\n%s", tt.args, err, out)
+		}
+		for key, want := range tt.want {
+			if got, ok := config[key]; !ok || !reflect.DeepEqual(got, want) {
+				t.Errorf("%v: %s is %#v, want %#v", tt.args, key, got, want)
+			}
+		}
+		if _, ok := config["CommitFilter"]; ok {
+			t.Errorf("%v: the commit filter is printed", tt.args)
+		}
+	}
+}
+
+func TestMinAdditions(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(20)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(22)}})
+	r.commit(testCommit{when: "2024-03-02T11:00:00Z", files: map[string]string{"a": lines(10)}})
+	r.commit(testCommit{when: "2024-03-03T10:00:00Z", files: map[string]string{"b": lines(12)}})
+
+	tests := []struct {
+		args      []string
+		additions string
+		deletions string
+		skipped   string
+	}{
+		{nil, "34", "12", ""},
+		{[]string{"--min-additions", "10"}, "32", "0", "Skipped 2 commits below --min-additions or --min-deletions"},
+		{[]string{"--min-additions", "2"}, "34", "0", "Skipped 1 commits"},
+		{[]string{"--min-deletions", "5"}, "0", "12", "Skipped 3 commits"},
+		{[]string{"--min-additions", "1", "--min-deletions", "1"}, "0", "0", "Skipped 4 commits"},
+	}
+	for _, tt := range tests {
+		stdout, stderr, code := runGitStat(t, r.dir, append(tt.args, ".", "2024-03-01", "2024-03-03")...)
+		if code != 0 {
+			t.Fatalf("%v exited with %d: %s", tt.args, code, stderr)
+		}
+		if row := tableRow(stdout, "Total"); row[2] != tt.additions || row[3] != tt.deletions {
+			t.Errorf("%v: +%s -%s, want +%s -%s", tt.args, row[2], row[3], tt.additions, tt.deletions)
+		}
+		if tt.skipped == "" && strings.Contains(stderr, "Skipped") || !strings.Contains(stderr, tt.skipped) {
+			t.Errorf("%v: stderr %q, want %q", tt.args, stderr, tt.skipped)
+		}
+	}
+
+	if stdout, stderr, code := runGitStat(t, r.dir, "--min-deletions", "-1", ".", "2024-03-01", "2024-03-03"); code == 0 || !strings.Contains(stderr, "must not be negative") {
+		t.Errorf("--min-deletions -1 exited with %d: %s%s", code, stdout, stderr)
+	}
+}
+
+func TestHeader(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(2)}})
+	r.checkout("topic")
+	topic := r.commit(testCommit{when: "2024-03-03T10:00:00Z", files: map[string]string{"a": lines(3)}})
+	r.checkout("master")
+	head := r.commit(testCommit{when: "2024-03-04T10:00:00Z", files: map[string]string{"b": lines(1)}})
+	root, err := filepath.EvalSymlinks(r.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args   []string
+		header string
+	}{
+		{
+			[]string{root, "2024-03-02", "2024-03-04"},
+			"Repository: " + root + "\nBranch:     master (" + head.String()[:7] + ")\nRange:      2024-03-02 ~ 03-04 (3 days)\nCommits:    2\n\n",
+		},
+		{
+			[]string{"--branch", "topic", root, "2024-03-01", "2024-03-07"},
+			"Repository: " + root + "\nBranch:     topic (" + topic.String()[:7] + ")\nRange:      2024-03-01 ~ 03-07 (7 days)\nCommits:    3\n\n",
+		},
+	}
+	for _, tt := range tests {
+		out := mustRun(t, r.dir, append([]string{"--header", "--primary-language=false"}, tt.args...)...)
+		if !strings.HasPrefix(out, tt.header) {
+			t.Errorf("%v: output starts with:\n%s\nwant:\n%s", tt.args, out, tt.header)
+		}
+		if table := strings.TrimPrefix(out, tt.header); table != mustRun(t, r.dir, append([]string{"--primary-language=false"}, tt.args...)...) {
+			t.Errorf("%v: the table after the header differs:\n%s", tt.args, table)
+		}
+	}
+
+	// Machine readable formats get no header.
+	if out := mustRun(t, r.dir, "--header", "--format", "csv", ".", "2024-03-01", "2024-03-04"); strings.Contains(out, "Repository:") {
+		t.Errorf("--format csv printed a header:\n%s", out)
+	}
+}
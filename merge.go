@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// directGroup collects the commits of --by-merge made on the branch itself
+// rather than brought in by a merge.
+const directGroup = "direct"
+
+// mergeGroups maps the commits brought into the branch by a merge to the
+// merge, named by its short hash and subject, for --by-merge. The branch is
+// followed along first parents back to startDate. Merges are handled oldest
+// first, and each takes the commits reachable from its other parents that
+// are neither on the branch nor taken by an earlier merge; a squash merge
+// made on the platform is an ordinary commit and ends up as direct.
+func mergeGroups(repo *git.Repository, from plumbing.Hash, startDate time.Time) (map[plumbing.Hash]string, map[plumbing.Hash]bool, error) {
+	mainline := make(map[plumbing.Hash]bool)
+	var merges []*object.Commit
+
+	c, err := repo.CommitObject(from)
+	if err != nil {
+		return nil, nil, err
+	}
+	for {
+		mainline[c.Hash] = true
+		if c.NumParents() > 1 {
+			merges = append(merges, c)
+		}
+		if c.NumParents() == 0 || c.Committer.When.Before(startDate) {
+			break
+		}
+		if c, err = c.Parent(0); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	groups := make(map[plumbing.Hash]string)
+	heads := make(map[plumbing.Hash]bool)
+	for i := len(merges) - 1; i >= 0; i-- {
+		merge := merges[i]
+		label := merge.Hash.String()[:7] + " " + strings.TrimSpace(strings.SplitN(merge.Message, "\n", 2)[0])
+
+		queue := merge.ParentHashes[1:]
+		for len(queue) > 0 {
+			hash := queue[0]
+			queue = queue[1:]
+			if mainline[hash] {
+				continue
+			}
+			if _, ok := groups[hash]; ok {
+				continue
+			}
+
+			c, err := repo.CommitObject(hash)
+			if err != nil {
+				return nil, nil, err
+			}
+			if c.Committer.When.Before(startDate) {
+				continue
+			}
+			groups[hash] = label
+			heads[merge.Hash] = true
+			queue = append(queue, c.ParentHashes...)
+		}
+	}
+
+	return groups, heads, nil
+}
+
+// getMergeStats aggregates the changes by the merge that brought each commit
+// into the branch. The merges themselves are left out, as their changes are
+// those of the commits they bring in; merges of nothing new count as direct.
+func getMergeStats(repo *git.Repository, startDate, endDate time.Time, opts *Options) (map[string]*DailyStats, error) {
+	from, _, err := resolveBranch(repo, opts.Branch)
+	if err != nil {
+		return nil, err
+	}
+	groups, heads, err := mergeGroups(repo, from, startDate)
+	if err != nil {
+		return nil, err
+	}
+
+	mergeOpts := *opts
+	mergeOpts.CommitFilter = func(c *object.Commit) bool {
+		return !heads[c.Hash] && (opts.CommitFilter == nil || opts.CommitFilter(c))
+	}
+
+	return getGroupStats(repo, startDate, endDate, &mergeOpts, func(c *object.Commit, stat object.FileStat) string {
+		if group, ok := groups[c.Hash]; ok {
+			return group
+		}
+		return directGroup
+	})
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// byMergeRepo returns a repository whose master merges two branches, with
+// the commits by name.
+func byMergeRepo(t *testing.T) (*testRepo, map[string]plumbing.Hash) {
+	t.Helper()
+
+	r := newTestRepo(t)
+	commits := make(map[string]plumbing.Hash)
+	commits["base"] = r.commit(testCommit{when: "2024-02-28T10:00:00Z", files: map[string]string{"a.txt": lines(1)}})
+	commits["direct"] = r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a.txt": lines(2)}})
+	r.checkout("feature")
+	commits["f1"] = r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"f.txt": lines(3)}})
+	commits["f2"] = r.commit(testCommit{when: "2024-03-03T10:00:00Z", files: map[string]string{"f.txt": lines(5)}})
+	r.checkout("master")
+	commits["m1"] = r.commit(testCommit{when: "2024-03-04T10:00:00Z", message: "Merge feature\n\nDetails.", files: map[string]string{"f.txt": lines(5)}, parents: []plumbing.Hash{commits["f2"]}})
+	r.checkout("second")
+	commits["s1"] = r.commit(testCommit{when: "2024-03-05T10:00:00Z", files: map[string]string{"g.txt": lines(4)}})
+	r.checkout("master")
+	commits["m2"] = r.commit(testCommit{when: "2024-03-06T10:00:00Z", message: "Merge second", files: map[string]string{"g.txt": lines(4)}, parents: []plumbing.Hash{commits["s1"]}})
+	// Merging feature again brings in nothing new.
+	commits["m3"] = r.commit(testCommit{when: "2024-03-07T10:00:00Z", message: "Merge feature again", parents: []plumbing.Hash{commits["f2"]}})
+	return r, commits
+}
+
+func TestMergeGroups(t *testing.T) {
+	r, commits := byMergeRepo(t)
+	label := func(merge, subject string) string {
+		return commits[merge].String()[:7] + " " + subject
+	}
+
+	tests := []struct {
+		startDate string
+		groups    map[string]string // by commit name
+		heads     []string
+	}{
+		{"2024-03-01", map[string]string{
+			"f1": label("m1", "Merge feature"),
+			"f2": label("m1", "Merge feature"),
+			"s1": label("m2", "Merge second"),
+		}, []string{"m1", "m2"}},
+		// Commits before the start date belong to no merge.
+		{"2024-03-03", map[string]string{
+			"f2": label("m1", "Merge feature"),
+			"s1": label("m2", "Merge second"),
+		}, []string{"m1", "m2"}},
+	}
+	for _, tt := range tests {
+		startDate, err := time.Parse(time.DateOnly, tt.startDate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		groups, heads, err := mergeGroups(r.repo, commits["m3"], startDate)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := make(map[string]string)
+		gotHeads := make(map[string]bool)
+		for name, hash := range commits {
+			if group, ok := groups[hash]; ok {
+				got[name] = group
+			}
+			if heads[hash] {
+				gotHeads[name] = true
+			}
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.groups) {
+			t.Errorf("since %s: groups %v, want %v", tt.startDate, got, tt.groups)
+		}
+		if len(gotHeads) != len(tt.heads) {
+			t.Errorf("since %s: heads %v, want %v", tt.startDate, gotHeads, tt.heads)
+		}
+		for _, name := range tt.heads {
+			if !gotHeads[name] {
+				t.Errorf("since %s: heads %v, want %v", tt.startDate, gotHeads, tt.heads)
+			}
+		}
+	}
+}
+
+func TestByMerge(t *testing.T) {
+	r, commits := byMergeRepo(t)
+	label := func(merge, subject string) string {
+		return commits[merge].String()[:7] + " " + subject
+	}
+
+	out := mustRun(t, r.dir, "--by-merge", ".", "2024-03-01", "2024-03-07")
+	tests := []struct {
+		label string
+		want  []string // additions and commits, none for no row
+	}{
+		{label("m1", "Merge feature"), []string{"5", "2"}},
+		{label("m2", "Merge second"), []string{"4", "1"}},
+		// The merge of nothing new is direct, without changes of its own.
+		{directGroup, []string{"1", "1"}},
+		{label("m3", "Merge feature again"), nil},
+	}
+	for _, tt := range tests {
+		row := tableRow(out, tt.label)
+		if tt.want == nil {
+			if row != nil {
+				t.Errorf("%s: %v, want no row", tt.label, row)
+			}
+			continue
+		}
+		if row == nil || row[2] != tt.want[0] || row[len(row)-1] != tt.want[1] {
+			t.Errorf("%s: %v, want +%s in %s commits:\n%s", tt.label, row, tt.want[0], tt.want[1], out)
+		}
+	}
+
+	_, stderr, code := runGitStat(t, r.dir, "--by-merge", "--by-author", ".", "2024-03-01", "2024-03-07")
+	if code == 0 || !strings.Contains(stderr, "--after-hours-report and --by-merge can be used") {
+		t.Errorf("--by-merge --by-author exited with %d: %s", code, stderr)
+	}
+}
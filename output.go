@@ -0,0 +1,341 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// renderers maps the names accepted by --format to the function writing the
+// report in that format.
+var renderers = map[string]func(w io.Writer, report *Report) error{
+	"table":      writeTable,
+	"json":       writeJSON,
+	"csv":        writeCSV,
+	"tsv":        writeTSV,
+	"prometheus": writePrometheus,
+	"html":       writeHTML,
+	"svg":        writeSVG,
+}
+
+// formatRoute is a format of --format and the file it is written to, empty
+// for stdout.
+type formatRoute struct {
+	format string
+	file   string
+}
+
+// routeFormats decides where each of the comma separated formats of --format
+// goes. A single format is written to --output if given, else to stdout.
+// With several formats the table goes to stdout and the other format to
+// --output, so a run can be viewed and archived at once; two formats may not
+// end up in the same place. The route to stdout, if any, comes first.
+func routeFormats(format, output string) ([]formatRoute, error) {
+	formats := strings.Split(format, ",")
+	for i, name := range formats {
+		formats[i] = strings.TrimSpace(name)
+		_, streamed := renderers[formats[i]]
+		_, toFile := fileRenderers[formats[i]]
+		standalone := formats[i] == formatCommitsJSON || formats[i] == formatAuthorsJSON
+		if !streamed && !toFile && !standalone {
+			return nil, fmt.Errorf("unknown format %q", formats[i])
+		}
+		if standalone && len(formats) > 1 {
+			return nil, fmt.Errorf("--format %s cannot be combined with other formats", formats[i])
+		}
+	}
+
+	if len(formats) == 1 {
+		if _, ok := fileRenderers[formats[0]]; ok && output == "" {
+			return nil, fmt.Errorf("--format %s needs --output", formats[0])
+		}
+		return []formatRoute{{formats[0], output}}, nil
+	}
+
+	var routes []formatRoute
+	destinations := make(map[string]string)
+	for _, name := range formats {
+		file := output
+		if name == "table" {
+			file = ""
+		}
+		if other, ok := destinations[file]; ok {
+			where := "stdout"
+			if file != "" {
+				where = file
+			}
+			return nil, fmt.Errorf("--format %s and %s would both be written to %s", other, name, where)
+		}
+		destinations[file] = name
+
+		if file == "" {
+			routes = append([]formatRoute{{name, file}}, routes...)
+		} else {
+			routes = append(routes, formatRoute{name, file})
+		}
+	}
+	return routes, nil
+}
+
+// reportFiles lists the files written by --output-dir and their format.
+var reportFiles = []struct {
+	name   string
+	format string
+}{
+	{"report.txt", "table"},
+	{"report.json", "json"},
+	{"report.csv", "csv"},
+}
+
+type jsonDay struct {
+	Date         string   `json:"date"`
+	FilesChanged int      `json:"files_changed"`
+	Additions    int      `json:"additions"`
+	Deletions    int      `json:"deletions"`
+	TotalChanges int      `json:"total_changes"`
+	Commits      int      `json:"commits"`
+	Tags         []string `json:"tags,omitempty"`
+}
+
+type jsonTotal struct {
+	FilesChanged int `json:"files_changed"`
+	Additions    int `json:"additions"`
+	Deletions    int `json:"deletions"`
+	TotalChanges int `json:"total_changes"`
+	Commits      int `json:"commits"`
+}
+
+type jsonReport struct {
+	StartDate       string    `json:"start_date"`
+	EndDate         string    `json:"end_date"`
+	PrimaryLanguage string    `json:"primary_language,omitempty"`
+	Days            []jsonDay `json:"days"`
+	Total           jsonTotal `json:"total"`
+}
+
+var csvHeader = []string{"date", "files_changed", "additions", "deletions", "total_changes", "commits"}
+
+var ansiPattern = regexp.MustCompile("\033\\[[0-9;]*m|\033\\]8;;[^\033]*\033\\\\")
+
+// stripANSI removes the color escape sequences and the OSC 8 hyperlinks of
+// --commit-url from text, keeping the text of the links.
+func stripANSI(text string) string {
+	return ansiPattern.ReplaceAllString(text, "")
+}
+
+// showsOnTerminal reports whether the first route of the report is shown on
+// a terminal, given whether stdout is one. What is copied with --clipboard
+// is pasted elsewhere, so it is rendered like output to a file even then.
+// With --tee the report is watched on stdout, and plainWriter leaves the
+// colors out of the copy in the file.
+func showsOnTerminal(route formatRoute, opts *Options, stdoutTerminal bool) bool {
+	if opts.Tee {
+		return stdoutTerminal
+	}
+	return route.file == "" && opts.OutputDir == "" && !opts.Clipboard && stdoutTerminal
+}
+
+// isTerminal reports whether file is a terminal rather than a pipe or a
+// regular file.
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// sortedDates returns the days of dailyStats in chronological order.
+func sortedDates(dailyStats map[string]*DailyStats) []string {
+	dates := make([]string, 0, len(dailyStats))
+	for date := range dailyStats {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	return dates
+}
+
+func writeTable(w io.Writer, report *Report) error {
+	if report.Options.TopDays > 0 {
+		printTopDaysTable(w, report, report.Options.TopDays)
+		return nil
+	}
+	printDailyTable(w, report)
+	return nil
+}
+
+// newJSONReport converts the report to the structure written by --format
+// json.
+func newJSONReport(report *Report) jsonReport {
+	out := jsonReport{
+		StartDate:       report.StartDate.Format("2006-01-02"),
+		EndDate:         report.EndDate.Format("2006-01-02"),
+		PrimaryLanguage: report.PrimaryLanguage,
+		Days:            []jsonDay{},
+	}
+
+	for _, date := range sortedDates(report.DailyStats) {
+		stats := report.DailyStats[date]
+		out.Days = append(out.Days, jsonDay{
+			Date:         date,
+			FilesChanged: len(stats.FilesChanged),
+			Additions:    stats.Additions,
+			Deletions:    stats.Deletions,
+			TotalChanges: stats.Changes,
+			Commits:      stats.Commits,
+			Tags:         report.TagDates[date],
+		})
+	}
+
+	total := totalStats(report)
+	out.Total = jsonTotal{
+		FilesChanged: len(total.FilesChanged),
+		Additions:    total.Additions,
+		Deletions:    total.Deletions,
+		TotalChanges: total.Changes,
+		Commits:      total.Commits,
+	}
+
+	return out
+}
+
+func writeJSON(w io.Writer, report *Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(newJSONReport(report))
+}
+
+func writeCSV(w io.Writer, report *Report) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, date := range sortedDates(report.DailyStats) {
+		if err := cw.Write(csvRow(date, report.DailyStats[date])); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeTSV writes the same columns as writeCSV separated by tabs. Fields are
+// never quoted; tabs and line breaks within them are replaced by spaces.
+func writeTSV(w io.Writer, report *Report) error {
+	if err := writeTSVLine(w, csvHeader); err != nil {
+		return err
+	}
+	for _, date := range sortedDates(report.DailyStats) {
+		if err := writeTSVLine(w, csvRow(date, report.DailyStats[date])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var tsvReplacer = strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ")
+
+func writeTSVLine(w io.Writer, fields []string) error {
+	escaped := make([]string, len(fields))
+	for i, field := range fields {
+		escaped[i] = tsvReplacer.Replace(field)
+	}
+	_, err := fmt.Fprintf(w, "%s\n", strings.Join(escaped, "\t"))
+	return err
+}
+
+func csvRow(date string, stats *DailyStats) []string {
+	return []string{
+		date,
+		strconv.Itoa(len(stats.FilesChanged)),
+		strconv.Itoa(stats.Additions),
+		strconv.Itoa(stats.Deletions),
+		strconv.Itoa(stats.Changes),
+		strconv.Itoa(stats.Commits),
+	}
+}
+
+// appendCSV adds the days of the report that filename does not have yet to
+// its end, for building up a time series with scheduled runs. Days already
+// in the file are kept as they are. A missing or empty file is started with
+// the header.
+func appendCSV(filename string, report *Report) error {
+	file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	if len(records) > 0 && !slices.Equal(records[0], csvHeader) {
+		return fmt.Errorf("%s does not start with the header written by --format csv", filename)
+	}
+
+	existing := make(map[string]bool, len(records))
+	for _, record := range records[min(len(records), 1):] {
+		existing[record[0]] = true
+	}
+
+	cw := csv.NewWriter(file)
+	if len(records) == 0 {
+		if err := cw.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+
+	added := 0
+	for _, date := range sortedDates(report.DailyStats) {
+		if existing[date] {
+			continue
+		}
+		if err := cw.Write(csvRow(date, report.DailyStats[date])); err != nil {
+			return err
+		}
+		added++
+	}
+	debugf("appended %d days to %s", added, filename)
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// writeReportFiles writes the report in every format into dir, creating it
+// if needed. Color codes are left out of the text report.
+func writeReportFiles(dir string, report *Report) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	for _, file := range reportFiles {
+		var buf bytes.Buffer
+		if err := renderers[file.format](&buf, report); err != nil {
+			return err
+		}
+
+		content := buf.String()
+		if file.format == "table" {
+			content = stripANSI(content)
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, file.name), []byte(content), 0o644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestOutputDir(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(3), "b": lines(1)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(5)}})
+	r.commit(testCommit{when: "2024-03-02T11:00:00Z", files: map[string]string{"b": ""}})
+
+	dir := filepath.Join(t.TempDir(), "reports")
+	if out := mustRun(t, r.dir, "--output-dir", dir, ".", "2024-03-01", "2024-03-02"); out != "" {
+		t.Errorf("--output-dir printed %q to stdout", out)
+	}
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	var report struct {
+		Days []struct {
+			Date         string `json:"date"`
+			FilesChanged int    `json:"files_changed"`
+			Additions    int    `json:"additions"`
+			Deletions    int    `json:"deletions"`
+			TotalChanges int    `json:"total_changes"`
+		} `json:"days"`
+	}
+	jsonReport := read("report.json")
+	if err := json.Unmarshal([]byte(jsonReport), &report); err != nil {
+		t.Fatalf("report.json: %v", err)
+	}
+	if want := mustRun(t, r.dir, "--format", "json", ".", "2024-03-01", "2024-03-02"); jsonReport != want {
+		t.Errorf("report.json differs from --format json:\n%s\nwant:\n%s", jsonReport, want)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(read("report.csv"))).ReadAll()
+	if err != nil {
+		t.Fatalf("report.csv: %v", err)
+	}
+	table := read("report.txt")
+
+	tests := []struct {
+		date string
+		want []int // files changed, additions, deletions and total changes
+	}{
+		{"2024-03-01", []int{2, 4, 0, 4}},
+		{"2024-03-02", []int{2, 2, 1, 3}},
+	}
+	if len(report.Days) != len(tests) || len(records) != len(tests)+1 {
+		t.Fatalf("report.json has %d days and report.csv %d rows, want %d", len(report.Days), len(records)-1, len(tests))
+	}
+	for i, tt := range tests {
+		day := report.Days[i]
+		if got := []int{day.FilesChanged, day.Additions, day.Deletions, day.TotalChanges}; day.Date != tt.date || !slices.Equal(got, tt.want) {
+			t.Errorf("report.json: %s %v, want %s %v", day.Date, got, tt.date, tt.want)
+		}
+		if got := atois(t, records[i+1][1:5]); records[i+1][0] != tt.date || !slices.Equal(got, tt.want) {
+			t.Errorf("report.csv: %s %v, want %s %v", records[i+1][0], got, tt.date, tt.want)
+		}
+		row := tableRow(table, tt.date)
+		if row == nil {
+			t.Errorf("report.txt has no row for %s:\n%s", tt.date, table)
+			continue
+		}
+		if got := atois(t, row[1:5]); !slices.Equal(got, tt.want) {
+			t.Errorf("report.txt: %s %v, want %v", tt.date, got, tt.want)
+		}
+	}
+}
+
+func atois(t *testing.T, cells []string) []int {
+	t.Helper()
+
+	numbers := make([]int, len(cells))
+	for i, cell := range cells {
+		n, err := strconv.Atoi(cell)
+		if err != nil {
+			t.Fatalf("%q is not a number", cell)
+		}
+		numbers[i] = n
+	}
+	return numbers
+}
+
+func TestRouteFormats(t *testing.T) {
+	tests := []struct {
+		format, output string
+		want           []formatRoute
+		err            string
+	}{
+		{"table", "", []formatRoute{{"table", ""}}, ""},
+		{"json", "out.json", []formatRoute{{"json", "out.json"}}, ""},
+		{"sqlite", "", nil, "--format sqlite needs --output"},
+		{"table,json", "out.json", []formatRoute{{"table", ""}, {"json", "out.json"}}, ""},
+		{" json , table ", "out.json", []formatRoute{{"table", ""}, {"json", "out.json"}}, ""},
+		{"table,sqlite", "stats.db", []formatRoute{{"table", ""}, {"sqlite", "stats.db"}}, ""},
+		{"table,json", "", nil, "--format table and json would both be written to stdout"},
+		{"json,csv", "out", nil, "--format json and csv would both be written to out"},
+		{"table,table", "out", nil, "--format table and table would both be written to stdout"},
+		{"table,xml", "out", nil, `unknown format "xml"`},
+	}
+	for _, tt := range tests {
+		routes, err := routeFormats(tt.format, tt.output)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("routeFormats(%q, %q): %v, want %q", tt.format, tt.output, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || !slices.Equal(routes, tt.want) {
+			t.Errorf("routeFormats(%q, %q) = %v, %v, want %v", tt.format, tt.output, routes, err, tt.want)
+		}
+	}
+}
+
+func TestMultipleFormats(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(3), "b": lines(1)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(5)}})
+
+	table := mustRun(t, r.dir, ".", "2024-03-01", "2024-03-02")
+	tests := []struct {
+		format string
+		other  string
+	}{
+		{"table,json", "json"},
+		{"json,table", "json"},
+		{"table,csv", "csv"},
+		{"table,prometheus", "prometheus"},
+	}
+	for _, tt := range tests {
+		output := filepath.Join(t.TempDir(), "report")
+		if out := mustRun(t, r.dir, "--format", tt.format, "--output", output, ".", "2024-03-01", "2024-03-02"); out != table {
+			t.Errorf("--format %s printed:\n%s\nwant the table:\n%s", tt.format, out, table)
+		}
+		data, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := mustRun(t, r.dir, "--format", tt.other, ".", "2024-03-01", "2024-03-02"); string(data) != want {
+			t.Errorf("--format %s wrote:\n%s\nwant the output of --format %s:\n%s", tt.format, data, tt.other, want)
+		}
+	}
+
+	if stdout, stderr, code := runGitStat(t, r.dir, "--format", "table,json", ".", "2024-03-01", "2024-03-02"); code == 0 || !strings.Contains(stdout+stderr, "would both be written to stdout") {
+		t.Errorf("--format table,json without --output exited with %d: %s%s", code, stdout, stderr)
+	}
+}
+
+func TestAppendCSV(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(3)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(5)}})
+	r.commit(testCommit{when: "2024-03-03T10:00:00Z", files: map[string]string{"b": lines(2)}})
+
+	// rows holds the header and the row of every day, as --format csv writes
+	// them.
+	rows := strings.SplitAfter(mustRun(t, r.dir, "--format", "csv", ".", "2024-03-01", "2024-03-03"), "\n")
+	header, day1, day2, day3 := rows[0], rows[1], rows[2], rows[3]
+	kept := "2024-03-02,9,9,9,9,9\n"
+
+	tests := []struct {
+		name     string
+		existing *string
+		from, to string
+		want     string
+	}{
+		{"missing file", nil, "2024-03-01", "2024-03-02", header + day1 + day2},
+		{"empty file", new(string), "2024-03-02", "2024-03-03", header + day2 + day3},
+		{"new days only", ptr(header + day1), "2024-03-01", "2024-03-03", header + day1 + day2 + day3},
+		{"existing days kept", ptr(header + day1 + kept), "2024-03-01", "2024-03-03", header + day1 + kept + day3},
+		{"nothing new", ptr(header + day1 + day2 + day3), "2024-03-02", "2024-03-03", header + day1 + day2 + day3},
+	}
+	for _, tt := range tests {
+		output := filepath.Join(t.TempDir(), "stats.csv")
+		if tt.existing != nil {
+			if err := os.WriteFile(output, []byte(*tt.existing), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		mustRun(t, r.dir, "--append", "--format", "csv", "--output", output, ".", tt.from, tt.to)
+		data, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != tt.want {
+			t.Errorf("%s: wrote:\n%s\nwant:\n%s", tt.name, data, tt.want)
+		}
+	}
+
+	other := filepath.Join(t.TempDir(), "other.csv")
+	if err := os.WriteFile(other, []byte("day,count\n2024-03-01,1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	failures := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--append", "--format", "csv"}, "--append needs --format csv and --output"},
+		{[]string{"--append", "--format", "json", "--output", other}, "--append needs --format csv and --output"},
+		{[]string{"--append", "--format", "csv", "--output", other}, "does not start with the header"},
+	}
+	for _, tt := range failures {
+		stdout, stderr, code := runGitStat(t, r.dir, append(tt.args, ".", "2024-03-01", "2024-03-03")...)
+		if code == 0 || !strings.Contains(stderr, tt.want) {
+			t.Errorf("%v exited with %d: %s%s\nwant the error %q", tt.args, code, stdout, stderr, tt.want)
+		}
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestWriteTSVLine(t *testing.T) {
+	tests := []struct {
+		fields []string
+		want   string
+	}{
+		{[]string{"2024-03-01", "1", "2"}, "2024-03-01\t1\t2\n"},
+		{[]string{"a\tb", "c"}, "a b\tc\n"},
+		{[]string{"one\ntwo", "three\r\nfour", "five\rsix"}, "one two\tthree four\tfive six\n"},
+		{[]string{`"quoted", with commas`}, "\"quoted\", with commas\n"},
+	}
+	for _, tt := range tests {
+		var b strings.Builder
+		if err := writeTSVLine(&b, tt.fields); err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != tt.want {
+			t.Errorf("writeTSVLine(%q) wrote %q, want %q", tt.fields, b.String(), tt.want)
+		}
+	}
+}
+
+func TestTSV(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(3), "b": lines(1)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(5)}})
+	r.commit(testCommit{when: "2024-03-04T10:00:00Z", files: map[string]string{"b": ""}})
+
+	tests := []struct {
+		from, to string
+	}{
+		{"2024-03-01", "2024-03-04"},
+		{"2024-03-02", "2024-03-02"},
+		{"2024-03-03", "2024-03-03"},
+	}
+	for _, tt := range tests {
+		out := mustRun(t, r.dir, "--format", "tsv", ".", tt.from, tt.to)
+		want := strings.ReplaceAll(mustRun(t, r.dir, "--format", "csv", ".", tt.from, tt.to), ",", "\t")
+		if out != want {
+			t.Errorf("%s ~ %s: --format tsv printed:\n%s\nwant the columns of --format csv:\n%s", tt.from, tt.to, out, want)
+		}
+		if !strings.HasPrefix(out, strings.Join(csvHeader, "\t")+"\n") {
+			t.Errorf("%s ~ %s: no header in:\n%s", tt.from, tt.to, out)
+		}
+	}
+}
+
+func TestOutputWithoutColors(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-02-28T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(3)}})
+	r.commit(testCommit{when: "2024-03-03T10:00:00Z", files: map[string]string{"a": lines(2)}})
+
+	tests := [][]string{
+		nil,
+		{"--indent", "2"},
+		{"--format", "table"},
+	}
+	for _, flags := range tests {
+		// The banner of 03-02 and the deltas are colored on stdout.
+		args := append(append([]string{"--show-delta"}, flags...), ".", "2024-03-01", "2024-03-03")
+		table := mustRun(t, r.dir, args...)
+		if !strings.Contains(table, "\x1b[") {
+			t.Fatalf("%v: no colors to leave out:\n%s", flags, table)
+		}
+
+		output := filepath.Join(t.TempDir(), "report")
+		if out := mustRun(t, r.dir, append([]string{"--output", output}, args...)...); out != "" {
+			t.Errorf("%v: printed %q to stdout", flags, out)
+		}
+		data, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "\x1b[") || string(data) != stripANSI(table) {
+			t.Errorf("%v: wrote\n%q\nwant the table without colors:\n%q", flags, data, stripANSI(table))
+		}
+	}
+}
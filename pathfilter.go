@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+const (
+	// renamesFollow counts a file moved across the --path boundary by where
+	// it ended up: moved in, it counts; moved out, it is left out entirely
+	// rather than counted as a deletion.
+	renamesFollow = "follow"
+	// renamesDrop leaves out files moved across the --path boundary in
+	// either direction.
+	renamesDrop = "drop"
+)
+
+// normalizePath turns a --path argument into the slash separated form used
+// by git, without leading "./" or trailing slashes.
+func normalizePath(p string) string {
+	p = path.Clean(strings.ReplaceAll(p, "\\", "/"))
+	if p == "." {
+		return ""
+	}
+	return strings.TrimPrefix(p, "/")
+}
+
+// inPaths reports whether the file lies within one of paths.
+func inPaths(file string, paths []string) bool {
+	for _, p := range paths {
+		if p == "" || file == p || strings.HasPrefix(file, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// splitRename splits a stat name of the form "old => new" into its two
+// paths. Other names are returned as both.
+func splitRename(name string) (from, to string) {
+	if from, to, ok := strings.Cut(name, " => "); ok {
+		return from, to
+	}
+	return name, name
+}
+
+// filterPaths keeps the stats of the files within opts.Paths. Renames are
+// matched on both ends and handled according to opts.PathRenames.
+func filterPaths(stats object.FileStats, opts *Options) object.FileStats {
+	if len(opts.Paths) == 0 {
+		return stats
+	}
+
+	var filtered object.FileStats
+	for _, stat := range stats {
+		from, to := splitRename(stat.Name)
+		if keepPath(from, to, opts) {
+			filtered = append(filtered, stat)
+		}
+	}
+	return filtered
+}
+
+// keepPath reports whether a file that moved from one path to another (the
+// same for changes other than renames) passes the path filter.
+func keepPath(from, to string, opts *Options) bool {
+	fromIn, toIn := inPaths(from, opts.Paths), inPaths(to, opts.Paths)
+	if fromIn != toIn && opts.PathRenames == renamesDrop {
+		return false
+	}
+	return toIn
+}
+
+func validateRenames(mode string) error {
+	if mode != renamesFollow && mode != renamesDrop {
+		return fmt.Errorf("unknown --path-renames mode %q, expected %s or %s", mode, renamesFollow, renamesDrop)
+	}
+	return nil
+}
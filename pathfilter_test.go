@@ -0,0 +1,88 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestNormalizePath(t *testing.T) {
+	tests := []struct{ path, want string }{
+		{"src", "src"},
+		{"./src/", "src"},
+		{"/src//lib", "src/lib"},
+		{`src\lib`, "src/lib"},
+		{".", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizePath(tt.path); got != tt.want {
+			t.Errorf("normalizePath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestFilterPaths(t *testing.T) {
+	stats := object.FileStats{
+		{Name: "src/a.go"},
+		{Name: "srcs/b.go"},
+		{Name: "lib/c.go"},
+		{Name: "lib/d.go => src/d.go"},
+		{Name: "src/e.go => lib/e.go"},
+		{Name: "src/f.go => src/g/f.go"},
+	}
+
+	tests := []struct {
+		paths   []string
+		renames string
+		want    string
+	}{
+		{nil, renamesFollow, "src/a.go, srcs/b.go, lib/c.go, lib/d.go => src/d.go, src/e.go => lib/e.go, src/f.go => src/g/f.go"},
+		{[]string{"src"}, renamesFollow, "src/a.go, lib/d.go => src/d.go, src/f.go => src/g/f.go"},
+		{[]string{"src"}, renamesDrop, "src/a.go, src/f.go => src/g/f.go"},
+		{[]string{"lib"}, renamesFollow, "lib/c.go, src/e.go => lib/e.go"},
+		{[]string{"lib", "src/g"}, renamesFollow, "lib/c.go, src/e.go => lib/e.go, src/f.go => src/g/f.go"},
+		{[]string{"src/a.go"}, renamesFollow, "src/a.go"},
+	}
+	for _, tt := range tests {
+		filtered := filterPaths(stats, &Options{Paths: tt.paths, PathRenames: tt.renames})
+		var names []string
+		for _, stat := range filtered {
+			names = append(names, stat.Name)
+		}
+		if got := strings.Join(names, ", "); got != tt.want {
+			t.Errorf("%v with %s: %q, want %q", tt.paths, tt.renames, got, tt.want)
+		}
+	}
+}
+
+func TestPathRenames(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"src/a": lines(10), "lib/b": lines(20)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{
+		"src/a": "", "lib/a": lines(11), // moved out of src with a line added
+		"lib/b": "", "src/b": lines(22), // moved into src with two lines added
+	}})
+
+	tests := []struct {
+		renames string
+		row     []string // files changed, additions and deletions on the day of the moves
+	}{
+		{renamesFollow, []string{"1", "2", "0"}},
+		{renamesDrop, nil},
+	}
+	for _, tt := range tests {
+		out := mustRun(t, r.dir, "--path", "src", "--path-renames", tt.renames, ".", "2024-03-01", "2024-03-02")
+		row := tableRow(out, "2024-03-02")
+		if row != nil {
+			row = row[1:4]
+		}
+		if strings.Join(row, " ") != strings.Join(tt.row, " ") {
+			t.Errorf("--path-renames %s: %q on the day of the moves, want %q", tt.renames, row, tt.row)
+		}
+	}
+
+	if stdout, stderr, code := runGitStat(t, r.dir, "--path", "src", "--path-renames", "keep", ".", "2024-03-01", "2024-03-02"); code == 0 || !strings.Contains(stdout+stderr, "unknown --path-renames mode") {
+		t.Errorf("--path-renames keep exited with %d: %s%s", code, stdout, stderr)
+	}
+}
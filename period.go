@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	periodDay   = "day"
+	periodWeek  = "week"
+	periodMonth = "month"
+
+	// periodSprint is a week counted from the start of the range, used
+	// for --period week with --relative-weeks.
+	periodSprint = "sprint"
+)
+
+// period is a span of whole days, both ends included.
+type period struct {
+	Start time.Time
+	End   time.Time
+}
+
+func validatePeriod(name string) error {
+	switch name {
+	case periodDay, periodWeek, periodMonth:
+		return nil
+	}
+	return fmt.Errorf("unknown period %q, expected %s, %s or %s", name, periodDay, periodWeek, periodMonth)
+}
+
+// splitPeriods cuts the range from startDate to endDate into consecutive
+// periods. Weeks start on Monday, sprints on the weekday of startDate and
+// months on the first; the first and last period are clipped to the range.
+func splitPeriods(startDate, endDate time.Time, name string) []period {
+	var periods []period
+
+	for start := startDate; !start.After(endDate); {
+		var next time.Time
+		switch name {
+		case periodWeek:
+			offset := (int(start.Weekday()) + 6) % 7 // days since Monday
+			next = start.AddDate(0, 0, 7-offset)
+		case periodSprint:
+			next = start.AddDate(0, 0, 7)
+		case periodMonth:
+			next = time.Date(start.Year(), start.Month()+1, 1, 0, 0, 0, 0, start.Location())
+		default:
+			next = start.AddDate(0, 0, 1)
+		}
+
+		end := next.AddDate(0, 0, -1)
+		if end.After(endDate) {
+			end = endDate
+		}
+
+		periods = append(periods, period{start, end})
+		start = next
+	}
+
+	return periods
+}
+
+// label names the i-th period in table rows, counting from zero.
+func (p period) label(name string, i int) string {
+	switch name {
+	case periodDay:
+		return p.Start.Format("2006-01-02")
+	case periodMonth:
+		return p.Start.Format("2006-01")
+	case periodSprint:
+		return fmt.Sprintf("Week %d", i+1)
+	}
+	return formatDateRange(p.Start, p.End)
+}
+
+// parseDateRange parses a `start..end` range of days, as taken by
+// --exclude-range.
+func parseDateRange(value string) (period, error) {
+	startStr, endStr, ok := strings.Cut(value, "..")
+	if !ok {
+		return period{}, fmt.Errorf("expected start..end, got %q", value)
+	}
+
+	start, err := parseDate(strings.TrimSpace(startStr))
+	if err != nil {
+		return period{}, err
+	}
+	end, err := parseDate(strings.TrimSpace(endStr))
+	if err != nil {
+		return period{}, err
+	}
+	if end.Before(start) {
+		return period{}, fmt.Errorf("range %q ends before it starts", value)
+	}
+
+	return period{start, end}, nil
+}
+
+// contains reports whether the day of t falls within the period.
+func (p period) contains(t time.Time) bool {
+	day, _ := parseDate(t.Format("2006-01-02"))
+	return !day.Before(p.Start) && !day.After(p.End)
+}
+
+// excluded reports whether t falls within one of the --exclude-range
+// periods.
+func excluded(t time.Time, ranges []period) bool {
+	for _, p := range ranges {
+		if p.contains(t) {
+			return true
+		}
+	}
+	return false
+}
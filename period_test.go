@@ -0,0 +1,180 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDateRange(t *testing.T) {
+	tests := []struct {
+		value      string
+		start, end string
+		err        string
+	}{
+		{"2024-03-10..2024-03-12", "2024-03-10", "2024-03-12", ""},
+		{" 2024-03-10 .. 2024-03-10 ", "2024-03-10", "2024-03-10", ""},
+		{"2024-03-10", "", "", "expected start..end"},
+		{"2024-03-12..2024-03-10", "", "", "ends before it starts"},
+		{"2024-03-10..soon", "", "", "cannot parse"},
+	}
+	for _, tt := range tests {
+		p, err := parseDateRange(tt.value)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("parseDateRange(%q): %v, want an error containing %q", tt.value, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseDateRange(%q): %v", tt.value, err)
+			continue
+		}
+		if got := p.Start.Format("2006-01-02") + " " + p.End.Format("2006-01-02"); got != tt.start+" "+tt.end {
+			t.Errorf("parseDateRange(%q) = %s, want %s %s", tt.value, got, tt.start, tt.end)
+		}
+	}
+}
+
+func TestExcluded(t *testing.T) {
+	freeze, err := parseDateRange("2024-03-10..2024-03-12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ranges := []period{freeze}
+
+	tests := []struct {
+		when string
+		want bool
+	}{
+		{"2024-03-09T23:59:59Z", false},
+		{"2024-03-10T00:00:00Z", true},
+		{"2024-03-12T23:59:59Z", true},
+		{"2024-03-12T23:30:00-05:00", true}, // the author's day, not UTC's
+		{"2024-03-13T00:00:00Z", false},
+	}
+	for _, tt := range tests {
+		when, err := time.Parse(time.RFC3339, tt.when)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := excluded(when, ranges); got != tt.want {
+			t.Errorf("excluded(%s) = %t, want %t", tt.when, got, tt.want)
+		}
+	}
+}
+
+func TestExcludeRange(t *testing.T) {
+	r := newTestRepo(t)
+	for day, n := range map[string]int{"2024-03-09": 1, "2024-03-10": 2, "2024-03-11": 3, "2024-03-12": 4, "2024-03-13": 5} {
+		r.commit(testCommit{when: day + "T10:00:00Z", files: map[string]string{day: lines(n)}})
+	}
+
+	tests := []struct {
+		ranges  []string
+		changes string
+		banner  string
+	}{
+		{nil, "15", ""},
+		{[]string{"2024-03-10..2024-03-12"}, "6", "2024-03-10 ~ 03-12"},
+		{[]string{"2024-03-10..2024-03-10", "2024-03-12..2024-03-13"}, "4", "2024-03-12 ~ 03-13"},
+	}
+	for _, tt := range tests {
+		var args []string
+		for _, rng := range tt.ranges {
+			args = append(args, "--exclude-range", rng)
+		}
+		out := stripANSI(mustRun(t, r.dir, append(args, ".", "2024-03-09", "2024-03-13")...))
+
+		if row := tableRow(out, "Total"); row[4] != tt.changes {
+			t.Errorf("%v: %s changes, want %s", tt.ranges, row[4], tt.changes)
+		}
+		if tt.banner != "" {
+			if row := tableRow(out, tt.banner); row == nil || !strings.HasSuffix(row[1], "days excluded") {
+				t.Errorf("%v: banner %q, want %s excluded:\n%s", tt.ranges, row, tt.banner, out)
+			}
+		}
+		if strings.Contains(out, "no commits") {
+			t.Errorf("%v: excluded days shown as without commits:\n%s", tt.ranges, out)
+		}
+	}
+
+	if stdout, stderr, code := runGitStat(t, r.dir, "--exclude-range", "2024-03-12..2024-03-10", ".", "2024-03-09", "2024-03-13"); code == 0 || !strings.Contains(stdout+stderr, "ends before it starts") {
+		t.Errorf("a backwards --exclude-range exited with %d: %s%s", code, stdout, stderr)
+	}
+}
+
+func TestSplitPeriods(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end string
+		want       []string
+	}{
+		{periodDay, "2024-03-01", "2024-03-03", []string{"2024-03-01", "2024-03-02", "2024-03-03"}},
+		// 2024-03-06 is a Wednesday.
+		{periodWeek, "2024-03-06", "2024-03-20", []string{"2024-03-06 ~ 03-10", "2024-03-11 ~ 03-17", "2024-03-18 ~ 03-20"}},
+		{periodSprint, "2024-03-06", "2024-03-26", []string{"Week 1", "Week 2", "Week 3"}},
+		{periodSprint, "2024-03-06", "2024-03-22", []string{"Week 1", "Week 2", "Week 3"}},
+		{periodMonth, "2024-01-15", "2024-03-02", []string{"2024-01", "2024-02", "2024-03"}},
+	}
+	for _, tt := range tests {
+		start, _ := parseDate(tt.start)
+		end, _ := parseDate(tt.end)
+		var labels []string
+		for i, p := range splitPeriods(start, end, tt.name) {
+			labels = append(labels, p.label(tt.name, i))
+		}
+		if strings.Join(labels, ", ") != strings.Join(tt.want, ", ") {
+			t.Errorf("%s periods of %s ~ %s: %q, want %q", tt.name, tt.start, tt.end, labels, tt.want)
+		}
+	}
+
+	// Sprints start on the weekday of the start date, clipped at the end.
+	start, _ := parseDate("2024-03-06")
+	end, _ := parseDate("2024-03-22")
+	sprints := splitPeriods(start, end, periodSprint)
+	if got := formatDateRange(sprints[1].Start, sprints[1].End); got != "2024-03-13 ~ 03-19" {
+		t.Errorf("the second sprint is %s, want 2024-03-13 ~ 03-19", got)
+	}
+	if got := formatDateRange(sprints[2].Start, sprints[2].End); got != "2024-03-20 ~ 03-22" {
+		t.Errorf("the last sprint is %s, want 2024-03-20 ~ 03-22", got)
+	}
+}
+
+func TestRelativeWeeks(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-06T10:00:00Z", files: map[string]string{"a": lines(1), "b": lines(1)}})
+	r.commit(testCommit{when: "2024-03-14T10:00:00Z", files: map[string]string{"c": lines(1)}})
+	r.commit(testCommit{when: "2024-03-25T10:00:00Z", files: map[string]string{"a": ""}})
+
+	tests := []struct {
+		args []string
+		rows map[string][]string // files and change by week
+	}{
+		{[]string{"--relative-weeks"}, map[string][]string{
+			"Week 1": {"2", ""},
+			"Week 2": {"3", "+1"},
+			"Week 3": {"2", "-1"},
+		}},
+		{nil, map[string][]string{
+			"2024-03-06 ~ 03-10": {"2", ""},
+			"2024-03-25 ~ 03-26": {"2", "-1"},
+		}},
+	}
+	for _, tt := range tests {
+		args := append(append([]string{"--file-count", "--period", "week"}, tt.args...), ".", "2024-03-06", "2024-03-26")
+		out := mustRun(t, r.dir, args...)
+		for label, want := range tt.rows {
+			if row := tableRow(out, label); row == nil || strings.Join(row[1:], " ") != strings.Join(want, " ") {
+				t.Errorf("%v: %s %q, want %q:\n%s", tt.args, label, row, want, out)
+			}
+		}
+		if len(tt.args) == 0 && strings.Contains(out, "Week 1") {
+			t.Errorf("weeks are numbered without --relative-weeks:\n%s", out)
+		}
+	}
+
+	if stdout, stderr, code := runGitStat(t, r.dir, "--file-count", "--relative-weeks", ".", "2024-03-06", "2024-03-26"); code == 0 || !strings.Contains(stderr, "--relative-weeks needs --period week") {
+		t.Errorf("--relative-weeks without --period week exited with %d: %s%s", code, stdout, stderr)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// permissionError replaces err by a message saying what to check when it
+// comes from a file of the repository at path that cannot be read. Other
+// errors are returned as they are.
+func permissionError(path string, err error) error {
+	if !errors.Is(err, fs.ErrPermission) {
+		return err
+	}
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		debugf("%v", pathErr)
+	}
+	return fmt.Errorf("cannot read repository at %s: permission denied; check file permissions", path)
+}
+
+// repositoryRoot returns the directory of the repository's worktree, or that
+// of its git directory when it is bare.
+func repositoryRoot(repo *git.Repository) string {
+	if worktree, err := repo.Worktree(); err == nil {
+		return worktree.Filesystem.Root()
+	}
+	if storage, ok := repo.Storer.(*filesystem.Storage); ok {
+		return storage.Filesystem().Root()
+	}
+	return "."
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestPermissionError(t *testing.T) {
+	defer func(level logLevel) { currentLogLevel = level }(currentLogLevel)
+	currentLogLevel = levelError
+
+	denied := &fs.PathError{Op: "open", Path: "/repo/.git/HEAD", Err: fs.ErrPermission}
+	other := errors.New("object not found")
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"path error", denied, "cannot read repository at /repo: permission denied; check file permissions"},
+		{"wrapped", fmt.Errorf("reading HEAD: %w", denied), "cannot read repository at /repo: permission denied; check file permissions"},
+		{"bare permission error", fs.ErrPermission, "cannot read repository at /repo: permission denied; check file permissions"},
+		{"other error", other, "object not found"},
+		{"missing file", &fs.PathError{Op: "open", Path: "/repo/.git/HEAD", Err: fs.ErrNotExist}, "open /repo/.git/HEAD: file does not exist"},
+	}
+	for _, tt := range tests {
+		if got := permissionError("/repo", tt.err); got == nil || got.Error() != tt.want {
+			t.Errorf("%s: %v, want %q", tt.name, got, tt.want)
+		}
+	}
+	if err := permissionError("/repo", nil); err != nil {
+		t.Errorf("no error became %v", err)
+	}
+}
+
+func TestRepositoryRoot(t *testing.T) {
+	r := newTestRepo(t)
+	bareDir := t.TempDir()
+	bare, err := git.PlainInit(bareDir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		repo *git.Repository
+		want string
+	}{
+		{"worktree", r.repo, r.dir},
+		{"bare", bare, bareDir},
+	}
+	for _, tt := range tests {
+		if got := repositoryRoot(tt.repo); got != tt.want {
+			t.Errorf("%s: %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPermissionDenied(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for root")
+	}
+
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	objects := filepath.Join(r.dir, ".git", "objects")
+	if err := os.Chmod(objects, 0); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(objects, 0o755)
+
+	stdout, stderr, code := runGitStat(t, r.dir, ".", "2024-03-01", "2024-03-02")
+	if code == 0 || !strings.Contains(stderr, "permission denied; check file permissions") {
+		t.Errorf("exited with %d: %s%s", code, stdout, stderr)
+	}
+}
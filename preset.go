@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// builtinPresets are the path presets of --preset, with patterns matched
+// like those of --test-pattern.
+var builtinPresets = map[string][]string{
+	"api": {
+		"api/**",
+		"proto/**",
+		"*.proto",
+		"*.graphql",
+		"openapi.*",
+		"swagger.*",
+	},
+	"docs": {
+		"doc/**",
+		"docs/**",
+		"*.md",
+		"*.rst",
+		"*.adoc",
+	},
+	"tests": defaultTestPatterns,
+	"ci": {
+		".github/workflows/**",
+		".circleci/**",
+		".buildkite/**",
+		".gitlab-ci.yml",
+		".travis.yml",
+		"azure-pipelines.yml",
+		"Jenkinsfile",
+	},
+}
+
+// loadPresets reads a preset file with one `<name> = <pattern>, <pattern>,
+// ...` entry per line, in the format of the --teams file. Blank lines and
+// lines starting with `#` are ignored.
+func loadPresets(filename string) (map[string][]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	presets := make(map[string][]string)
+
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, patterns, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%s:%d: expected `<name> = <pattern>, <pattern>, ...`", filename, lineNo)
+		}
+
+		for _, pattern := range strings.Split(patterns, ",") {
+			if pattern = strings.TrimSpace(pattern); pattern != "" {
+				presets[name] = append(presets[name], pattern)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return presets, nil
+}
+
+// presetPatterns returns the patterns of the named presets. Those of the
+// presets file, if given, take precedence over the built-in ones.
+func presetPatterns(names []string, presetsFile string) ([]string, error) {
+	presets := builtinPresets
+	if presetsFile != "" {
+		userPresets, err := loadPresets(presetsFile)
+		if err != nil {
+			return nil, err
+		}
+		presets = make(map[string][]string, len(builtinPresets)+len(userPresets))
+		for name, patterns := range builtinPresets {
+			presets[name] = patterns
+		}
+		for name, patterns := range userPresets {
+			presets[name] = patterns
+		}
+	}
+
+	var patterns []string
+	for _, name := range names {
+		preset, ok := presets[name]
+		if !ok {
+			known := make([]string, 0, len(presets))
+			for name := range presets {
+				known = append(known, name)
+			}
+			sort.Strings(known)
+			return nil, fmt.Errorf("unknown preset %q, expected one of %s", name, strings.Join(known, ", "))
+		}
+		patterns = append(patterns, preset...)
+	}
+	return patterns, nil
+}
+
+// filterPatterns keeps the stats of the files matching one of patterns.
+// Renamed files are matched by their new path.
+func filterPatterns(stats object.FileStats, patterns []string) object.FileStats {
+	var filtered object.FileStats
+	for _, stat := range stats {
+		if _, to := splitRename(stat.Name); matchesPattern(to, patterns) {
+			filtered = append(filtered, stat)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestLoadPresets(t *testing.T) {
+	tests := []struct {
+		content string
+		want    map[string][]string
+		err     string
+	}{
+		{
+			"# presets\nweb = web/**, *.css\n\nschema=*.sql,\n",
+			map[string][]string{"web": {"web/**", "*.css"}, "schema": {"*.sql"}},
+			"",
+		},
+		{"web = web/**\nweb = *.css\n", map[string][]string{"web": {"web/**", "*.css"}}, ""},
+		{"web/**\n", nil, "presets:1: expected"},
+		{"web = *.css\n = *.sql\n", nil, "presets:2: expected"},
+	}
+	for _, tt := range tests {
+		filename := filepath.Join(t.TempDir(), "presets")
+		if err := os.WriteFile(filename, []byte(tt.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		presets, err := loadPresets(filename)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("loadPresets(%q): %v, want an error containing %q", tt.content, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || fmt.Sprint(presets) != fmt.Sprint(tt.want) {
+			t.Errorf("loadPresets(%q) = %v, %v, want %v", tt.content, presets, err, tt.want)
+		}
+	}
+}
+
+func TestPresetPatterns(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "presets")
+	if err := os.WriteFile(filename, []byte("web = web/**\ndocs = handbook/**\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		names   []string
+		file    string
+		want    []string
+		wantErr string
+	}{
+		{[]string{"ci"}, "", builtinPresets["ci"], ""},
+		{[]string{"api", "docs"}, "", append(slices.Clone(builtinPresets["api"]), builtinPresets["docs"]...), ""},
+		{[]string{"web", "tests"}, filename, append([]string{"web/**"}, defaultTestPatterns...), ""},
+		{[]string{"docs"}, filename, []string{"handbook/**"}, ""},
+		{[]string{"web"}, "", nil, `unknown preset "web", expected one of api, ci, docs, tests`},
+		{[]string{"mobile"}, filename, nil, `unknown preset "mobile", expected one of api, ci, docs, tests, web`},
+	}
+	for _, tt := range tests {
+		patterns, err := presetPatterns(tt.names, tt.file)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("presetPatterns(%v, %q): %v, want %q", tt.names, tt.file, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || !slices.Equal(patterns, tt.want) {
+			t.Errorf("presetPatterns(%v, %q) = %v, %v, want %v", tt.names, tt.file, patterns, err, tt.want)
+		}
+	}
+}
+
+func TestFilterPatterns(t *testing.T) {
+	stats := object.FileStats{
+		{Name: "README.md"},
+		{Name: "docs/guide.txt"},
+		{Name: "src/main.go"},
+		{Name: "notes.txt => docs/notes.txt"},
+		{Name: "docs/old.txt => archive/old.txt"},
+	}
+	tests := []struct {
+		patterns []string
+		want     []string
+	}{
+		{builtinPresets["docs"], []string{"README.md", "docs/guide.txt", "notes.txt => docs/notes.txt"}},
+		{[]string{"*.go"}, []string{"src/main.go"}},
+		{[]string{"*.py"}, nil},
+	}
+	for _, tt := range tests {
+		var names []string
+		for _, stat := range filterPatterns(stats, tt.patterns) {
+			names = append(names, stat.Name)
+		}
+		if !slices.Equal(names, tt.want) {
+			t.Errorf("filterPatterns(%v) = %q, want %q", tt.patterns, names, tt.want)
+		}
+	}
+}
+
+func TestPreset(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-02-28T10:00:00Z", files: map[string]string{"main.go": lines(1)}})
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"main.go": lines(3), "README.md": lines(4)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{".github/workflows/ci.yml": lines(5)}})
+	r.commit(testCommit{when: "2024-03-03T10:00:00Z", files: map[string]string{"web/app.js": lines(6)}})
+
+	presets := filepath.Join(t.TempDir(), "presets")
+	if err := os.WriteFile(presets, []byte("web = web/**\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		flags     []string
+		additions string
+		days      []string // the days with a row
+	}{
+		{nil, "17", []string{"2024-03-01", "2024-03-02", "2024-03-03"}},
+		{[]string{"--preset", "docs"}, "4", []string{"2024-03-01"}},
+		{[]string{"--preset", "docs", "--preset", "ci"}, "9", []string{"2024-03-01", "2024-03-02"}},
+		{[]string{"--preset", "web", "--presets", presets}, "6", []string{"2024-03-03"}},
+	}
+	for _, tt := range tests {
+		out := mustRun(t, r.dir, append(tt.flags, ".", "2024-03-01", "2024-03-03")...)
+		if row := tableRow(out, "Total"); row == nil || row[2] != tt.additions {
+			t.Errorf("%v: total row %v, want %s additions", tt.flags, row, tt.additions)
+		}
+		for _, day := range []string{"2024-03-01", "2024-03-02", "2024-03-03"} {
+			if got := tableRow(out, day) != nil; got != slices.Contains(tt.days, day) {
+				t.Errorf("%v: %s has a row: %t", tt.flags, day, got)
+			}
+		}
+	}
+
+	failures := []struct {
+		flags []string
+		want  string
+	}{
+		{[]string{"--preset", "web"}, `Invalid --preset: unknown preset "web"`},
+		{[]string{"--presets", presets}, "--presets needs --preset"},
+	}
+	for _, tt := range failures {
+		stdout, stderr, code := runGitStat(t, r.dir, append(tt.flags, ".", "2024-03-01", "2024-03-03")...)
+		if code == 0 || !strings.Contains(stderr, tt.want) {
+			t.Errorf("%v exited with %d: %s%s\nwant the error %q", tt.flags, code, stdout, stderr, tt.want)
+		}
+	}
+}
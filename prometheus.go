@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// prometheusMetrics lists the per-day gauges written by --format prometheus.
+var prometheusMetrics = []struct {
+	name  string
+	help  string
+	value func(stats *DailyStats) int
+}{
+	{"git_stat_additions", "Lines added on the day.", func(stats *DailyStats) int { return stats.Additions }},
+	{"git_stat_deletions", "Lines deleted on the day.", func(stats *DailyStats) int { return stats.Deletions }},
+	{"git_stat_files_changed", "Distinct files changed on the day.", func(stats *DailyStats) int { return len(stats.FilesChanged) }},
+	{"git_stat_commits", "Commits made on the day.", func(stats *DailyStats) int { return stats.Commits }},
+}
+
+var prometheusEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// writePrometheus writes the daily stats in the Prometheus text exposition
+// format, for example for the node_exporter textfile collector.
+func writePrometheus(w io.Writer, report *Report) error {
+	dates := sortedDates(report.DailyStats)
+
+	for _, metric := range prometheusMetrics {
+		fmt.Fprintf(w, "# HELP %s %s\n", metric.name, metric.help)
+		fmt.Fprintf(w, "# TYPE %s gauge\n", metric.name)
+		for _, date := range dates {
+			_, err := fmt.Fprintf(w, "%s{date=\"%s\"} %d\n", metric.name, prometheusEscaper.Replace(date), metric.value(report.DailyStats[date]))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var (
+	metricName   = `[a-zA-Z_:][a-zA-Z0-9_:]*`
+	helpLine     = regexp.MustCompile(`^# HELP (` + metricName + `) \S.*$`)
+	typeLine     = regexp.MustCompile(`^# TYPE (` + metricName + `) (counter|gauge|histogram|summary|untyped)$`)
+	sampleLine   = regexp.MustCompile(`^(` + metricName + `)\{((?:[a-zA-Z_][a-zA-Z0-9_]*="(?:[^"\\\n]|\\[\\"n])*",?)*)\} (\S+)$`)
+	sampleLabels = regexp.MustCompile(`([a-zA-Z_][a-zA-Z0-9_]*)="((?:[^"\\]|\\.)*)"`)
+)
+
+// parsePrometheus parses the text exposition format as far as
+// writePrometheus uses it, checking that every metric is described by HELP
+// and TYPE lines before its samples. It returns the samples by metric name
+// and labels, the label values unescaped.
+func parsePrometheus(text string) (map[string]float64, error) {
+	samples := make(map[string]float64)
+	typed := make(map[string]bool)
+	helped := make(map[string]bool)
+	for i, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		if m := helpLine.FindStringSubmatch(line); m != nil {
+			helped[m[1]] = true
+			continue
+		}
+		if m := typeLine.FindStringSubmatch(line); m != nil {
+			if typed[m[1]] {
+				return nil, fmt.Errorf("line %d: second TYPE of %s", i+1, m[1])
+			}
+			typed[m[1]] = true
+			continue
+		}
+		m := sampleLine.FindStringSubmatch(line)
+		if m == nil {
+			return nil, fmt.Errorf("line %d: cannot parse %q", i+1, line)
+		}
+		if !typed[m[1]] || !helped[m[1]] {
+			return nil, fmt.Errorf("line %d: %s lacks HELP or TYPE", i+1, m[1])
+		}
+		value, err := strconv.ParseFloat(m[3], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+
+		var labels []string
+		for _, l := range sampleLabels.FindAllStringSubmatch(m[2], -1) {
+			unquoted, err := strconv.Unquote(`"` + l[2] + `"`)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", i+1, err)
+			}
+			labels = append(labels, l[1]+"="+unquoted)
+		}
+		samples[m[1]+"{"+strings.Join(labels, ",")+"}"] = value
+	}
+	return samples, nil
+}
+
+func TestWritePrometheus(t *testing.T) {
+	report := &Report{DailyStats: map[string]*DailyStats{
+		"2024-03-01": {FilesChanged: map[string]struct{}{"a": {}, "b": {}}, Additions: 42, Deletions: 7, Commits: 3},
+		"2024-03-02": {FilesChanged: map[string]struct{}{"a": {}}, Additions: 0, Deletions: 1, Commits: 1},
+	}}
+
+	var b bytes.Buffer
+	if err := writePrometheus(&b, report); err != nil {
+		t.Fatal(err)
+	}
+	samples, err := parsePrometheus(b.String())
+	if err != nil {
+		t.Fatalf("%v:\n%s", err, b.String())
+	}
+
+	want := map[string]float64{
+		`git_stat_additions{date=2024-03-01}`:     42,
+		`git_stat_additions{date=2024-03-02}`:     0,
+		`git_stat_deletions{date=2024-03-01}`:     7,
+		`git_stat_deletions{date=2024-03-02}`:     1,
+		`git_stat_files_changed{date=2024-03-01}`: 2,
+		`git_stat_files_changed{date=2024-03-02}`: 1,
+		`git_stat_commits{date=2024-03-01}`:       3,
+		`git_stat_commits{date=2024-03-02}`:       1,
+	}
+	if len(samples) != len(want) {
+		t.Errorf("%d samples, want %d:\n%s", len(samples), len(want), b.String())
+	}
+	for sample, value := range want {
+		if got, ok := samples[sample]; !ok || got != value {
+			t.Errorf("%s = %v (found %t), want %v", sample, got, ok, value)
+		}
+	}
+}
+
+func TestPrometheusEscaper(t *testing.T) {
+	tests := []struct{ value, want string }{
+		{"2024-03-01", "2024-03-01"},
+		{`say "hi"`, `say \"hi\"`},
+		{`C:\repo`, `C:\\repo`},
+		{"two\nlines", `two\nlines`},
+	}
+	for _, tt := range tests {
+		escaped := prometheusEscaper.Replace(tt.value)
+		if escaped != tt.want {
+			t.Errorf("escaping %q = %q, want %q", tt.value, escaped, tt.want)
+		}
+		samples, err := parsePrometheus("# HELP m A metric.\n# TYPE m gauge\nm{l=\"" + escaped + "\"} 1\n")
+		if err != nil || samples["m{l="+tt.value+"}"] != 1 {
+			t.Errorf("the escaped %q does not parse back: %v, %v", tt.value, samples, err)
+		}
+	}
+}
+
+func TestFormatPrometheus(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(3)}})
+
+	out := mustRun(t, r.dir, "--format", "prometheus", ".", "2024-03-01", "2024-03-02")
+	samples, err := parsePrometheus(out)
+	if err != nil {
+		t.Fatalf("%v:\n%s", err, out)
+	}
+	if samples["git_stat_additions{date=2024-03-01}"] != 3 || len(samples) != len(prometheusMetrics) {
+		t.Errorf("samples %v, want one per metric with 3 additions", samples)
+	}
+}
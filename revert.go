@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// isRevert reports whether c undoes an earlier commit, going by the
+// "Revert ..." subject `git revert` gives it.
+func isRevert(c *object.Commit) bool {
+	return strings.HasPrefix(c.Message, "Revert ") || strings.HasPrefix(c.Message, "Revert\"")
+}
+
+// printReverts prints how many of the commits in total were reverts and
+// how much of the changes they make up.
+func printReverts(w io.Writer, total *DailyStats) {
+	if total.Reverts == 0 {
+		fmt.Fprintln(w, "Reverts: none")
+		return
+	}
+
+	fmt.Fprintf(w, "Reverts: %s %s, %s changes", formatCount(total.Reverts), commitUnit(total.Reverts), formatCount(total.RevertChanges))
+	if total.Changes > 0 {
+		fmt.Fprintf(w, " (%s of the total)", formatPercent(100*float64(total.RevertChanges)/float64(total.Changes), 1))
+	}
+	fmt.Fprintln(w)
+}
+
+func commitUnit(n int) string {
+	if n == 1 {
+		return "commit"
+	}
+	return "commits"
+}
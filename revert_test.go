@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestIsRevert(t *testing.T) {
+	tests := []struct {
+		message string
+		want    bool
+	}{
+		{"Revert \"Add the parser\"\n\nThis reverts commit 1234567.\n", true},
+		{"Revert\"Add the parser\"\n", true},
+		{"Revert the parser changes\n", true},
+		{"Reverted the parser\n", false},
+		{"revert \"Add the parser\"\n", false},
+		{"Fix the parser\n\nRevert \"Add the parser\" broke it.\n", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isRevert(&object.Commit{Message: tt.message}); got != tt.want {
+			t.Errorf("isRevert(%q) = %v, want %v", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestReverts(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-02-28T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", message: "Grow a", files: map[string]string{"a": lines(5)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", message: "Revert \"Grow a\"\n\nThis reverts the last commit.\n", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-03T10:00:00Z", files: map[string]string{"b": lines(6)}})
+
+	tests := []struct {
+		flag    string
+		summary string // the --reverts line, "" for none
+		revert  bool   // whether the day of the revert has a row
+		total   string
+	}{
+		{"", "", true, "14"},
+		{"--reverts", "Reverts: 1 commit, 4 changes (28.6% of the total)", true, "14"},
+		{"--exclude-reverts", "", false, "10"},
+		{"--exclude-reverts --reverts", "Reverts: none", false, "10"},
+	}
+	for _, tt := range tests {
+		args := append(strings.Fields(tt.flag), ".", "2024-03-01", "2024-03-03")
+		stdout, stderr, code := runGitStat(t, r.dir, args...)
+		if code != 0 {
+			t.Fatalf("%s exited with %d: %s%s", tt.flag, code, stdout, stderr)
+		}
+		if got := strings.Contains(stdout, "Reverts:"); got != (tt.summary != "") || !strings.Contains(stdout, tt.summary) {
+			t.Errorf("%q: want the summary %q in:\n%s", tt.flag, tt.summary, stdout)
+		}
+		if got := tableRow(stdout, "2024-03-02") != nil; got != tt.revert {
+			t.Errorf("%q: the day of the revert has a row: %v, want %v", tt.flag, got, tt.revert)
+		}
+		if row := tableRow(stdout, "Total"); row == nil || row[4] != tt.total {
+			t.Errorf("%q: total row %v, want %s changes", tt.flag, row, tt.total)
+		}
+		if got := strings.Contains(stderr, "Excluded 1 revert commits"); got != strings.Contains(tt.flag, "--exclude-reverts") {
+			t.Errorf("%q: stderr:\n%s", tt.flag, stderr)
+		}
+	}
+}
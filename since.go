@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// sinceCommit resolves the commit given with --since-commit. It returns the
+// day of that commit, which becomes the start of the range, and a filter
+// skipping the commit and its ancestors from that day on, so only what came
+// after it is counted.
+func sinceCommit(repo *git.Repository, rev string) (time.Time, func(c *object.Commit) bool, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return time.Time{}, nil, fmt.Errorf("commit %q not found", rev)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return time.Time{}, nil, fmt.Errorf("commit %q not found", rev)
+	}
+
+	startDate, _ := parseDate(commit.Committer.When.Format("2006-01-02"))
+
+	// Ancestors committed before the start of the range are never walked.
+	seen := make(map[plumbing.Hash]bool)
+	err = logCommits(repo, commit.Hash, startDate.Add(-24*time.Hour), time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC), func(c *object.Commit) error {
+		seen[c.Hash] = true
+		return nil
+	})
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	debugf("skipping %d commits up to %s", len(seen), commit.Hash.String()[:7])
+
+	return startDate, func(c *object.Commit) bool { return !seen[c.Hash] }, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestSinceCommit(t *testing.T) {
+	r := newTestRepo(t)
+	first := r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	mid := r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(3)}})
+	after := r.commit(testCommit{when: "2024-03-02T12:00:00Z", files: map[string]string{"b": lines(4)}})
+	last := r.commit(testCommit{when: "2024-03-03T10:00:00Z", files: map[string]string{"a": lines(11)}})
+
+	startDate, filter, err := sinceCommit(r.repo, mid.String()[:7])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := startDate.Format("2006-01-02"); got != "2024-03-02" {
+		t.Errorf("the range starts on %s, want 2024-03-02", got)
+	}
+	for _, tt := range []struct {
+		hash plumbing.Hash
+		want bool
+	}{
+		{first, false},
+		{mid, false},
+		{after, true},
+		{last, true},
+	} {
+		c, err := r.repo.CommitObject(tt.hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := filter(c); got != tt.want {
+			t.Errorf("filter(%s) = %t, want %t", tt.hash.String()[:7], got, tt.want)
+		}
+	}
+
+	tests := []struct {
+		since   string
+		changes string
+	}{
+		{first.String(), "14"},
+		{mid.String(), "12"},
+		{mid.String()[:7], "12"},
+		{"HEAD~1", "8"},
+		{last.String(), "0"},
+	}
+	for _, tt := range tests {
+		if row := tableRow(mustRun(t, r.dir, "--since-commit", tt.since, ".", "2024-03-03"), "Total"); row[4] != tt.changes {
+			t.Errorf("--since-commit %s: %s changes, want %s", tt.since, row[4], tt.changes)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"--since-commit", "0123456789abcdef", ".", "2024-03-03"},
+		{"--since-commit", mid.String(), ".", "2024-03-01", "2024-03-03"},
+	} {
+		if stdout, stderr, code := runGitStat(t, r.dir, args...); code == 0 || !strings.Contains(stdout+stderr, "--since-commit") {
+			t.Errorf("%v exited with %d: %s%s", args, code, stdout, stderr)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"database/sql"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteSchema is the table written by --format sqlite. Rows are keyed by
+// day so running the report again over an overlapping range updates the
+// existing rows.
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS daily_stats (
+	date          TEXT PRIMARY KEY,
+	files_changed INTEGER NOT NULL,
+	additions     INTEGER NOT NULL,
+	deletions     INTEGER NOT NULL,
+	total_changes INTEGER NOT NULL,
+	commits       INTEGER NOT NULL,
+	created_at    TEXT NOT NULL,
+	updated_at    TEXT NOT NULL
+)`
+
+const sqliteUpsert = `INSERT INTO daily_stats
+	(date, files_changed, additions, deletions, total_changes, commits, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (date) DO UPDATE SET
+		files_changed = excluded.files_changed,
+		additions     = excluded.additions,
+		deletions     = excluded.deletions,
+		total_changes = excluded.total_changes,
+		commits       = excluded.commits,
+		updated_at    = excluded.updated_at`
+
+// fileRenderers maps the formats that cannot be streamed to the function
+// writing the report to the --output file.
+var fileRenderers = map[string]func(filename string, report *Report) error{
+	"sqlite": writeSQLite,
+}
+
+// writeSQLite upserts one row per active day into the database at filename,
+// creating it if needed.
+func writeSQLite(filename string, report *Report) error {
+	db, err := sql.Open("sqlite", filename)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(sqliteUpsert)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, date := range sortedDates(report.DailyStats) {
+		stats := report.DailyStats[date]
+		_, err := stmt.Exec(date,
+			len(stats.FilesChanged),
+			stats.Additions,
+			stats.Deletions,
+			stats.Changes,
+			stats.Commits,
+			now, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteSQLite(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "stats.db")
+	day := func(files, additions, deletions, commits int) *DailyStats {
+		stats := &DailyStats{
+			FilesChanged: make(map[string]struct{}),
+			Additions:    additions,
+			Deletions:    deletions,
+			Changes:      additions + deletions,
+			Commits:      commits,
+		}
+		for i := 0; i < files; i++ {
+			stats.FilesChanged[fmt.Sprint(i)] = struct{}{}
+		}
+		return stats
+	}
+
+	runs := []struct {
+		days map[string]*DailyStats
+		want []string // date, files, additions, deletions, changes and commits per row
+	}{
+		{
+			map[string]*DailyStats{"2024-03-01": day(2, 5, 1, 1), "2024-03-02": day(1, 0, 3, 2)},
+			[]string{"2024-03-01 2 5 1 6 1", "2024-03-02 1 0 3 3 2"},
+		},
+		{
+			// Running again over an overlapping range updates the day both
+			// runs cover and adds the new one.
+			map[string]*DailyStats{"2024-03-02": day(3, 4, 3, 3), "2024-03-03": day(1, 1, 0, 1)},
+			[]string{"2024-03-01 2 5 1 6 1", "2024-03-02 3 4 3 7 3", "2024-03-03 1 1 0 1 1"},
+		},
+	}
+
+	const longAgo = "2000-01-01T00:00:00Z"
+	for i, run := range runs {
+		if err := writeSQLite(filename, &Report{DailyStats: run.days}); err != nil {
+			t.Fatalf("run %d: %v", i+1, err)
+		}
+
+		db, err := sql.Open("sqlite", filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, err := db.Query(`SELECT date, files_changed, additions, deletions, total_changes, commits, created_at, updated_at
+			FROM daily_stats ORDER BY date`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for rows.Next() {
+			var date, created, updated string
+			var files, additions, deletions, changes, commits int
+			if err := rows.Scan(&date, &files, &additions, &deletions, &changes, &commits, &created, &updated); err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, fmt.Sprintf("%s %d %d %d %d %d", date, files, additions, deletions, changes, commits))
+
+			// The rows of the first run were dated long ago below, which
+			// an update must keep as their creation only.
+			if i > 0 && date == "2024-03-02" && (created != longAgo || updated == longAgo) {
+				t.Errorf("run %d: updated row created %s and updated %s, want created %s and updated now", i+1, created, updated, longAgo)
+			}
+		}
+		if err := rows.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(got, ", ") != strings.Join(run.want, ", ") {
+			t.Errorf("run %d: rows %q, want %q", i+1, got, run.want)
+		}
+
+		if _, err := db.Exec(`UPDATE daily_stats SET created_at = ?, updated_at = ?`, longAgo, longAgo); err != nil {
+			t.Fatal(err)
+		}
+		db.Close()
+	}
+}
+
+func TestFormatSQLite(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(3)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(1)}})
+
+	filename := filepath.Join(t.TempDir(), "stats.db")
+	if out := mustRun(t, r.dir, "--format", "sqlite", "--output", filename, ".", "2024-03-01", "2024-03-03"); out != "" {
+		t.Errorf("--format sqlite printed %q", out)
+	}
+
+	db, err := sql.Open("sqlite", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var days, additions, deletions int
+	if err := db.QueryRow(`SELECT count(*), sum(additions), sum(deletions) FROM daily_stats`).Scan(&days, &additions, &deletions); err != nil {
+		t.Fatal(err)
+	}
+	if days != 2 || additions != 3 || deletions != 2 {
+		t.Errorf("%d days with +%d -%d, want 2 days with +3 -2", days, additions, deletions)
+	}
+
+	if stdout, stderr, code := runGitStat(t, r.dir, "--format", "sqlite", ".", "2024-03-01", "2024-03-03"); code == 0 {
+		t.Errorf("--format sqlite without --output exited with %d: %s%s", code, stdout, stderr)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// streak is a run of consecutive days with commits.
+type streak struct {
+	Start time.Time
+	Days  int
+}
+
+// findStreaks returns the longest run of consecutive days with commits in
+// the report's range, the earliest one on a tie, and the run ending on the
+// end date, which is empty when nothing was committed that day.
+func findStreaks(report *Report) (longest, current streak) {
+	for d := report.StartDate; !d.After(report.EndDate); d = d.AddDate(0, 0, 1) {
+		if _, ok := report.DailyStats[d.Format("2006-01-02")]; !ok {
+			current = streak{}
+			continue
+		}
+
+		if current.Days == 0 {
+			current.Start = d
+		}
+		current.Days++
+		if current.Days > longest.Days {
+			longest = current
+		}
+	}
+	return longest, current
+}
+
+// printStreaks prints the longest and the current commit streak.
+func printStreaks(w io.Writer, report *Report) {
+	longest, current := findStreaks(report)
+	fmt.Fprintf(w, "Longest streak: %s", formatStreak(longest))
+	fmt.Fprintf(w, ", current streak: %s\n", formatStreak(current))
+}
+
+func formatStreak(s streak) string {
+	if s.Days == 0 {
+		return "0 days"
+	}
+	return fmt.Sprintf("%d %s (%s)", s.Days, dayUnit(s.Days), formatDateRange(s.Start, s.Start.AddDate(0, 0, s.Days-1)))
+}
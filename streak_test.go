@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFindStreaks(t *testing.T) {
+	tests := []struct {
+		days    []string
+		longest string
+		current string
+	}{
+		{nil, "0 days", "0 days"},
+		{[]string{"03-02", "03-03", "03-04", "03-05", "03-06"}, "5 days (2024-03-02 ~ 03-06)", "0 days"},
+		{[]string{"03-01", "03-02", "03-05", "03-06", "03-07", "03-10"}, "3 days (2024-03-05 ~ 03-07)", "1 day (2024-03-10 ~ 03-10)"},
+		{[]string{"03-01", "03-02", "03-08", "03-09", "03-10"}, "3 days (2024-03-08 ~ 03-10)", "3 days (2024-03-08 ~ 03-10)"},
+		{[]string{"03-01", "03-02", "03-09", "03-10"}, "2 days (2024-03-01 ~ 03-02)", "2 days (2024-03-09 ~ 03-10)"},
+	}
+	for _, tt := range tests {
+		report := &Report{DailyStats: make(map[string]*DailyStats)}
+		report.StartDate, _ = parseDate("2024-03-01")
+		report.EndDate, _ = parseDate("2024-03-10")
+		for _, day := range tt.days {
+			report.DailyStats["2024-"+day] = &DailyStats{}
+		}
+
+		longest, current := findStreaks(report)
+		if got := formatStreak(longest); got != tt.longest {
+			t.Errorf("%v: longest streak %s, want %s", tt.days, got, tt.longest)
+		}
+		if got := formatStreak(current); got != tt.current {
+			t.Errorf("%v: current streak %s, want %s", tt.days, got, tt.current)
+		}
+	}
+}
+
+func TestStreaks(t *testing.T) {
+	r := newTestRepo(t)
+	for _, day := range []string{"2024-03-01", "2024-03-04", "2024-03-05", "2024-03-06", "2024-03-07", "2024-03-08", "2024-03-10"} {
+		r.commit(testCommit{when: day + "T10:00:00Z", files: map[string]string{"a": day + "\n"}})
+	}
+
+	out := mustRun(t, r.dir, "--streaks", ".", "2024-03-01", "2024-03-10")
+	want := "Longest streak: 5 days (2024-03-04 ~ 03-08), current streak: 1 day (2024-03-10 ~ 03-10)\n"
+	if !strings.Contains(out, want) {
+		t.Errorf("output lacks %q:\n%s", want, out)
+	}
+
+	var b bytes.Buffer
+	printStreaks(&b, &Report{DailyStats: map[string]*DailyStats{}})
+	if got := b.String(); got != "Longest streak: 0 days, current streak: 0 days\n" {
+		t.Errorf("printStreaks without commits = %q", got)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// tableStyle holds the characters tables are drawn with.
+type tableStyle struct {
+	vertical   string // between two columns
+	horizontal string // rules below every row; empty leaves them out
+	cross      string // where a rule meets a column separator
+}
+
+// tableStyles maps the names accepted by --style to their characters. All
+// characters take up a single column.
+var tableStyles = map[string]tableStyle{
+	"ascii":   {"|", "-", "-"},
+	"unicode": {"│", "─", "┼"},
+	"minimal": {" ", "", ""},
+}
+
+var currentStyle = tableStyles["ascii"]
+
+func validateStyle(name string) error {
+	if _, ok := tableStyles[name]; ok {
+		return nil
+	}
+	names := make([]string, 0, len(tableStyles))
+	for name := range tableStyles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("unknown style %q, expected one of %s", name, strings.Join(names, ", "))
+}
+
+// rule returns a horizontal rule across columns of the given widths, or ""
+// when the style has no rules.
+func (s tableStyle) rule(widths ...int) string {
+	if s.horizontal == "" {
+		return ""
+	}
+	parts := make([]string, len(widths))
+	for i, width := range widths {
+		parts[i] = strings.Repeat(s.horizontal, width)
+	}
+	return strings.Join(parts, s.cross)
+}
+
+// printRule prints a rule across columns of the given widths, unless the
+// current style has no rules.
+func printRule(w io.Writer, widths ...int) {
+	if rule := currentStyle.rule(widths...); rule != "" {
+		fmt.Fprintf(w, "%s\n", rule)
+	}
+}
+
+// printCells prints a table line of already padded cells.
+func printCells(w io.Writer, cells ...string) {
+	fmt.Fprintf(w, "%s\n", strings.Join(cells, currentStyle.vertical))
+}
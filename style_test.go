@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func TestStyles(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(3)}})
+	r.commit(testCommit{when: "2024-03-04T10:00:00Z", files: map[string]string{"a": lines(2), "b": lines(1)}})
+
+	for _, style := range []string{"ascii", "unicode", "minimal"} {
+		got := stripANSI(mustRun(t, r.dir, "--style", style, ".", "2024-03-01", "2024-03-05"))
+
+		golden := filepath.Join("testdata", "style_"+style+".golden")
+		if *update {
+			if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != string(want) {
+			t.Errorf("--style %s:\n%s\nwant:\n%s", style, got, want)
+		}
+	}
+
+	if stdout, stderr, code := runGitStat(t, r.dir, "--style", "fancy", ".", "2024-03-01", "2024-03-05"); code == 0 || !strings.Contains(stdout+stderr, "expected one of ascii, minimal, unicode") {
+		t.Errorf("--style fancy exited with %d: %s%s", code, stdout, stderr)
+	}
+}
+
+func TestRule(t *testing.T) {
+	tests := []struct {
+		style string
+		want  string
+	}{
+		{"ascii", "---------"},
+		{"unicode", "──┼───┼──"},
+		{"minimal", ""},
+	}
+	for _, tt := range tests {
+		if got := tableStyles[tt.style].rule(2, 3, 2); got != tt.want {
+			t.Errorf("rule of %s = %q, want %q", tt.style, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// totalStats sums the daily stats of the report. With --dedupe-across-days
+// (the default) a file changed on several days counts once; otherwise every
+// day's distinct files are added up, so a file changed on three days counts
+// three times.
+func totalStats(report *Report) *DailyStats {
+	total := &DailyStats{
+		FilesChanged: make(map[string]struct{}),
+		Authors:      make(map[string]struct{}),
+	}
+
+	for date, stats := range report.DailyStats {
+		for file := range stats.FilesChanged {
+			if report.Options.DedupeAcrossDays {
+				total.FilesChanged[file] = struct{}{}
+			} else {
+				total.FilesChanged[date+"\x00"+file] = struct{}{}
+			}
+		}
+
+		for author := range stats.Authors {
+			total.Authors[author] = struct{}{}
+		}
+
+		total.Additions += stats.Additions
+		total.Deletions += stats.Deletions
+		total.Changes += stats.Changes
+		total.Commits += stats.Commits
+		total.ReviewLag += stats.ReviewLag
+		total.ClockSkew += stats.ClockSkew
+		total.TestAdditions += stats.TestAdditions
+		total.TestDeletions += stats.TestDeletions
+		total.Bytes += stats.Bytes
+		total.NetFiles += stats.NetFiles
+		total.AfterHours += stats.AfterHours
+		total.WeekendCommits += stats.WeekendCommits
+		total.AgeWeighted += stats.AgeWeighted
+		total.Reverts += stats.Reverts
+		total.Times = append(total.Times, stats.Times...)
+		total.RevertChanges += stats.RevertChanges
+		for hour, n := range stats.Hours {
+			total.Hours[hour] += n
+		}
+	}
+
+	return total
+}
+
+// mostChangedDays returns the days with the most additions and the most
+// deletions, the earliest on a tie. Both are empty when there are no days.
+func mostChangedDays(report *Report) (additions, deletions string) {
+	for _, date := range sortedDates(report.DailyStats) {
+		stats := report.DailyStats[date]
+		if additions == "" || stats.Additions > report.DailyStats[additions].Additions {
+			additions = date
+		}
+		if deletions == "" || stats.Deletions > report.DailyStats[deletions].Deletions {
+			deletions = date
+		}
+	}
+	return additions, deletions
+}
+
+// printHighlights prints the days with the most additions and deletions.
+func printHighlights(w io.Writer, report *Report) {
+	additions, deletions := mostChangedDays(report)
+	if additions == "" {
+		return
+	}
+	fmt.Fprintf(w, "Most additions: %s (+%s)\n", additions, formatCount(report.DailyStats[additions].Additions))
+	fmt.Fprintf(w, "Most deletions: %s (-%s)\n", deletions, formatCount(report.DailyStats[deletions].Deletions))
+}
+
+// averageChanges returns the total changes per day with commits and per day
+// of the range. Both are 0 when there are no such days.
+func averageChanges(report *Report, total *DailyStats) (perActiveDay, perDay float64) {
+	if active := len(report.DailyStats); active > 0 {
+		perActiveDay = float64(total.Changes) / float64(active)
+	}
+	if days := int(report.EndDate.Sub(report.StartDate).Hours()/24) + 1; days > 0 {
+		perDay = float64(total.Changes) / float64(days)
+	}
+	return perActiveDay, perDay
+}
+
+// printAverages prints the average changes per active and per calendar day,
+// which differ the more days go without commits.
+func printAverages(w io.Writer, report *Report, total *DailyStats) {
+	perActiveDay, perDay := averageChanges(report, total)
+	fmt.Fprintf(w, "Average changes: %s per active day, %s per calendar day\n", formatFloat(perActiveDay, 1), formatFloat(perDay, 1))
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTotalStats(t *testing.T) {
+	days := map[string]*DailyStats{
+		"2024-03-01": {
+			FilesChanged: map[string]struct{}{"a": {}, "b": {}},
+			Authors:      map[string]struct{}{"alice": {}},
+			Additions:    3, Deletions: 1, Changes: 4, Commits: 2,
+		},
+		"2024-03-02": {
+			FilesChanged: map[string]struct{}{"a": {}},
+			Authors:      map[string]struct{}{"alice": {}, "bob": {}},
+			Additions:    2, Changes: 2, Commits: 1,
+		},
+	}
+
+	tests := []struct {
+		dedupe bool
+		files  int
+	}{
+		{true, 2},
+		{false, 3},
+	}
+	for _, tt := range tests {
+		report := &Report{DailyStats: days, Options: &Options{DedupeAcrossDays: tt.dedupe}}
+		total := totalStats(report)
+		if len(total.FilesChanged) != tt.files {
+			t.Errorf("dedupe %t: %d files, want %d", tt.dedupe, len(total.FilesChanged), tt.files)
+		}
+		if total.Additions != 5 || total.Deletions != 1 || total.Changes != 6 || total.Commits != 3 || len(total.Authors) != 2 {
+			t.Errorf("dedupe %t: %+v, want 5 additions, 1 deletion, 6 changes, 3 commits and 2 authors", tt.dedupe, total)
+		}
+	}
+}
+
+func TestDedupeAcrossDays(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(2)}})
+
+	tests := []struct {
+		flag  string
+		files string
+	}{
+		{"", "1"},
+		{"--dedupe-across-days", "1"},
+		{"--dedupe-across-days=false", "2"},
+	}
+	for _, tt := range tests {
+		args := []string{".", "2024-03-01", "2024-03-02"}
+		if tt.flag != "" {
+			args = append([]string{tt.flag}, args...)
+		}
+		if row := tableRow(mustRun(t, r.dir, args...), "Total"); row[1] != tt.files {
+			t.Errorf("%q: %s files in total, want %s", tt.flag, row[1], tt.files)
+		}
+	}
+}
+
+func TestMostChangedDays(t *testing.T) {
+	tests := []struct {
+		days      map[string][2]int // additions and deletions by date
+		additions string
+		deletions string
+	}{
+		{nil, "", ""},
+		{map[string][2]int{"2024-03-01": {5, 0}}, "2024-03-01", "2024-03-01"},
+		{map[string][2]int{"2024-03-01": {5, 9}, "2024-03-02": {842, 1}, "2024-03-03": {3, 531}}, "2024-03-02", "2024-03-03"},
+		{map[string][2]int{"2024-03-03": {7, 2}, "2024-03-02": {7, 2}, "2024-03-04": {1, 2}}, "2024-03-02", "2024-03-02"},
+	}
+	for _, tt := range tests {
+		report := &Report{DailyStats: make(map[string]*DailyStats)}
+		for date, changes := range tt.days {
+			report.DailyStats[date] = &DailyStats{Additions: changes[0], Deletions: changes[1]}
+		}
+		if additions, deletions := mostChangedDays(report); additions != tt.additions || deletions != tt.deletions {
+			t.Errorf("%v: most additions on %q and deletions on %q, want %q and %q", tt.days, additions, deletions, tt.additions, tt.deletions)
+		}
+	}
+}
+
+func TestHighlights(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(3)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(1), "b": lines(1200)}})
+	r.commit(testCommit{when: "2024-03-03T10:00:00Z", files: map[string]string{"b": ""}})
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--highlights"}, "Most additions: 2024-03-02 (+1200)\nMost deletions: 2024-03-03 (-1200)\n"},
+		{[]string{"--highlights", "--humanize=comma"}, "Most additions: 2024-03-02 (+1,200)\nMost deletions: 2024-03-03 (-1,200)\n"},
+	}
+	for _, tt := range tests {
+		out := mustRun(t, r.dir, append(tt.args, ".", "2024-03-01", "2024-03-03")...)
+		if !strings.Contains(out, tt.want) {
+			t.Errorf("%v: output lacks %q:\n%s", tt.args, tt.want, out)
+		}
+	}
+
+	if out := mustRun(t, r.dir, ".", "2024-03-01", "2024-03-03"); strings.Contains(out, "Most additions") {
+		t.Errorf("highlights printed without --highlights:\n%s", out)
+	}
+	if out := mustRun(t, r.dir, "--highlights", ".", "2024-04-01", "2024-04-02"); strings.Contains(out, "Most additions") {
+		t.Errorf("highlights printed for a range without commits:\n%s", out)
+	}
+}
+
+func TestAverages(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-02-28T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(5)}})
+	r.commit(testCommit{when: "2024-03-03T10:00:00Z", files: map[string]string{"b": lines(6)}})
+
+	tests := []struct {
+		from, to string
+		want     string
+	}{
+		{"2024-03-01", "2024-03-05", "Average changes: 5.0 per active day, 2.0 per calendar day\n"},
+		{"2024-03-01", "2024-03-03", "Average changes: 5.0 per active day, 3.3 per calendar day\n"},
+		{"2024-03-01", "2024-03-01", "Average changes: 4.0 per active day, 4.0 per calendar day\n"},
+		{"2024-03-02", "2024-03-04", "Average changes: 6.0 per active day, 2.0 per calendar day\n"},
+	}
+	for _, tt := range tests {
+		out := mustRun(t, r.dir, "--averages", ".", tt.from, tt.to)
+		if !strings.Contains(out, tt.want) {
+			t.Errorf("%s ~ %s: want %q in:\n%s", tt.from, tt.to, tt.want, out)
+		}
+		if out := mustRun(t, r.dir, ".", tt.from, tt.to); strings.Contains(out, "Average changes") {
+			t.Errorf("%s ~ %s: averages printed without --averages:\n%s", tt.from, tt.to, out)
+		}
+	}
+
+	report := &Report{DailyStats: map[string]*DailyStats{}, Options: &Options{}}
+	if perActiveDay, perDay := averageChanges(report, &DailyStats{}); perActiveDay != 0 || perDay != 0 {
+		t.Errorf("averages of an empty range: %v, %v, want 0", perActiveDay, perDay)
+	}
+}
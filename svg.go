@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Margins around the plot of --format svg, leaving room for the axis labels.
+const (
+	svgMarginLeft   = 60
+	svgMarginRight  = 10
+	svgMarginTop    = 30
+	svgMarginBottom = 30
+	svgLabelSpacing = 80 // least pixels between two date labels
+)
+
+// chartSize is the size in pixels of the chart written by --format svg.
+type chartSize struct {
+	Width  int
+	Height int
+}
+
+var defaultChartSize = chartSize{800, 300}
+
+// parseChartSize parses a size given as WIDTHxHEIGHT, such as 800x300.
+func parseChartSize(value string) (chartSize, error) {
+	widthStr, heightStr, ok := strings.Cut(strings.ToLower(value), "x")
+	if !ok {
+		return chartSize{}, fmt.Errorf("expected WIDTHxHEIGHT, got %q", value)
+	}
+	width, err := strconv.Atoi(strings.TrimSpace(widthStr))
+	if err != nil || width < svgMarginLeft+svgMarginRight+100 {
+		return chartSize{}, fmt.Errorf("invalid width %q, expected at least %d", widthStr, svgMarginLeft+svgMarginRight+100)
+	}
+	height, err := strconv.Atoi(strings.TrimSpace(heightStr))
+	if err != nil || height < svgMarginTop+svgMarginBottom+100 {
+		return chartSize{}, fmt.Errorf("invalid height %q, expected at least %d", heightStr, svgMarginTop+svgMarginBottom+100)
+	}
+	return chartSize{width, height}, nil
+}
+
+// writeSVG writes a standalone bar chart of every day in the range, with
+// the additions rising above the axis in the middle and the deletions
+// hanging below it, both on the same scale. Days without commits take up
+// their slot with empty bars, so gaps in the work show as gaps in the chart.
+func writeSVG(w io.Writer, report *Report) error {
+	size := report.Options.ChartSize
+	plotWidth := float64(size.Width - svgMarginLeft - svgMarginRight)
+	plotHeight := float64(size.Height - svgMarginTop - svgMarginBottom)
+	middle := float64(svgMarginTop) + plotHeight/2
+
+	var dates []string
+	largest := 0
+	for d := report.StartDate; !d.After(report.EndDate); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		dates = append(dates, date)
+		if stats, ok := report.DailyStats[date]; ok {
+			largest = max(largest, stats.Additions, stats.Deletions)
+		}
+	}
+	scale := 0.0
+	if largest > 0 {
+		scale = plotHeight / 2 / float64(largest)
+	}
+	slot := plotWidth / float64(len(dates))
+	barWidth := max(slot*0.8, 1)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="11">
+`, size.Width, size.Height, size.Width, size.Height)
+	fmt.Fprintf(&b, "<rect width=\"%d\" height=\"%d\" fill=\"#ffffff\"/>\n", size.Width, size.Height)
+	fmt.Fprintf(&b, "<text x=\"%d\" y=\"%d\" font-size=\"13\">%s</text>\n",
+		svgMarginLeft, svgMarginTop-12, formatDateRange(report.StartDate, report.EndDate))
+
+	// The axes, with the largest count at the top and bottom of the y axis.
+	left, right := float64(svgMarginLeft), float64(size.Width-svgMarginRight)
+	bottom := float64(svgMarginTop) + plotHeight
+	fmt.Fprintf(&b, "<line x1=\"%.1f\" y1=\"%d\" x2=\"%.1f\" y2=\"%.1f\" stroke=\"#57606a\"/>\n", left, svgMarginTop, left, bottom)
+	fmt.Fprintf(&b, "<line x1=\"%.1f\" y1=\"%.1f\" x2=\"%.1f\" y2=\"%.1f\" stroke=\"#57606a\"/>\n", left, middle, right, middle)
+	for _, tick := range []struct {
+		y     float64
+		label string
+	}{
+		{float64(svgMarginTop), "+" + formatCount(largest)},
+		{middle, "0"},
+		{bottom, "-" + formatCount(largest)},
+	} {
+		fmt.Fprintf(&b, "<text x=\"%.1f\" y=\"%.1f\" text-anchor=\"end\" dominant-baseline=\"middle\">%s</text>\n", left-6, tick.y, tick.label)
+	}
+
+	step := max(1, int(float64(svgLabelSpacing)/slot+0.999))
+	for i := 0; i < len(dates); i += step {
+		x := left + slot*(float64(i)+0.5)
+		fmt.Fprintf(&b, "<line x1=\"%.1f\" y1=\"%.1f\" x2=\"%.1f\" y2=\"%.1f\" stroke=\"#57606a\"/>\n", x, bottom, x, bottom+4)
+		fmt.Fprintf(&b, "<text x=\"%.1f\" y=\"%.1f\" text-anchor=\"middle\">%s</text>\n", x, bottom+16, dates[i])
+	}
+
+	for i, date := range dates {
+		additions, deletions := 0, 0
+		if stats, ok := report.DailyStats[date]; ok {
+			additions, deletions = stats.Additions, stats.Deletions
+		}
+		x := left + slot*float64(i) + (slot-barWidth)/2
+		up, down := float64(additions)*scale, float64(deletions)*scale
+		fmt.Fprintf(&b, "<g class=\"day\"><title>%s: +%d -%d</title>", date, additions, deletions)
+		fmt.Fprintf(&b, "<rect x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" fill=\"#2da44e\"/>", x, middle-up, barWidth, up)
+		fmt.Fprintf(&b, "<rect x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" fill=\"#cf222e\"/></g>\n", x, middle, barWidth, down)
+	}
+
+	b.WriteString("</svg>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestParseChartSize(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    chartSize
+		wantErr bool
+	}{
+		{"800x300", chartSize{800, 300}, false},
+		{"1200X400", chartSize{1200, 400}, false},
+		{" 640 x 240 ", chartSize{640, 240}, false},
+		{"170x160", chartSize{170, 160}, false},
+		{"169x160", chartSize{}, true},
+		{"170x159", chartSize{}, true},
+		{"800", chartSize{}, true},
+		{"800x", chartSize{}, true},
+		{"widexhigh", chartSize{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseChartSize(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseChartSize(%q) error = %v, want error %t", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseChartSize(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+// svgChart is the part of the chart of --format svg checked by TestSVG.
+type svgChart struct {
+	Width  string `xml:"width,attr"`
+	Height string `xml:"height,attr"`
+	Days   []struct {
+		Title string `xml:"title"`
+		Bars  []struct {
+			Y      string `xml:"y,attr"`
+			Height string `xml:"height,attr"`
+		} `xml:"rect"`
+	} `xml:"g"`
+}
+
+func TestSVG(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-02-28T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(5)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(3)}})
+
+	// bar is the y and height of the additions and of the deletions bar.
+	type bar [4]string
+	tests := []struct {
+		flags  string
+		width  string
+		height string
+		days   map[string]bar
+	}{
+		{"", "800", "300", map[string]bar{
+			"2024-03-01: +4 -0": {"30.0", "120.0", "150.0", "0.0"},
+			"2024-03-02: +0 -2": {"150.0", "0.0", "150.0", "60.0"},
+			"2024-03-03: +0 -0": {"150.0", "0.0", "150.0", "0.0"},
+		}},
+		{"--chart-size 400x200", "400", "200", map[string]bar{
+			"2024-03-01: +4 -0": {"30.0", "70.0", "100.0", "0.0"},
+			"2024-03-02: +0 -2": {"100.0", "0.0", "100.0", "35.0"},
+			"2024-03-03: +0 -0": {"100.0", "0.0", "100.0", "0.0"},
+		}},
+	}
+	for _, tt := range tests {
+		out := mustRun(t, r.dir, append(strings.Fields(tt.flags), "--format", "svg", ".", "2024-03-01", "2024-03-03")...)
+		var chart svgChart
+		if err := xml.Unmarshal([]byte(out), &chart); err != nil {
+			t.Fatalf("%q: %v in:\n%s", tt.flags, err, out)
+		}
+		if chart.Width != tt.width || chart.Height != tt.height {
+			t.Errorf("%q: %sx%s, want %sx%s", tt.flags, chart.Width, chart.Height, tt.width, tt.height)
+		}
+		if len(chart.Days) != len(tt.days) {
+			t.Errorf("%q: %d days, want %d", tt.flags, len(chart.Days), len(tt.days))
+		}
+		for _, day := range chart.Days {
+			want, ok := tt.days[day.Title]
+			if !ok || len(day.Bars) != 2 {
+				t.Errorf("%q: unexpected day %q with %d bars", tt.flags, day.Title, len(day.Bars))
+				continue
+			}
+			if got := (bar{day.Bars[0].Y, day.Bars[0].Height, day.Bars[1].Y, day.Bars[1].Height}); got != want {
+				t.Errorf("%q: %s has the bars %v, want %v", tt.flags, day.Title, got, want)
+			}
+		}
+	}
+
+	if stdout, stderr, code := runGitStat(t, r.dir, "--chart-size", "100x100", "--format", "svg", ".", "2024-03-01", "2024-03-03"); code == 0 || !strings.Contains(stdout+stderr, `invalid width "100"`) {
+		t.Errorf("--chart-size 100x100 exited with %d: %s%s", code, stdout, stderr)
+	}
+}
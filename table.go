@@ -0,0 +1,435 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/text/width"
+)
+
+// summaryMarker is printed above the Total row with --summary-marker, for
+// scripts to find the totals by.
+const summaryMarker = "### SUMMARY ###"
+
+const (
+	colorReset  = "\033[0m"
+	colorOrange = "\033[38;5;208m"
+	colorCyan   = "\033[36m"
+	colorGreen  = "\033[32m"
+	colorRed    = "\033[31m"
+)
+
+const (
+	dateRangeWidth    = 25
+	filesChangedWidth = 15
+	additionsWidth    = 11
+	deletionsWidth    = 11
+	totalChangesWidth = 15
+	commitsWidth      = 9
+	perCommitWidth    = 12
+	peakHourWidth     = 11
+	shareWidth        = 9
+	authorsWidth      = 9
+	bytesWidth        = 15
+	netFilesWidth     = 11
+	weightedWidth     = 10
+	activeHoursWidth  = 14
+	perHourWidth      = 10
+)
+
+// tableColumn is a column of the stats table following the date range (or
+// other label) column.
+type tableColumn struct {
+	title string
+	width int
+	value func(stats *DailyStats) string
+}
+
+// labelWidth is the width of the leading column holding the date range or
+// group name.
+var labelWidth = dateRangeWidth
+
+var tableColumns = []tableColumn{
+	{"Files Changed", filesChangedWidth, func(stats *DailyStats) string {
+		return formatCount(len(stats.FilesChanged))
+	}},
+	{"Additions", additionsWidth, func(stats *DailyStats) string {
+		return formatCount(stats.Additions)
+	}},
+	{"Deletions", deletionsWidth, func(stats *DailyStats) string {
+		return formatCount(stats.Deletions)
+	}},
+	{"Total Changes", totalChangesWidth, func(stats *DailyStats) string {
+		return formatCount(stats.Changes)
+	}},
+}
+
+var commitsColumn = tableColumn{"Commits", commitsWidth, func(stats *DailyStats) string {
+	return formatCount(stats.Commits)
+}}
+
+// perCommitColumns show how many commits were made and how many lines they
+// changed on average. The average is left blank when there are no commits.
+var perCommitColumns = []tableColumn{
+	commitsColumn,
+	{"Per Commit", perCommitWidth, func(stats *DailyStats) string {
+		if stats.Commits == 0 {
+			return ""
+		}
+		return formatFloat(float64(stats.Changes)/float64(stats.Commits), 1)
+	}},
+}
+
+// authorsColumn shows how many people committed.
+var authorsColumn = tableColumn{"Authors", authorsWidth, func(stats *DailyStats) string {
+	return formatCount(len(stats.Authors))
+}}
+
+// bytesColumn shows the bytes changed counted by --bytes.
+var bytesColumn = tableColumn{"Bytes Changed", bytesWidth, func(stats *DailyStats) string {
+	return formatCount(stats.Bytes)
+}}
+
+// netFilesColumn shows the files added minus those deleted, counted by
+// --net-files, with a sign.
+var netFilesColumn = tableColumn{"Net Files", netFilesWidth, func(stats *DailyStats) string {
+	return formatDelta(stats.NetFiles)
+}}
+
+// peakHourColumn shows the hour with the most commits, the earliest one on a
+// tie.
+var peakHourColumn = tableColumn{"Peak Hour", peakHourWidth, func(stats *DailyStats) string {
+	peak := 0
+	for hour, n := range stats.Hours {
+		if n > stats.Hours[peak] {
+			peak = hour
+		}
+	}
+	if stats.Hours[peak] == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%02d:00", peak)
+}}
+
+// fitColumns widens the columns whose values would not fit into their
+// default width, keeping one space of padding on each side.
+func fitColumns(rows []*DailyStats) {
+	for i := range tableColumns {
+		col := &tableColumns[i]
+		for _, stats := range rows {
+			if width := textWidth(col.value(stats)) + 2; width > col.width {
+				col.width = width
+			}
+		}
+	}
+}
+
+// fitLabels widens the label column to fit the longest label.
+func fitLabels(labels []string) {
+	for _, label := range labels {
+		if width := textWidth(label) + 1; width > labelWidth {
+			labelWidth = width
+		}
+	}
+}
+
+func tableWidth() int {
+	width := labelWidth
+	for _, col := range tableColumns {
+		width += col.width + 1 // +1 for the separator
+	}
+	return width
+}
+
+// printDailyTable prints one row per day with commits and a total row.
+// Consecutive days without commits are folded into a single highlighted row,
+// or left out with --only-days-with-commits.
+func printDailyTable(w io.Writer, report *Report) {
+	total := totalStats(report)
+
+	rows := make([]*DailyStats, 0, len(report.DailyStats)+1)
+	for _, stats := range report.DailyStats {
+		rows = append(rows, stats)
+	}
+	rows = append(rows, total)
+	fitColumns(rows)
+	if report.Options.Indicators {
+		// The range of the whole report is the longest label a row of
+		// days without commits can have.
+		fitLabels([]string{withIndicator(formatDateRange(report.StartDate, report.EndDate), nil, report.Options)})
+	}
+
+	if report.Options.OnlyActiveDays {
+		fmt.Fprintf(w, "%s\n", formatDateRange(report.StartDate, report.EndDate))
+	}
+	if report.PrimaryLanguage != "" {
+		fmt.Fprintf(w, "Primary language: %s\n", report.PrimaryLanguage)
+	}
+
+	printTableHeader(w, "Date Range")
+
+	// Runs of days without commits, or excluded with --exclude-range, are
+	// folded into a single banner.
+	var runStart time.Time
+	var runDays int
+	var runExcluded bool
+	var runTags []string
+	var lastActive time.Time
+	var previous *DailyStats
+
+	flush := func(end time.Time) {
+		if runDays == 0 {
+			return
+		}
+		label := formatDateRange(runStart, end)
+		if runExcluded {
+			printExcludedRow(w, label, runDays, formatTags(runTags))
+		} else {
+			printNoChangeRow(w, withIndicator(label, nil, report.Options), runDays, formatTags(runTags))
+		}
+		runDays = 0
+		runTags = nil
+	}
+
+	for d := report.StartDate; !d.After(report.EndDate); d = d.AddDate(0, 0, 1) {
+		dateStr := d.Format("2006-01-02")
+		stats, ok := report.DailyStats[dateStr]
+
+		if ok {
+			flush(d.AddDate(0, 0, -1))
+			note := formatTags(report.TagDates[dateStr])
+			if report.Options.ShowDelta && previous != nil {
+				note = formatChangesDelta(stats.Changes-previous.Changes) + note
+			}
+			previous = stats
+			if gap := report.Options.ResumeGap; gap > 0 && !lastActive.IsZero() {
+				if days := int(d.Sub(lastActive).Hours()/24) - 1; days >= gap {
+					note += fmt.Sprintf(" (resumed after %d days)", days)
+				}
+			}
+			lastActive = d
+			printTableRow(w, withIndicator(dateStr, stats, report.Options), stats, note)
+			if report.Options.VerboseFiles && len(stats.Files) > 0 {
+				printFileChurn(w, stats.Files)
+				printRule(w, columnWidths()...)
+			}
+			continue
+		}
+
+		if report.Options.OnlyActiveDays {
+			continue
+		}
+		isExcluded := excluded(d, report.Options.ExcludeRanges)
+		if runDays > 0 && isExcluded != runExcluded {
+			flush(d.AddDate(0, 0, -1))
+		}
+		if runDays == 0 {
+			runStart = d
+			runExcluded = isExcluded
+		}
+		runDays++
+		runTags = append(runTags, report.TagDates[dateStr]...)
+	}
+
+	flush(report.EndDate)
+
+	if report.Options.SummaryMarker {
+		fmt.Fprintf(w, "%s\n", summaryMarker)
+	}
+	printTableRow(w, "Total", total, "")
+	printSummary(w, report, total)
+}
+
+// printSummary prints the lines asked for by --highlights, --averages,
+// --streaks, --review-lag, --reverts, --trend, --anomalies and --explain
+// below the table.
+func printSummary(w io.Writer, report *Report, total *DailyStats) {
+	if report.Options.Highlights {
+		printHighlights(w, report)
+	}
+	if report.Options.Averages {
+		printAverages(w, report, total)
+	}
+	if report.Options.Streaks {
+		printStreaks(w, report)
+	}
+	if report.Options.ReviewLag {
+		printReviewLag(w, total)
+	}
+	if report.Options.Reverts {
+		printReverts(w, total)
+	}
+	if report.Options.Trend {
+		printTrend(w, report)
+	}
+	if report.Options.Anomalies {
+		printAnomalies(w, report)
+	}
+	if report.Options.Explain != "" {
+		printExplanation(w, report)
+	}
+}
+
+// columnWidths returns the width of the label column followed by those of
+// the stats columns.
+func columnWidths() []int {
+	widths := []int{labelWidth}
+	for _, col := range tableColumns {
+		widths = append(widths, col.width)
+	}
+	return widths
+}
+
+// printTopDaysTable prints the n days with the most changes, largest first.
+// Ties are broken by the later date first.
+func printTopDaysTable(w io.Writer, report *Report, n int) {
+	dates := sortedDates(report.DailyStats)
+	sort.SliceStable(dates, func(i, j int) bool {
+		a, b := report.DailyStats[dates[i]], report.DailyStats[dates[j]]
+		if a.Changes != b.Changes {
+			return a.Changes > b.Changes
+		}
+		return dates[i] > dates[j]
+	})
+	if len(dates) > n {
+		dates = dates[:n]
+	}
+
+	rows := make([]*DailyStats, 0, len(dates))
+	for _, date := range dates {
+		rows = append(rows, report.DailyStats[date])
+	}
+	fitColumns(rows)
+
+	fmt.Fprintf(w, "%s\n", formatDateRange(report.StartDate, report.EndDate))
+	if report.PrimaryLanguage != "" {
+		fmt.Fprintf(w, "Primary language: %s\n", report.PrimaryLanguage)
+	}
+	printTableHeader(w, "Date")
+
+	for _, date := range dates {
+		printTableRow(w, date, report.DailyStats[date], formatTags(report.TagDates[date]))
+	}
+}
+
+func printTableHeader(w io.Writer, firstColumn string) {
+	fitWidth()
+
+	fmt.Fprint(w, centerText(firstColumn, labelWidth))
+	for _, col := range tableColumns {
+		fmt.Fprintf(w, "%s%s", currentStyle.vertical, centerText(col.title, col.width))
+	}
+	fmt.Fprintln(w)
+
+	printRule(w, columnWidths()...)
+}
+
+func printTableRow(w io.Writer, dateRange string, stats *DailyStats, note string) {
+	fmt.Fprint(w, padText(dateRange, labelWidth))
+	for _, col := range tableColumns {
+		fmt.Fprintf(w, "%s%s", currentStyle.vertical, centerText(col.value(stats), col.width))
+	}
+	fmt.Fprintf(w, "%s\n", note)
+
+	printRule(w, columnWidths()...)
+}
+
+func printNoChangeRow(w io.Writer, dateRange string, days int, note string) {
+	printBannerRow(w, dateRange, fmt.Sprintf("%d %s no commits", days, dayUnit(days)), note)
+}
+
+// printExcludedRow prints the banner for days left out with --exclude-range.
+func printExcludedRow(w io.Writer, dateRange string, days int, note string) {
+	printBannerRow(w, dateRange, fmt.Sprintf("%d %s excluded", days, dayUnit(days)), note)
+}
+
+// formatChangesDelta formats the change in total changes from the previous
+// day with commits for --show-delta, green when they went up and red when
+// they went down.
+func formatChangesDelta(delta int) string {
+	switch {
+	case delta > 0:
+		return fmt.Sprintf(" %s%s%s", colorGreen, formatArrowDelta(delta), colorReset)
+	case delta < 0:
+		return fmt.Sprintf(" %s%s%s", colorRed, formatArrowDelta(delta), colorReset)
+	}
+	return " " + formatArrowDelta(delta)
+}
+
+func dayUnit(days int) string {
+	if days > 1 {
+		return "days"
+	}
+	return "day"
+}
+
+// printBannerRow prints a highlighted row with message spanning all the
+// stats columns.
+func printBannerRow(w io.Writer, dateRange string, message string, note string) {
+	totalWidth := tableWidth()
+
+	if rule := currentStyle.rule(totalWidth); rule != "" {
+		fmt.Fprintf(w, "%s%s%s\n", colorOrange, rule, colorReset)
+	}
+
+	fmt.Fprintf(w, "%s%s%s%s%s%s%s%s\n",
+		colorOrange,
+		padText(dateRange, labelWidth),
+		colorReset,
+		currentStyle.vertical,
+		colorOrange,
+		centerText(message, totalWidth-labelWidth-1),
+		colorReset,
+		note)
+
+	printRule(w, totalWidth)
+}
+
+// textWidth returns the number of terminal columns text takes up. Wide
+// characters, such as CJK and most emoji, take up two.
+func textWidth(text string) int {
+	n := 0
+	for _, r := range text {
+		n += runeWidth(r)
+	}
+	return n
+}
+
+func runeWidth(r rune) int {
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+// truncateText cuts text down to at most width columns.
+func truncateText(text string, maxWidth int) string {
+	n := 0
+	for i, r := range text {
+		if n+runeWidth(r) > maxWidth {
+			return text[:i]
+		}
+		n += runeWidth(r)
+	}
+	return text
+}
+
+func centerText(text string, width int) string {
+	if textWidth(text) >= width {
+		return padText(text, width)
+	}
+	leftPad := (width - textWidth(text)) / 2
+	rightPad := width - textWidth(text) - leftPad
+	return fmt.Sprintf("%s%s%s", strings.Repeat(" ", leftPad), text, strings.Repeat(" ", rightPad))
+}
+
+func padText(text string, width int) string {
+	if textWidth(text) > width {
+		text = truncateText(text, width)
+	}
+	return fmt.Sprintf("%s%s", text, strings.Repeat(" ", width-textWidth(text)))
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPerCommitColumn(t *testing.T) {
+	perCommit := perCommitColumns[1]
+
+	tests := []struct {
+		changes, commits int
+		want             string
+	}{
+		{10, 4, "2.5"},
+		{7, 3, "2.3"},
+		{0, 2, "0.0"},
+		{12, 1, "12.0"},
+		{5, 0, ""},
+		{0, 0, ""},
+	}
+	for _, tt := range tests {
+		stats := &DailyStats{Changes: tt.changes, Commits: tt.commits}
+		if got := perCommit.value(stats); got != tt.want {
+			t.Errorf("%d changes in %d commits: %q per commit, want %q", tt.changes, tt.commits, got, tt.want)
+		}
+	}
+}
+
+func TestOnlyDaysWithCommits(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-05T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-20T10:00:00Z", files: map[string]string{"a": lines(2)}})
+
+	tests := []struct {
+		flag     string
+		banners  int
+		firstRow string
+	}{
+		{"", 3, "Primary language: Other"},
+		{"--only-days-with-commits", 0, "2024-03-01 ~ 03-31"},
+	}
+	for _, tt := range tests {
+		args := []string{".", "2024-03-01", "2024-03-31"}
+		if tt.flag != "" {
+			args = append([]string{tt.flag}, args...)
+		}
+		out := stripANSI(mustRun(t, r.dir, args...))
+
+		var days, banners int
+		for _, line := range strings.Split(out, "\n") {
+			switch {
+			case strings.Contains(line, "no commits"):
+				banners++
+			case strings.HasPrefix(line, "2024-03-") && strings.Contains(line, "|"):
+				days++
+			}
+		}
+		if days != 2 || banners != tt.banners {
+			t.Errorf("%q: %d days and %d banners, want 2 and %d:\n%s", tt.flag, days, banners, tt.banners, out)
+		}
+		if first, _, _ := strings.Cut(out, "\n"); first != tt.firstRow {
+			t.Errorf("%q: starts with %q, want %q", tt.flag, first, tt.firstRow)
+		}
+	}
+}
+
+func TestPeakHourColumn(t *testing.T) {
+	tests := []struct {
+		hours map[int]int
+		want  string
+	}{
+		{map[int]int{10: 1, 15: 2}, "15:00"},
+		{map[int]int{9: 2, 14: 2}, "09:00"},
+		{map[int]int{0: 1}, "00:00"},
+		{map[int]int{23: 3, 0: 1}, "23:00"},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		stats := &DailyStats{}
+		for hour, n := range tt.hours {
+			stats.Hours[hour] = n
+		}
+		if got := peakHourColumn.value(stats); got != tt.want {
+			t.Errorf("commits by hour %v: peak %q, want %q", tt.hours, got, tt.want)
+		}
+	}
+}
+
+func TestPeakHour(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00+02:00", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-01T15:10:00+02:00", files: map[string]string{"a": lines(2)}})
+	r.commit(testCommit{when: "2024-03-01T15:50:00+02:00", files: map[string]string{"a": lines(3)}})
+	r.commit(testCommit{when: "2024-03-02T09:00:00-05:00", files: map[string]string{"a": lines(4)}})
+
+	out := mustRun(t, r.dir, "--peak-hour", ".", "2024-03-01", "2024-03-02")
+	for day, want := range map[string]string{"2024-03-01": "15:00", "2024-03-02": "09:00"} {
+		if row := tableRow(out, day); row == nil || row[5] != want {
+			t.Errorf("%s: %q, want the peak in the author's time zone, %s", day, row, want)
+		}
+	}
+}
+
+func TestTopDays(t *testing.T) {
+	r := newTestRepo(t)
+	for day, n := range map[string]int{"2024-03-01": 5, "2024-03-02": 9, "2024-03-03": 2, "2024-03-04": 9, "2024-03-05": 1} {
+		r.commit(testCommit{when: day + "T10:00:00Z", files: map[string]string{day: lines(n)}})
+	}
+
+	tests := []struct {
+		n    string
+		want []string
+	}{
+		{"1", []string{"2024-03-04"}},
+		{"3", []string{"2024-03-04", "2024-03-02", "2024-03-01"}},
+		{"10", []string{"2024-03-04", "2024-03-02", "2024-03-01", "2024-03-03", "2024-03-05"}},
+	}
+	for _, tt := range tests {
+		out := mustRun(t, r.dir, "--top-days", tt.n, ".", "2024-03-01", "2024-03-07")
+		if first, _, _ := strings.Cut(out, "\n"); first != "2024-03-01 ~ 03-07" {
+			t.Errorf("--top-days %s: starts with %q, want the full range", tt.n, first)
+		}
+
+		var days []string
+		for _, line := range strings.Split(out, "\n") {
+			if cells := strings.Split(line, "|"); len(cells) > 1 && strings.HasPrefix(cells[0], "2024-") {
+				days = append(days, strings.TrimSpace(cells[0]))
+			}
+		}
+		if strings.Join(days, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("--top-days %s: %v, want %v", tt.n, days, tt.want)
+		}
+	}
+}
+
+func TestResumeGap(t *testing.T) {
+	r := newTestRepo(t)
+	for _, day := range []string{"2024-03-01", "2024-03-12", "2024-03-14", "2024-03-20"} {
+		r.commit(testCommit{when: day + "T10:00:00Z", files: map[string]string{"a": day + "\n"}})
+	}
+
+	tests := []struct {
+		gap     string
+		resumed map[string]string
+	}{
+		{"0", nil},
+		{"10", map[string]string{"2024-03-12": "10"}},
+		{"5", map[string]string{"2024-03-12": "10", "2024-03-20": "5"}},
+		{"11", nil},
+	}
+	for _, tt := range tests {
+		// The first day with commits is not marked, however late in the
+		// range it comes.
+		out := stripANSI(mustRun(t, r.dir, "--resume-gap", tt.gap, ".", "2024-02-15", "2024-03-20"))
+		for _, day := range []string{"2024-03-01", "2024-03-12", "2024-03-14", "2024-03-20"} {
+			row := tableRow(out, day)
+			if row == nil {
+				t.Fatalf("no row for %s:\n%s", day, out)
+			}
+			want := ""
+			if days, ok := tt.resumed[day]; ok {
+				want = "(resumed after " + days + " days)"
+			}
+			if last := row[len(row)-1]; want == "" && strings.Contains(last, "resumed") || !strings.HasSuffix(last, want) {
+				t.Errorf("--resume-gap %s: %s ends in %q, want %q", tt.gap, day, last, want)
+			}
+		}
+	}
+
+	if stdout, stderr, code := runGitStat(t, r.dir, "--resume-gap", "-1", ".", "2024-03-01", "2024-03-20"); code == 0 || !strings.Contains(stderr, "--resume-gap must not be negative") {
+		t.Errorf("--resume-gap -1 exited with %d: %s%s", code, stdout, stderr)
+	}
+}
+
+func TestFormatChangesDelta(t *testing.T) {
+	tests := []struct {
+		delta int
+		want  string
+	}{
+		{5, " " + colorGreen + "+5" + colorReset},
+		{-3, " " + colorRed + "-3" + colorReset},
+		{0, " 0"},
+	}
+	for _, tt := range tests {
+		if got := formatChangesDelta(tt.delta); got != tt.want {
+			t.Errorf("formatChangesDelta(%d) = %q, want %q", tt.delta, got, tt.want)
+		}
+	}
+}
+
+func TestShowDelta(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-02-28T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(2)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(4)}})
+	r.commit(testCommit{when: "2024-03-04T10:00:00Z", files: map[string]string{"a": lines(8)}})
+	r.commit(testCommit{when: "2024-03-05T10:00:00Z", files: map[string]string{"a": lines(4)}})
+	r.commit(testCommit{when: "2024-03-06T10:00:00Z", files: map[string]string{"a": lines(3)}})
+
+	tests := []struct {
+		flag  string
+		notes map[string]string // the note after each day's row
+	}{
+		{"", map[string]string{"2024-03-01": "", "2024-03-02": "", "2024-03-04": "", "2024-03-05": "", "2024-03-06": ""}},
+		// The first day has nothing to compare with, and 03-04 is compared
+		// with 03-02 across the day without commits.
+		{"--show-delta", map[string]string{"2024-03-01": "", "2024-03-02": "+1", "2024-03-04": "+2", "2024-03-05": "0", "2024-03-06": "-3"}},
+	}
+	for _, tt := range tests {
+		args := []string{".", "2024-03-01", "2024-03-06"}
+		if tt.flag != "" {
+			args = append([]string{tt.flag}, args...)
+		}
+		out := stripANSI(mustRun(t, r.dir, args...))
+		for day, want := range tt.notes {
+			row := tableRow(out, day)
+			if row == nil {
+				t.Fatalf("no row for %s:\n%s", day, out)
+			}
+			// The note follows the Total Changes of the last cell.
+			last := row[len(row)-1]
+			if note := strings.Join(strings.Fields(last)[1:], " "); note != want {
+				t.Errorf("%q: %s ends in %q, want the note %q", tt.flag, day, last, want)
+			}
+		}
+	}
+}
+
+func TestSummaryMarker(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a": lines(2)}})
+
+	tests := []struct {
+		flags  string
+		marker bool
+	}{
+		{"", false},
+		{"--summary-marker", true},
+		{"--summary-marker --highlights", true},
+		{"--summary-marker --style unicode", true},
+		{"--summary-marker --format csv", false},
+	}
+	for _, tt := range tests {
+		out := mustRun(t, r.dir, append(strings.Fields(tt.flags), ".", "2024-03-01", "2024-03-02")...)
+		want := 0
+		if tt.marker {
+			want = 1
+		}
+		if got := strings.Count(out, summaryMarker); got != want {
+			t.Errorf("%q: the marker is printed %d times:\n%s", tt.flags, got, out)
+			continue
+		}
+		if !tt.marker {
+			continue
+		}
+		// The marker is the line above the Total row, so everything after it
+		// is the totals and the summary.
+		after := out[strings.Index(out, summaryMarker+"\n")+len(summaryMarker)+1:]
+		if !strings.HasPrefix(after, "Total ") {
+			t.Errorf("%q: the marker is followed by:\n%s", tt.flags, after)
+		}
+		if strings.Contains(tt.flags, "--highlights") && !strings.Contains(after, "Most additions") {
+			t.Errorf("%q: the summary is not below the marker:\n%s", tt.flags, out)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// getTagDates returns the tag names of the repository grouped by the day the
+// tag was created. Annotated tags use the tagger date, lightweight tags use
+// the committer date of the commit they point to.
+func getTagDates(repo *git.Repository) (map[string][]string, error) {
+	tags, err := repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+
+	tagDates := make(map[string][]string)
+
+	err = tags.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().Short()
+
+		if tag, err := repo.TagObject(ref.Hash()); err == nil {
+			date := tag.Tagger.When.Format("2006-01-02")
+			tagDates[date] = append(tagDates[date], name)
+			return nil
+		} else if err != plumbing.ErrObjectNotFound {
+			return err
+		}
+
+		commit, err := repo.CommitObject(ref.Hash())
+		if err == plumbing.ErrObjectNotFound {
+			// Lightweight tags pointing to trees or blobs have no date.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		date := commit.Committer.When.Format("2006-01-02")
+		tagDates[date] = append(tagDates[date], name)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, names := range tagDates {
+		sort.Strings(names)
+	}
+
+	return tagDates, nil
+}
+
+func formatTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" %s[%s]%s", colorCyan, strings.Join(tags, ", "), colorReset)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestAnnotate(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a.go": lines(1)}})
+	release := r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"a.go": lines(3)}})
+	r.commit(testCommit{when: "2024-03-03T10:00:00Z", files: map[string]string{"a.go": lines(4)}})
+
+	// A lightweight tag is dated by its commit, an annotated one by its
+	// tagger.
+	if _, err := r.repo.CreateTag("v1.0", release, nil); err != nil {
+		t.Fatal(err)
+	}
+	tagger := parseTestAuthor("")
+	tagger.When = time.Date(2024, 3, 2, 18, 0, 0, 0, time.UTC)
+	if _, err := r.repo.CreateTag("deploy-1", release, &git.CreateTagOptions{Tagger: &tagger, Message: "deploy"}); err != nil {
+		t.Fatal(err)
+	}
+
+	out := mustRun(t, r.dir, "--annotate", ".", "2024-03-01", "2024-03-03")
+
+	tests := []struct {
+		date string
+		note string
+	}{
+		{"2024-03-01", ""},
+		{"2024-03-02", "[deploy-1, v1.0]"},
+		{"2024-03-03", ""},
+	}
+	for _, tt := range tests {
+		row := tableRow(stripANSI(out), tt.date)
+		if row == nil {
+			t.Fatalf("no row for %s in:\n%s", tt.date, out)
+		}
+		last := row[len(row)-1]
+		if tt.note == "" && strings.Contains(last, "[") {
+			t.Errorf("%s is annotated with %q", tt.date, last)
+		}
+		if tt.note != "" && !strings.HasSuffix(last, tt.note) {
+			t.Errorf("%s ends in %q, want %q", tt.date, last, tt.note)
+		}
+	}
+}
+
+func TestGetTagDates(t *testing.T) {
+	r := newTestRepo(t)
+	first := r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": "a\n"}})
+	if _, err := r.repo.CreateTag("b", first, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.repo.CreateTag("a", first, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tagDates, err := getTagDates(r.repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(tagDates["2024-03-01"], ","); got != "a,b" {
+		t.Errorf("tags on 2024-03-01 = %q, want a,b sorted", got)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// noTeam groups the authors not listed in the team file.
+const noTeam = "(no team)"
+
+// loadTeams reads a team file with one `<team> = <email>, <email>, ...` entry
+// per line and maps every email, in lower case, to its team. Blank lines and
+// lines starting with `#` are ignored. An email may only belong to one team.
+func loadTeams(filename string) (map[string]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	teams := make(map[string]string)
+
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		team, members, ok := strings.Cut(line, "=")
+		team = strings.TrimSpace(team)
+		if !ok || team == "" {
+			return nil, fmt.Errorf("%s:%d: expected `<team> = <email>, <email>, ...`", filename, lineNo)
+		}
+
+		for _, email := range strings.Split(members, ",") {
+			email = strings.ToLower(strings.TrimSpace(email))
+			if email == "" {
+				continue
+			}
+			if other, ok := teams[email]; ok && other != team {
+				return nil, fmt.Errorf("%s:%d: %s is already in team %s", filename, lineNo, email, other)
+			}
+			teams[email] = team
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return teams, nil
+}
+
+// getTeamStats aggregates the changes by the team of the commit authors,
+// whose emails have been normalized with .mailmap.
+func getTeamStats(repo *git.Repository, startDate, endDate time.Time, opts *Options, teams map[string]string) (map[string]*DailyStats, error) {
+	return getGroupStats(repo, startDate, endDate, opts, func(c *object.Commit, stat object.FileStat) string {
+		if team, ok := teams[strings.ToLower(c.Author.Email)]; ok {
+			return team
+		}
+		return noTeam
+	})
+}
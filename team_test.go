@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoadTeams(t *testing.T) {
+	tests := []struct {
+		content string
+		want    map[string]string
+		err     string
+	}{
+		{
+			"# teams\nteam-a = alice@x.com, Bob@X.com\n\nteam-b=carol@x.com,\n",
+			map[string]string{"alice@x.com": "team-a", "bob@x.com": "team-a", "carol@x.com": "team-b"},
+			"",
+		},
+		{"team-a = alice@x.com\nteam-a = bob@x.com\n", map[string]string{"alice@x.com": "team-a", "bob@x.com": "team-a"}, ""},
+		{"team-a = alice@x.com\nteam-b = ALICE@x.com\n", nil, "teams:2: alice@x.com is already in team team-a"},
+		{"alice@x.com\n", nil, "teams:1: expected"},
+		{" = alice@x.com\n", nil, "teams:1: expected"},
+	}
+	for _, tt := range tests {
+		filename := filepath.Join(t.TempDir(), "teams")
+		if err := os.WriteFile(filename, []byte(tt.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		teams, err := loadTeams(filename)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("loadTeams(%q): %v, want an error containing %q", tt.content, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || fmt.Sprint(teams) != fmt.Sprint(tt.want) {
+			t.Errorf("loadTeams(%q) = %v, %v, want %v", tt.content, teams, err, tt.want)
+		}
+	}
+}
+
+func TestByTeam(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", author: "Alice <alice@x.com>", files: map[string]string{".mailmap": "Alice <alice@x.com> <alice@laptop.local>\n"}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", author: "Alice <alice@x.com>", files: map[string]string{"a": lines(3)}})
+	r.commit(testCommit{when: "2024-03-02T11:00:00Z", author: "Bob <bob@x.com>", files: map[string]string{"b": lines(2)}})
+	r.commit(testCommit{when: "2024-03-03T10:00:00Z", author: "alice <alice@laptop.local>", files: map[string]string{"a": lines(4)}})
+	r.commit(testCommit{when: "2024-03-03T11:00:00Z", author: "Carol <carol@x.com>", files: map[string]string{"c": lines(5)}})
+
+	teams := filepath.Join(t.TempDir(), "teams")
+	if err := os.WriteFile(teams, []byte("team-a = alice@x.com, bob@x.com\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := mustRun(t, r.dir, "--by-team", "--teams", teams, ".", "2024-03-02", "2024-03-03")
+	for label, want := range map[string][]string{
+		"team-a":    {"2", "6", "0", "6", "3"},
+		"(no team)": {"1", "5", "0", "5", "1"},
+	} {
+		if row := tableRow(out, label); row == nil || !slices.Equal(row[1:], want) {
+			t.Errorf("%s: %v, want %v:\n%s", label, row, want, out)
+		}
+	}
+
+	if stdout, stderr, code := runGitStat(t, r.dir, "--by-team", ".", "2024-03-02", "2024-03-03"); code == 0 || !strings.Contains(stderr, "--by-team needs --teams") {
+		t.Errorf("--by-team without --teams exited with %d: %s%s", code, stdout, stderr)
+	}
+}
@@ -0,0 +1,18 @@
+package main
+
+import "io"
+
+// plainWriter writes to w with the color escape sequences removed, for the
+// files of --output, including the one --tee writes next to stdout. The
+// report is printed a line or a cell at a time, so sequences are never split
+// across writes.
+type plainWriter struct {
+	w io.Writer
+}
+
+func (pw plainWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(pw.w, stripANSI(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// failingWriter fails every write, for the errors of the writers around it.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestPlainWriter(t *testing.T) {
+	tests := []struct {
+		writes []string
+		want   string
+	}{
+		{[]string{"plain\n"}, "plain\n"},
+		{[]string{"\x1b[38;5;208mno commits\x1b[0m", "|\n"}, "no commits|\n"},
+		{[]string{"\x1b[1m\x1b[32m+4\x1b[0m"}, "+4"},
+		// The hash linked with --commit-url is kept as plain text.
+		{[]string{" \x1b]8;;https://example.com/c/1234567\x1b\\1234567\x1b]8;;\x1b\\ |\n"}, " 1234567 |\n"},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		pw := plainWriter{&b}
+		for _, s := range tt.writes {
+			// The length written is that of the input, so
+			// io.MultiWriter does not see a short write.
+			if n, err := pw.Write([]byte(s)); n != len(s) || err != nil {
+				t.Errorf("Write(%q) = %d, %v, want %d", s, n, err, len(s))
+			}
+		}
+		if b.String() != tt.want {
+			t.Errorf("%q: wrote %q, want %q", tt.writes, b.String(), tt.want)
+		}
+	}
+
+	if _, err := (plainWriter{failingWriter{}}).Write([]byte("x")); err == nil {
+		t.Error("Write to a failing writer succeeded")
+	}
+}
+
+func TestShowsOnTerminal(t *testing.T) {
+	tests := []struct {
+		route    formatRoute
+		opts     Options
+		terminal bool
+		want     bool
+	}{
+		{formatRoute{"table", ""}, Options{}, true, true},
+		{formatRoute{"table", ""}, Options{}, false, false},
+		{formatRoute{"table", "report.txt"}, Options{}, true, false},
+		{formatRoute{"table", ""}, Options{OutputDir: "reports"}, true, false},
+		{formatRoute{"table", ""}, Options{Clipboard: true}, true, false},
+		// With --tee the report is shown on stdout, though it goes to
+		// a file as well.
+		{formatRoute{"table", "report.txt"}, Options{Tee: true}, true, true},
+		{formatRoute{"table", "report.txt"}, Options{Tee: true}, false, false},
+	}
+	for i, tt := range tests {
+		if got := showsOnTerminal(tt.route, &tt.opts, tt.terminal); got != tt.want {
+			t.Errorf("%d: showsOnTerminal(%v, %t) = %t, want %t", i, tt.route, tt.terminal, got, tt.want)
+		}
+	}
+}
+
+func TestTee(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-02-28T10:00:00Z", files: map[string]string{"a.txt": lines(1)}})
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a.txt": lines(4)}})
+
+	tests := []struct {
+		format string
+		want   string // in both stdout and the file
+	}{
+		{"table", "Total"},
+		{"csv", "2024-03-01,1,3,0,3"},
+		{"json", `"additions": 3`},
+	}
+	for _, tt := range tests {
+		output := filepath.Join(t.TempDir(), "report")
+		stdout := mustRun(t, r.dir, "--tee", "--format", tt.format, "--output", output, ".", "2024-03-01", "2024-03-02")
+		data, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The file is stdout without its colors.
+		if string(data) != stripANSI(stdout) || strings.Contains(string(data), "\x1b[") {
+			t.Errorf("--format %s: file\n%s\nwant stdout without colors:\n%s", tt.format, data, stdout)
+		}
+		if !strings.Contains(stdout, tt.want) {
+			t.Errorf("--format %s: stdout\n%s\nwant %q", tt.format, stdout, tt.want)
+		}
+	}
+
+	output := filepath.Join(t.TempDir(), "report")
+	failures := []struct {
+		flags []string
+		want  string
+	}{
+		{[]string{"--tee"}, "--tee needs --output and a single --format"},
+		{[]string{"--tee", "--output", output, "--format", "table,csv"}, "--tee needs --output and a single --format"},
+		{[]string{"--tee", "--output", output, "--format", "sqlite"}, "--tee cannot be used with --format sqlite"},
+		{[]string{"--tee", "--output", output, "--clipboard"}, "--tee cannot be used with --append, --clipboard or --output-dir"},
+		{[]string{"--tee", "--output", output, "--format", "csv", "--append"}, "--tee cannot be used with --append, --clipboard or --output-dir"},
+	}
+	for _, tt := range failures {
+		stdout, stderr, code := runGitStat(t, r.dir, append(tt.flags, ".", "2024-03-01", "2024-03-02")...)
+		if code == 0 || !strings.Contains(stderr, tt.want) {
+			t.Errorf("%v exited with %d: %s%s\nwant the error %q", tt.flags, code, stdout, stderr, tt.want)
+		}
+	}
+}
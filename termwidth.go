@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+// defaultTerminalWidth is used when stdout is a terminal whose width cannot
+// be found out. Pipes and files are not narrowed at all unless --width is
+// given, as the tools reading them do not wrap lines.
+const defaultTerminalWidth = 80
+
+// minLabelWidth is as narrow as the label column gets to fit a table into
+// maxTableWidth, enough for a full date.
+const minLabelWidth = 11
+
+// maxTableWidth is the width tables are narrowed to, 0 for no limit.
+var maxTableWidth = 0
+
+// terminalWidth returns the number of columns of the terminal file is
+// connected to.
+func terminalWidth(file *os.File) int {
+	width, _, err := term.GetSize(int(file.Fd()))
+	if err != nil || width <= 0 {
+		return defaultTerminalWidth
+	}
+	return width
+}
+
+// fitWidth narrows the label column, the only one of variable content, so
+// the table fits into maxTableWidth. Labels that no longer fit are cut.
+func fitWidth() {
+	if maxTableWidth <= 0 {
+		return
+	}
+	if excess := tableWidth() - maxTableWidth; excess > 0 {
+		labelWidth = max(minLabelWidth, labelWidth-excess)
+	}
+}
+
+// shrinkWidths narrows the columns at the given indexes of widths, in that
+// order and each down to minWidth, until the columns and their separators
+// fit into maxTableWidth.
+func shrinkWidths(widths []int, minWidth int, indexes ...int) {
+	if maxTableWidth <= 0 {
+		return
+	}
+
+	total := len(widths) - 1
+	for _, width := range widths {
+		total += width
+	}
+
+	for _, i := range indexes {
+		excess := total - maxTableWidth
+		if excess <= 0 {
+			return
+		}
+		shrunk := max(minWidth, widths[i]-excess)
+		total -= widths[i] - shrunk
+		widths[i] = shrunk
+	}
+}
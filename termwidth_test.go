@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShrinkWidths(t *testing.T) {
+	defer func(width int) { maxTableWidth = width }(maxTableWidth)
+
+	tests := []struct {
+		maxWidth int
+		widths   []int
+		want     []int
+	}{
+		{0, []int{10, 20, 30}, []int{10, 20, 30}},
+		{100, []int{10, 20, 30}, []int{10, 20, 30}},
+		{62, []int{10, 20, 30}, []int{10, 20, 30}}, // 60 plus two separators
+		{52, []int{10, 20, 30}, []int{10, 20, 20}},
+		{40, []int{10, 20, 30}, []int{10, 18, 10}},
+		{10, []int{10, 20, 30}, []int{10, 10, 10}},
+	}
+	for _, tt := range tests {
+		maxTableWidth = tt.maxWidth
+		widths := append([]int(nil), tt.widths...)
+		shrinkWidths(widths, 10, 2, 1)
+		if fmt.Sprint(widths) != fmt.Sprint(tt.want) {
+			t.Errorf("shrinking %v to %d: %v, want %v", tt.widths, tt.maxWidth, widths, tt.want)
+		}
+	}
+}
+
+func TestFitWidth(t *testing.T) {
+	defer func(width, label int) { maxTableWidth, labelWidth = width, label }(maxTableWidth, labelWidth)
+
+	full := tableWidth()
+	tests := []struct {
+		maxWidth int
+		want     int
+	}{
+		{0, labelWidth},
+		{full + 10, labelWidth},
+		{full, labelWidth},
+		{full - 5, labelWidth - 5},
+		{20, minLabelWidth},
+	}
+	for _, tt := range tests {
+		maxTableWidth = tt.maxWidth
+		before := labelWidth
+		fitWidth()
+		if labelWidth != tt.want {
+			t.Errorf("fitting a %d wide table into %d: labels %d wide, want %d", full, tt.maxWidth, labelWidth, tt.want)
+		}
+		labelWidth = before
+	}
+}
+
+func TestWidth(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", message: "feat: " + strings.Repeat("a long subject ", 5), files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-05T10:00:00Z", author: "Someone With A Long Name <long@example.com>", files: map[string]string{"a": lines(2)}})
+
+	tests := []struct {
+		args  []string
+		width int
+	}{
+		{[]string{"--width", "70"}, 70},
+		{[]string{"--width", "75", "--indent", "4"}, 75},
+		{[]string{"--width", "70", "--commits-table"}, 70},
+		// No narrower than what the numbers and a date need.
+		{[]string{"--width", "40"}, 67},
+	}
+	for _, tt := range tests {
+		out := stripANSI(mustRun(t, r.dir, append(tt.args, ".", "2024-03-01", "2024-03-05")...))
+		widest := 0
+		for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+			widest = max(widest, textWidth(strings.TrimRight(line, " ")))
+		}
+		if widest > tt.width || widest < tt.width-5 {
+			t.Errorf("%v: lines up to %d wide, want %d:\n%s", tt.args, widest, tt.width, out)
+		}
+	}
+
+	// Output to a pipe is not narrowed.
+	out := stripANSI(mustRun(t, r.dir, ".", "2024-03-01", "2024-03-05"))
+	if !strings.Contains(out, "2024-03-02 ~ 03-04") {
+		t.Errorf("a piped table is narrowed:\n%s", out)
+	}
+
+	if stdout, stderr, code := runGitStat(t, r.dir, "--width", "-1", ".", "2024-03-01", "2024-03-05"); code == 0 || !strings.Contains(stderr, "--width must not be negative") {
+		t.Errorf("--width -1 exited with %d: %s%s", code, stdout, stderr)
+	}
+}
+
+func TestTerminalWidth(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	// Only used for terminals, but the size of a file cannot be read
+	// either.
+	if got := terminalWidth(file); got != defaultTerminalWidth {
+		t.Errorf("terminalWidth of a file = %d, want %d", got, defaultTerminalWidth)
+	}
+}
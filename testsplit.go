@@ -0,0 +1,69 @@
+package main
+
+import (
+	"path"
+	"strings"
+)
+
+// defaultTestPatterns are the --test-pattern defaults: the usual file
+// names of tests and directories holding them.
+var defaultTestPatterns = []string{
+	"*_test.go",
+	"test_*.py",
+	"*_test.py",
+	"*.test.js",
+	"*.spec.js",
+	"*.test.ts",
+	"*.spec.ts",
+	"*Test.java",
+	"test/**",
+	"tests/**",
+	"spec/**",
+}
+
+// isTestFile reports whether the file at filename matches one of the
+// --test-pattern patterns.
+func isTestFile(filename string, patterns []string) bool {
+	return matchesPattern(filename, patterns)
+}
+
+// matchesPattern reports whether the file at filename matches one of the
+// patterns. A pattern without a slash matches the file name in any
+// directory, `dir/**` matches everything below a directory named dir at any
+// depth, and other patterns match the whole path.
+func matchesPattern(filename string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if dir, ok := strings.CutSuffix(pattern, "/**"); ok {
+			if strings.HasPrefix(filename, dir+"/") || strings.Contains(filename, "/"+dir+"/") {
+				return true
+			}
+			continue
+		}
+
+		name := filename
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(filename)
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// splitTestColumns replace the stats columns with --split-tests.
+var splitTestColumns = []tableColumn{
+	tableColumns[0],
+	{"Code Additions", 16, func(stats *DailyStats) string {
+		return formatCount(stats.Additions - stats.TestAdditions)
+	}},
+	{"Code Deletions", 16, func(stats *DailyStats) string {
+		return formatCount(stats.Deletions - stats.TestDeletions)
+	}},
+	{"Test Additions", 16, func(stats *DailyStats) string {
+		return formatCount(stats.TestAdditions)
+	}},
+	{"Test Deletions", 16, func(stats *DailyStats) string {
+		return formatCount(stats.TestDeletions)
+	}},
+}
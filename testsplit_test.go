@@ -0,0 +1,75 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestIsTestFile(t *testing.T) {
+	tests := []struct {
+		filename string
+		patterns []string
+		want     bool
+	}{
+		{"foo_test.go", defaultTestPatterns, true},
+		{"pkg/foo_test.go", defaultTestPatterns, true},
+		{"foo.go", defaultTestPatterns, false},
+		{"test/helpers.go", defaultTestPatterns, true},
+		{"src/tests/fixtures/data.json", defaultTestPatterns, true},
+		{"attest/main.go", defaultTestPatterns, false},
+		{"web/app.spec.ts", defaultTestPatterns, true},
+		{"src/FooTest.java", defaultTestPatterns, true},
+		{"testing.go", defaultTestPatterns, false},
+		{"pkg/foo_test.go", []string{"pkg/*_test.go"}, true},
+		{"lib/pkg/foo_test.go", []string{"pkg/*_test.go"}, false},
+		{"foo_test.go", []string{"checks/**"}, false},
+		{"foo_test.go", nil, false},
+	}
+	for _, tt := range tests {
+		if got := isTestFile(tt.filename, tt.patterns); got != tt.want {
+			t.Errorf("isTestFile(%q, %q) = %t, want %t", tt.filename, tt.patterns, got, tt.want)
+		}
+	}
+}
+
+func TestSplitTests(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"foo.go": lines(3), "foo_test.go": lines(2)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"foo.go": lines(1), "foo_test.go": lines(4)}})
+	r.commit(testCommit{when: "2024-03-02T11:00:00Z", files: map[string]string{"checks/run.sh": lines(1)}})
+
+	tests := []struct {
+		patterns []string
+		rows     map[string][]string // code additions, code deletions, test additions and test deletions
+	}{
+		{nil, map[string][]string{
+			"2024-03-01": {"3", "0", "2", "0"},
+			"2024-03-02": {"1", "2", "2", "0"},
+			"Total":      {"4", "2", "4", "0"},
+		}},
+		{[]string{"checks/**", "*_test.go"}, map[string][]string{
+			"2024-03-02": {"0", "2", "3", "0"},
+			"Total":      {"3", "2", "5", "0"},
+		}},
+		{[]string{"checks/**"}, map[string][]string{
+			"2024-03-01": {"5", "0", "0", "0"},
+			"Total":      {"7", "2", "1", "0"},
+		}},
+	}
+	for _, tt := range tests {
+		args := []string{"--split-tests"}
+		for _, pattern := range tt.patterns {
+			args = append(args, "--test-pattern", pattern)
+		}
+		out := mustRun(t, r.dir, append(args, ".", "2024-03-01", "2024-03-02")...)
+		for label, want := range tt.rows {
+			if row := tableRow(out, label); row == nil || !slices.Equal(row[2:], want) {
+				t.Errorf("--test-pattern %q: %s %v, want %v:\n%s", tt.patterns, label, row, want, out)
+			}
+		}
+	}
+
+	if stdout, stderr, code := runGitStat(t, r.dir, "--split-tests", "--by-author", ".", "2024-03-01", "2024-03-02"); code == 0 {
+		t.Errorf("--split-tests with --by-author exited with 0: %s%s", stdout, stderr)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// minTrendDays is the shortest range a trend is reported for.
+const minTrendDays = 3
+
+// trendSlope fits a line through the total changes of every day of the
+// range, days without commits counting as zero, and returns its slope in
+// changes per day along with the mean daily changes.
+func trendSlope(report *Report) (slope, mean float64, days int) {
+	var values []float64
+	for d := report.StartDate; !d.After(report.EndDate); d = d.AddDate(0, 0, 1) {
+		value := 0.0
+		if stats, ok := report.DailyStats[d.Format("2006-01-02")]; ok {
+			value = float64(stats.Changes)
+		}
+		values = append(values, value)
+	}
+
+	n := float64(len(values))
+	var sumX, sumY, sumXY, sumXX float64
+	for i, y := range values {
+		x := float64(i)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+
+	if denom := n*sumXX - sumX*sumX; denom != 0 {
+		slope = (n*sumXY - sumX*sumY) / denom
+	}
+	return slope, sumY / n, len(values)
+}
+
+// classifyTrend names the direction of the slope. The trend is stable when
+// the fitted line changes by less than a tenth of the mean over the range.
+func classifyTrend(slope, mean float64, days int) string {
+	switch {
+	case days < minTrendDays:
+		return "insufficient data"
+	case math.Abs(slope)*float64(days-1) < mean/10 || slope == 0:
+		return "stable"
+	case slope > 0:
+		return "increasing"
+	}
+	return "decreasing"
+}
+
+// printTrend prints the trend of the daily total changes.
+func printTrend(w io.Writer, report *Report) {
+	slope, mean, days := trendSlope(report)
+	trend := classifyTrend(slope, mean, days)
+	if days < minTrendDays {
+		fmt.Fprintf(w, "Trend: %s\n", trend)
+		return
+	}
+	fmt.Fprintf(w, "Trend: %s (%s changes per day)\n", trend, formatSigned(slope, 2))
+}
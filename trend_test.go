@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTrendSlope(t *testing.T) {
+	tests := []struct {
+		changes []int
+		slope   float64
+		trend   string
+	}{
+		{[]int{10, 20, 30, 40}, 10, "increasing"},
+		{[]int{40, 30, 20, 10}, -10, "decreasing"},
+		{[]int{0, 0, 5, 0, 50}, 10, "increasing"},
+		{[]int{10, 11, 10, 11}, 0.2, "stable"},
+		{[]int{0, 0, 0}, 0, "stable"},
+		{[]int{10, 40}, 30, "insufficient data"},
+		{[]int{7}, 0, "insufficient data"},
+	}
+	for _, tt := range tests {
+		report := &Report{DailyStats: make(map[string]*DailyStats)}
+		report.StartDate, _ = parseDate("2024-03-01")
+		report.EndDate = report.StartDate.AddDate(0, 0, len(tt.changes)-1)
+		for i, changes := range tt.changes {
+			if changes > 0 {
+				report.DailyStats[report.StartDate.AddDate(0, 0, i).Format("2006-01-02")] = &DailyStats{Changes: changes}
+			}
+		}
+
+		slope, mean, days := trendSlope(report)
+		if days != len(tt.changes) || fmt.Sprintf("%.4f", slope) != fmt.Sprintf("%.4f", tt.slope) {
+			t.Errorf("%v: slope %f over %d days, want %f over %d", tt.changes, slope, days, tt.slope, len(tt.changes))
+		}
+		if got := classifyTrend(slope, mean, days); got != tt.trend {
+			t.Errorf("%v: trend %q, want %q", tt.changes, got, tt.trend)
+		}
+	}
+}
+
+func TestTrend(t *testing.T) {
+	r := newTestRepo(t)
+	for i, day := range []string{"2024-03-01", "2024-03-02", "2024-03-03", "2024-03-04"} {
+		r.commit(testCommit{when: day + "T10:00:00Z", files: map[string]string{day: lines(10 * (i + 1))}})
+	}
+
+	tests := []struct {
+		start, end string
+		want       string
+	}{
+		{"2024-03-01", "2024-03-04", "Trend: increasing (+10.00 changes per day)\n"},
+		{"2024-03-03", "2024-03-06", "Trend: decreasing (-13.00 changes per day)\n"},
+		{"2024-03-03", "2024-03-04", "Trend: insufficient data\n"},
+	}
+	for _, tt := range tests {
+		out := mustRun(t, r.dir, "--trend", ".", tt.start, tt.end)
+		if !strings.Contains(out, tt.want) {
+			t.Errorf("%s ~ %s: output lacks %q:\n%s", tt.start, tt.end, tt.want, out)
+		}
+	}
+
+	if out := mustRun(t, r.dir, ".", "2024-03-01", "2024-03-04"); strings.Contains(out, "Trend") {
+		t.Errorf("trend printed without --trend:\n%s", out)
+	}
+
+	var b bytes.Buffer
+	printTrend(&b, &Report{DailyStats: map[string]*DailyStats{}})
+	if got := b.String(); got != "Trend: insufficient data\n" {
+		t.Errorf("printTrend of an empty range = %q", got)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseTZOffset parses a UTC offset such as +08:00, -0530, +8 or Z.
+func parseTZOffset(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "Z" || value == "UTC" {
+		return 0, nil
+	}
+	if value == "" || value[0] != '+' && value[0] != '-' {
+		return 0, fmt.Errorf("invalid offset %q, expected one like +08:00", value)
+	}
+
+	sign := time.Duration(1)
+	if value[0] == '-' {
+		sign = -1
+	}
+
+	digits := strings.ReplaceAll(value[1:], ":", "")
+	hoursStr, minutesStr := digits, "0"
+	if len(digits) > 2 {
+		hoursStr, minutesStr = digits[:len(digits)-2], digits[len(digits)-2:]
+	}
+
+	hours, err := strconv.Atoi(hoursStr)
+	if err != nil || hours > 14 {
+		return 0, fmt.Errorf("invalid offset %q, expected one like +08:00", value)
+	}
+	minutes, err := strconv.Atoi(minutesStr)
+	if err != nil || minutes > 59 {
+		return 0, fmt.Errorf("invalid offset %q, expected one like +08:00", value)
+	}
+
+	return sign * (time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute), nil
+}
+
+// inTimeZone reports whether the time zone of when is within tolerance of
+// the UTC offset, a rough way of telling where a commit was made.
+func inTimeZone(when time.Time, offset, tolerance time.Duration) bool {
+	_, seconds := when.Zone()
+	diff := time.Duration(seconds)*time.Second - offset
+	return diff <= tolerance && -diff <= tolerance
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTZOffset(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"+08:00", 8 * time.Hour, false},
+		{"+0800", 8 * time.Hour, false},
+		{"+8", 8 * time.Hour, false},
+		{"-05:30", -(5*time.Hour + 30*time.Minute), false},
+		{"-0530", -(5*time.Hour + 30*time.Minute), false},
+		{"+14:00", 14 * time.Hour, false},
+		{"Z", 0, false},
+		{"UTC", 0, false},
+		{" +01:00 ", time.Hour, false},
+		{"08:00", 0, true},
+		{"", 0, true},
+		{"+", 0, true},
+		{"+15:00", 0, true},
+		{"+08:60", 0, true},
+		{"+ab:cd", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseTZOffset(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseTZOffset(%q) error = %v, want error %t", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("parseTZOffset(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestInTimeZone(t *testing.T) {
+	at := func(offset time.Duration) time.Time {
+		return time.Date(2024, 3, 1, 12, 0, 0, 0, time.FixedZone("", int(offset/time.Second)))
+	}
+	tests := []struct {
+		zone, offset, tolerance time.Duration
+		want                    bool
+	}{
+		{8 * time.Hour, 8 * time.Hour, 0, true},
+		{9 * time.Hour, 8 * time.Hour, 0, false},
+		{9 * time.Hour, 8 * time.Hour, time.Hour, true},
+		{7 * time.Hour, 8 * time.Hour, time.Hour, true},
+		{10 * time.Hour, 8 * time.Hour, time.Hour, false},
+		{-5 * time.Hour, 0, 5 * time.Hour, true},
+		{-5*time.Hour - 30*time.Minute, 0, 5 * time.Hour, false},
+	}
+	for _, tt := range tests {
+		if got := inTimeZone(at(tt.zone), tt.offset, tt.tolerance); got != tt.want {
+			t.Errorf("inTimeZone(%s, %s, %s) = %t, want %t", tt.zone, tt.offset, tt.tolerance, got, tt.want)
+		}
+	}
+}
+
+func TestTZOffset(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-02-28T12:00:00Z", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-01T12:00:00+08:00", files: map[string]string{"a": lines(2)}})
+	r.commit(testCommit{when: "2024-03-02T12:00:00+09:00", files: map[string]string{"a": lines(4)}})
+	r.commit(testCommit{when: "2024-03-03T12:00:00-05:00", files: map[string]string{"a": lines(8)}})
+
+	tests := []struct {
+		flags string
+		total string // the additions counted
+	}{
+		{"", "7"},
+		{"--tz-offset +08:00", "1"},
+		{"--tz-offset +0900", "2"},
+		{"--tz-offset +08:00 --tz-tolerance 1h", "3"},
+		{"--tz-offset -5", "4"},
+		{"--tz-offset Z --tz-tolerance 5h", "4"},
+		{"--tz-offset Z --tz-tolerance 10h", "7"},
+	}
+	for _, tt := range tests {
+		out := mustRun(t, r.dir, append(strings.Fields(tt.flags), ".", "2024-03-01", "2024-03-04")...)
+		if row := tableRow(out, "Total"); row == nil || row[2] != tt.total {
+			t.Errorf("%q: total row %v, want %s additions", tt.flags, row, tt.total)
+		}
+	}
+
+	failures := []struct {
+		flags string
+		want  string
+	}{
+		{"--tz-offset 8", `invalid offset "8"`},
+		{"--tz-tolerance 1h", "--tz-tolerance needs --tz-offset"},
+		{"--tz-offset +08:00 --tz-tolerance -1h", "--tz-tolerance must not be negative"},
+	}
+	for _, tt := range failures {
+		stdout, stderr, code := runGitStat(t, r.dir, append(strings.Fields(tt.flags), ".", "2024-03-01", "2024-03-04")...)
+		if code == 0 || !strings.Contains(stdout+stderr, tt.want) {
+			t.Errorf("%q exited with %d: %s%s\nwant the error %q", tt.flags, code, stdout, stderr, tt.want)
+		}
+	}
+}
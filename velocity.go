@@ -0,0 +1,52 @@
+package main
+
+import (
+	"slices"
+	"time"
+)
+
+// sessionLead is the work assumed to precede the first commit of a session,
+// so a session of a single commit still counts for some time.
+const sessionLead = 30 * time.Minute
+
+// activeTime estimates how long was worked to make commits at the given
+// times. Commits less than gap apart belong to the same session, which lasts
+// from sessionLead before its first commit to its last one.
+func activeTime(times []time.Time, gap time.Duration) time.Duration {
+	if len(times) == 0 {
+		return 0
+	}
+
+	sorted := slices.Clone(times)
+	slices.SortFunc(sorted, func(a, b time.Time) int { return a.Compare(b) })
+
+	active := sessionLead
+	for i := 1; i < len(sorted); i++ {
+		if since := sorted[i].Sub(sorted[i-1]); since <= gap {
+			active += since
+		} else {
+			active += sessionLead
+		}
+	}
+	return active
+}
+
+// velocityColumns show the estimated active hours and the changes made per
+// active hour.
+func velocityColumns(gap time.Duration) []tableColumn {
+	return []tableColumn{
+		{"Active Hours", activeHoursWidth, func(stats *DailyStats) string {
+			if len(stats.Times) == 0 {
+				return ""
+			}
+			return formatFloat(activeTime(stats.Times, gap).Hours(), 1)
+		}},
+		{"Per Hour", perHourWidth, func(stats *DailyStats) string {
+			hours := activeTime(stats.Times, gap).Hours()
+			if hours == 0 {
+				return ""
+			}
+			return formatFloat(float64(stats.Changes)/hours, 1)
+		}},
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestActiveTime(t *testing.T) {
+	at := func(clock string) time.Time {
+		when, err := time.Parse("15:04", clock)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return when
+	}
+
+	tests := []struct {
+		times []string
+		gap   time.Duration
+		want  time.Duration
+	}{
+		{nil, 2 * time.Hour, 0},
+		{[]string{"09:00"}, 2 * time.Hour, 30 * time.Minute},
+		{[]string{"09:00", "10:00"}, 2 * time.Hour, 90 * time.Minute},
+		{[]string{"10:00", "09:00"}, 2 * time.Hour, 90 * time.Minute},
+		{[]string{"09:00", "11:00"}, 2 * time.Hour, 150 * time.Minute},
+		{[]string{"09:00", "11:01"}, 2 * time.Hour, time.Hour},
+		{[]string{"09:00", "10:00", "15:00", "15:20"}, 2 * time.Hour, 2*time.Hour + 20*time.Minute},
+		{[]string{"09:00", "10:00", "15:00"}, 6 * time.Hour, 6*time.Hour + 30*time.Minute},
+	}
+	for _, tt := range tests {
+		var times []time.Time
+		for _, clock := range tt.times {
+			times = append(times, at(clock))
+		}
+		if got := activeTime(times, tt.gap); got != tt.want {
+			t.Errorf("activeTime(%v, %s) = %s, want %s", tt.times, tt.gap, got, tt.want)
+		}
+	}
+}
+
+func TestVelocity(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-02-28T12:00:00Z", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-01T09:00:00Z", files: map[string]string{"a": lines(2)}})
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(4)}})
+	r.commit(testCommit{when: "2024-03-01T15:00:00Z", files: map[string]string{"a": lines(8)}})
+	r.commit(testCommit{when: "2024-03-02T12:00:00Z", files: map[string]string{"b": lines(3)}})
+
+	tests := []struct {
+		flags string
+		rows  map[string][2]string // the active hours and changes per hour by row
+	}{
+		{"--velocity", map[string][2]string{
+			"2024-03-01": {"2.0", "3.5"},
+			"2024-03-02": {"0.5", "6.0"},
+			"Total":      {"2.5", "4.0"},
+		}},
+		{"--velocity --session-gap 6h", map[string][2]string{
+			"2024-03-01": {"6.5", "1.1"},
+			"2024-03-02": {"0.5", "6.0"},
+			"Total":      {"7.0", "1.4"},
+		}},
+	}
+	for _, tt := range tests {
+		out := mustRun(t, r.dir, append(strings.Fields(tt.flags), ".", "2024-03-01", "2024-03-03")...)
+		for label, want := range tt.rows {
+			row := tableRow(out, label)
+			if len(row) < 7 || row[5] != want[0] || row[6] != want[1] {
+				t.Errorf("%q: %s has the row %v, want %s active hours and %s per hour", tt.flags, label, row, want[0], want[1])
+			}
+		}
+	}
+
+	for _, column := range velocityColumns(2 * time.Hour) {
+		if got := column.value(&DailyStats{}); got != "" {
+			t.Errorf("%s without commits: %q, want nothing", column.title, got)
+		}
+	}
+
+	if out := mustRun(t, r.dir, ".", "2024-03-01", "2024-03-03"); strings.Contains(out, "Active Hours") {
+		t.Errorf("the velocity columns are shown without --velocity:\n%s", out)
+	}
+	if stdout, stderr, code := runGitStat(t, r.dir, "--velocity", "--session-gap", "0s", ".", "2024-03-01", "2024-03-03"); code == 0 || !strings.Contains(stderr, "--session-gap must be positive") {
+		t.Errorf("--session-gap 0s exited with %d: %s%s", code, stdout, stderr)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// fileChurn is the number of lines added to and deleted from a file.
+type fileChurn struct {
+	Additions int
+	Deletions int
+}
+
+// printFileChurn lists the files changed on a day below its row for
+// --verbose-files, those with the most changed lines first and by name on a
+// tie.
+func printFileChurn(w io.Writer, files map[string]fileChurn) {
+	names := make([]string, 0, len(files))
+	nameWidth, addWidth := 0, 0
+	for name, churn := range files {
+		names = append(names, name)
+		nameWidth = max(nameWidth, textWidth(name))
+		addWidth = max(addWidth, len(fmt.Sprintf("+%d", churn.Additions)))
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := files[names[i]], files[names[j]]
+		if a.Additions+a.Deletions != b.Additions+b.Deletions {
+			return a.Additions+a.Deletions > b.Additions+b.Deletions
+		}
+		return names[i] < names[j]
+	})
+
+	for _, name := range names {
+		churn := files[name]
+		added := fmt.Sprintf("+%d", churn.Additions)
+		fmt.Fprintf(w, "  %s  %s%s  -%d\n", padText(name, nameWidth), strings.Repeat(" ", addWidth-len(added)), added, churn.Deletions)
+	}
+}
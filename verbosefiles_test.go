@@ -0,0 +1,79 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintFileChurn(t *testing.T) {
+	tests := []struct {
+		files map[string]fileChurn
+		want  string
+	}{
+		{
+			map[string]fileChurn{"a.go": {1, 0}},
+			"  a.go  +1  -0\n",
+		},
+		{
+			// Most changed first, by name on a tie, with the names and
+			// additions aligned.
+			map[string]fileChurn{"b.go": {2, 3}, "main.go": {120, 4}, "a.go": {5, 0}},
+			"  main.go  +120  -4\n  a.go       +5  -0\n  b.go       +2  -3\n",
+		},
+		{
+			map[string]fileChurn{"日本.txt": {1, 1}, "x": {1, 0}},
+			"  日本.txt  +1  -1\n  x         +1  -0\n",
+		},
+	}
+	for _, tt := range tests {
+		var b strings.Builder
+		printFileChurn(&b, tt.files)
+		if b.String() != tt.want {
+			t.Errorf("printFileChurn(%v) printed:\n%s\nwant:\n%s", tt.files, b.String(), tt.want)
+		}
+	}
+}
+
+func TestVerboseFiles(t *testing.T) {
+	r := newTestRepo(t)
+	r.commit(testCommit{when: "2024-02-28T10:00:00Z", files: map[string]string{"a": lines(1)}})
+	r.commit(testCommit{when: "2024-03-01T10:00:00Z", files: map[string]string{"a": lines(3), "b": lines(1)}})
+	r.commit(testCommit{when: "2024-03-01T11:00:00Z", files: map[string]string{"a": lines(2)}})
+	r.commit(testCommit{when: "2024-03-02T10:00:00Z", files: map[string]string{"c": lines(4)}})
+
+	tests := []struct {
+		flag  string
+		files map[string]string // the lines following each day's row
+	}{
+		{"", map[string]string{"2024-03-01": "", "2024-03-02": ""}},
+		// The changes of a file are added up over the day's commits.
+		{"--verbose-files", map[string]string{
+			"2024-03-01": "  a  +2  -1\n  b  +1  -0\n",
+			"2024-03-02": "  c  +4  -0\n",
+		}},
+	}
+	for _, tt := range tests {
+		args := []string{".", "2024-03-01", "2024-03-02"}
+		if tt.flag != "" {
+			args = append([]string{tt.flag}, args...)
+		}
+		out := mustRun(t, r.dir, args...)
+		for day, want := range tt.files {
+			start := strings.Index(out, "\n"+day)
+			if start < 0 {
+				t.Fatalf("no row for %s:\n%s", day, out)
+			}
+			// The files, if any, follow the row and its rule, indented.
+			got := ""
+			for _, line := range strings.SplitAfter(out[start+1:], "\n")[2:] {
+				if !strings.HasPrefix(line, "  ") {
+					break
+				}
+				got += line
+			}
+			if got != want {
+				t.Errorf("%q: below %s:\n%q\nwant:\n%q", tt.flag, day, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/gitattributes"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// dayTotals are the line counts of a day compared by --verify.
+type dayTotals struct {
+	Additions int
+	Deletions int
+}
+
+// gitLogTotals runs `git log --numstat` over the same commits walkCommits
+// visits and sums the changed lines per author day. Every commit and its
+// files go through the same skipCommit and filterStats as in walkCommits,
+// so the options leaving out commits or files agree on both sides. Binary
+// files, which git reports as "-", and files without changed lines are left
+// out like they are by commitFileStats.
+func gitLogTotals(repoPath string, repo *git.Repository, startDate, endDate time.Time, opts *Options) (map[string]dayTotals, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, errors.New("--verify requires git on PATH")
+	}
+
+	from, _, err := resolveBranch(repo, opts.Branch)
+	if err != nil {
+		return nil, err
+	}
+
+	var linguist gitattributes.Matcher
+	if opts.Linguist {
+		if linguist, err = loadLinguistMatcher(repo, from); err != nil {
+			return nil, err
+		}
+	}
+
+	authors, err := loadMailmap(repo, from)
+	if err != nil {
+		return nil, err
+	}
+	excludedCommits, err := resolveCommits(repo, opts.ExcludeCommits)
+	if err != nil {
+		return nil, err
+	}
+	includedAuthors := authorSet(opts.IncludeAuthors)
+
+	var follower *fileFollower
+	if opts.File != "" {
+		follower = newFileFollower(opts)
+	}
+
+	endDate = endDate.Add(24 * time.Hour).Add(-time.Second)
+
+	args := []string{
+		"-C", repoPath, "log", from.String(),
+		"--since=" + startDate.Format(time.RFC3339),
+		"--until=" + endDate.Format(time.RFC3339),
+		"--numstat", "--diff-merges=first-parent",
+		fmt.Sprintf("-M%d%%", opts.RenameThreshold),
+		"--format=commit %H",
+	}
+	if opts.SinceCommit != "" {
+		args = append(args, "^"+opts.SinceCommit)
+	}
+	if opts.NormalizeEOL {
+		args = append(args, "--ignore-cr-at-eol")
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	totals := make(map[string]dayTotals)
+	var commit *object.Commit
+	var stats object.FileStats
+	var moves map[string]string
+
+	// A commit is only added to its day once all its files are seen, so
+	// the options looking at the commit as a whole can be applied to it.
+	flush := func() error {
+		if commit == nil {
+			return nil
+		}
+		if follower != nil {
+			defer follower.track(moves)
+		}
+		if skipCommit(commit, opts, includedAuthors, excludedCommits) != "" {
+			return nil
+		}
+		empty, err := unchangedTree(commit)
+		if err != nil {
+			return err
+		}
+		kept, reason := filterStats(stats, empty, opts, linguist, follower)
+		if reason != "" {
+			return nil
+		}
+		day := commit.Author.When.Format("2006-01-02")
+		t := totals[day]
+		for _, stat := range kept {
+			t.Additions += stat.Addition
+			t.Deletions += stat.Deletion
+		}
+		totals[day] = t
+		return nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if hash, ok := strings.CutPrefix(line, "commit "); ok {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			if commit, err = repo.CommitObject(plumbing.NewHash(hash)); err != nil {
+				return nil, err
+			}
+			commit.Author = authors.apply(commit.Author)
+			stats, moves = nil, make(map[string]string)
+			continue
+		}
+
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		from, to := numstatPaths(fields[2])
+		if from != to {
+			moves[to] = from
+		}
+		if fields[0] == "-" {
+			continue
+		}
+
+		adds, _ := strconv.Atoi(fields[0])
+		dels, _ := strconv.Atoi(fields[1])
+		if adds == 0 && dels == 0 {
+			continue
+		}
+
+		name := to
+		if from != to {
+			name = from + " => " + to
+		}
+		stats = append(stats, object.FileStat{Name: name, Addition: adds, Deletion: dels})
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return totals, scanner.Err()
+}
+
+// numstatPaths returns the old and new path of a numstat entry, expanding
+// the "dir/{old => new}/file" notation git uses for renames.
+func numstatPaths(name string) (from, to string) {
+	open := strings.Index(name, "{")
+	close := strings.LastIndex(name, "}")
+	if open >= 0 && close > open {
+		if old, new, ok := strings.Cut(name[open+1:close], " => "); ok {
+			from = path.Clean(name[:open] + old + name[close+1:])
+			to = path.Clean(name[:open] + new + name[close+1:])
+			return from, to
+		}
+	}
+	return splitRename(name)
+}
+
+// verifyStats compares the daily totals against git's own numbers and
+// prints whether they agree or the first day where they diverge. It returns
+// false on a divergence.
+func verifyStats(w io.Writer, dailyStats map[string]*DailyStats, gitTotals map[string]dayTotals) bool {
+	days := make(map[string]struct{})
+	for day, stats := range dailyStats {
+		if stats.Additions+stats.Deletions > 0 {
+			days[day] = struct{}{}
+		}
+	}
+	for day, t := range gitTotals {
+		if t.Additions+t.Deletions > 0 {
+			days[day] = struct{}{}
+		}
+	}
+
+	sorted := make([]string, 0, len(days))
+	for day := range days {
+		sorted = append(sorted, day)
+	}
+	sort.Strings(sorted)
+
+	for _, day := range sorted {
+		var ours dayTotals
+		if stats, ok := dailyStats[day]; ok {
+			ours = dayTotals{stats.Additions, stats.Deletions}
+		}
+
+		if theirs := gitTotals[day]; ours != theirs {
+			fmt.Fprintf(w, "Mismatch on %s: git-stat +%d -%d, git log +%d -%d\n",
+				day, ours.Additions, ours.Deletions, theirs.Additions, theirs.Deletions)
+			return false
+		}
+	}
+
+	fmt.Fprintf(w, "OK: %d days match git log --numstat\n", len(sorted))
+	return true
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNumstatPaths(t *testing.T) {
+	tests := []struct{ name, from, to string }{
+		{"a.go", "a.go", "a.go"},
+		{"old.go => new.go", "old.go", "new.go"},
+		{"src/{a => b}/c.go", "src/a/c.go", "src/b/c.go"},
+		{"{src => lib}/c.go", "src/c.go", "lib/c.go"},
+		{"src/{ => sub}/c.go", "src/c.go", "src/sub/c.go"},
+		{"src/{sub => }/c.go", "src/sub/c.go", "src/c.go"},
+	}
+	for _, tt := range tests {
+		if from, to := numstatPaths(tt.name); from != tt.from || to != tt.to {
+			t.Errorf("numstatPaths(%q) = %q, %q, want %q, %q", tt.name, from, to, tt.from, tt.to)
+		}
+	}
+}
+
+func TestVerifyStats(t *testing.T) {
+	ours := map[string]*DailyStats{
+		"2024-03-01": {Additions: 3, Deletions: 1},
+		"2024-03-02": {Additions: 0, Deletions: 0},
+		"2024-03-03": {Additions: 2},
+	}
+
+	tests := []struct {
+		name   string
+		theirs map[string]dayTotals
+		ok     bool
+		want   string
+	}{
+		{"agreeing", map[string]dayTotals{"2024-03-01": {3, 1}, "2024-03-03": {2, 0}}, true, "OK: 2 days match git log --numstat\n"},
+		{"differing", map[string]dayTotals{"2024-03-01": {3, 2}, "2024-03-03": {1, 0}}, false, "Mismatch on 2024-03-01: git-stat +3 -1, git log +3 -2\n"},
+		{"missing a day", map[string]dayTotals{"2024-03-01": {3, 1}}, false, "Mismatch on 2024-03-03: git-stat +2 -0, git log +0 -0\n"},
+		{"with an extra day", map[string]dayTotals{"2024-03-01": {3, 1}, "2024-03-02": {1, 0}, "2024-03-03": {2, 0}}, false, "Mismatch on 2024-03-02: git-stat +0 -0, git log +1 -0\n"},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		if ok := verifyStats(&b, ours, tt.theirs); ok != tt.ok || b.String() != tt.want {
+			t.Errorf("%s: %t, %q, want %t, %q", tt.name, ok, b.String(), tt.ok, tt.want)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not on PATH")
+	}
+
+	r := mergeRepo(t)
+	r.commit(testCommit{when: "2024-03-05T10:00:00Z", files: map[string]string{"src/c": lines(5), "b": lines(1)}})
+	revert := r.commit(testCommit{when: "2024-03-05T22:00:00Z", message: "Revert \"change\"", files: map[string]string{"src/c": ""}})
+	r.commit(testCommit{when: "2024-03-06T10:00:00Z", author: "Bob <bob@example.com>", files: map[string]string{"src/d": lines(3), "e": lines(2), "f": lines(1)}})
+	r.commit(testCommit{when: "2024-03-06T11:00:00Z", files: map[string]string{"src/d": "", "src/g": lines(3)}})
+
+	authors := filepath.Join(t.TempDir(), "authors")
+	if err := os.WriteFile(authors, []byte("bob@example.com\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	presets := filepath.Join(t.TempDir(), "presets")
+	if err := os.WriteFile(presets, []byte("src = src/**\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := [][]string{
+		nil,
+		{"--authors-file", authors},
+		{"--no-merges"},
+		{"--merges-only"},
+		{"--path", "src"},
+		{"--exclude-reverts"},
+		{"--exclude-commit", revert.String()},
+		{"--max-files-per-commit", "2"},
+		{"--min-additions", "2"},
+		{"--hours", "9-12"},
+		{"--exclude-range", "2024-03-02..2024-03-04"},
+		{"--tz-offset", "+00:00"},
+		{"--normalize-line-endings"},
+		{"--preset", "src", "--presets", presets},
+	}
+	for _, flags := range tests {
+		args := append(append([]string{"--verify"}, flags...), ".", "2024-03-01", "2024-03-06")
+		stdout, stderr, code := runGitStat(t, r.dir, args...)
+		if code != 0 || !strings.HasPrefix(stdout, "OK: ") {
+			t.Errorf("--verify %v exited with %d: %s%s", flags, code, stdout, stderr)
+		}
+	}
+}